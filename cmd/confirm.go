@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"os"
+	"sort"
+	"strings"
+)
+
+var errScalingNotApproved = errors.New("scaling not approved")
+
+func printPlan(scalingPlan *pkg.ScalingPlan) {
+	fmt.Printf("Scaling plan for app %s:\n", scalingPlan.ScalingConfig.Name)
+	region := ""
+	for _, resourcePlan := range scalingPlan.Resources {
+		if resourcePlan.Region != region {
+			region = resourcePlan.Region
+			fmt.Printf("----------region: %s------------\n", region)
+		}
+
+		if !resourcePlan.IsChange() {
+			fmt.Printf("%s %s (unchanged)\n", resourcePlan.ServiceName, resourcePlan.IdentifierId)
+			continue
+		}
+		fmt.Printf("%s %s\n", resourcePlan.ServiceName, resourcePlan.IdentifierId)
+		for _, name := range capacityNames(resourcePlan.Target) {
+			current := "(unknown)"
+			if value, ok := resourcePlan.Current[name]; ok {
+				current = fmt.Sprint(value)
+			}
+			fmt.Printf("    %s: %s -> %d\n", name, current, resourcePlan.Target[name])
+		}
+	}
+
+	if waves := scalingPlan.Waves(); len(waves) > 1 {
+		fmt.Println("----------rollout------------")
+		for i, wave := range waves {
+			fmt.Printf("wave %d: %s\n", i+1, strings.Join(wave, ", "))
+		}
+	}
+
+	if failedServices := scalingPlan.NotScaled(); len(failedServices) > 0 {
+		fmt.Println("----------not scaled------------")
+		for _, scalingError := range failedServices {
+			fmt.Printf("%s %s (%s): %v\n", scalingError.ServiceName, scalingError.IdentifierId, scalingError.Region, scalingError.Err)
+		}
+	}
+
+	if len(scalingPlan.QuotasNotChecked) > 0 {
+		fmt.Println("----------quotas not checked------------")
+		for _, scalingError := range scalingPlan.QuotasNotChecked {
+			fmt.Printf("%s %s (%s): %v\n", scalingError.ServiceName, scalingError.IdentifierId, scalingError.Region, scalingError.Err)
+		}
+	}
+}
+
+func capacityNames(capacity service.Capacity) []string {
+	names := make([]string, 0, len(capacity))
+	for name := range capacity {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// confirmScaling asks for the confirmation of the plan, canceling the context stops waiting for the answer
+func confirmScaling(ctx context.Context, scalingPlan *pkg.ScalingPlan) error {
+	appName := scalingPlan.ScalingConfig.Name
+	interactive := isInteractive()
+
+	if scalingPlan.ScalingConfig.Protected {
+		if options.approveProtected != "" {
+			if options.approveProtected != appName {
+				return fmt.Errorf("%w: approval token does not match app name %s", errScalingNotApproved, appName)
+			}
+			return nil
+		}
+
+		if !interactive || options.autoApprove {
+			return fmt.Errorf("%w: app %s is protected, run interactively or pass --approve-protected %s", errScalingNotApproved, appName, appName)
+		}
+	}
+
+	if options.autoApprove {
+		return nil
+	}
+
+	fmt.Print("Do you want to apply this plan? Only 'yes' will be accepted: ")
+	answers := make(chan string, 1)
+	go func() {
+		answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && answer == "" {
+			close(answers)
+			return
+		}
+		answers <- answer
+	}()
+
+	select {
+	case <-ctx.Done():
+		fmt.Println()
+		return fmt.Errorf("%w: %w", errScalingNotApproved, context.Cause(ctx))
+	case answer, ok := <-answers:
+		if !ok {
+			return fmt.Errorf("%w: no confirmation received", errScalingNotApproved)
+		}
+		if strings.TrimSpace(answer) != "yes" {
+			return errScalingNotApproved
+		}
+		return nil
+	}
+}
+
+func isInteractive() bool {
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
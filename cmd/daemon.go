@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/daemon"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/metrics"
+	"github.com/spf13/cobra"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type DaemonOptions struct {
+	healthAddr       string
+	lockTimeout      time.Duration
+	approveProtected string
+}
+
+var daemonOptions *DaemonOptions
+
+func init() {
+	daemonOptions = &DaemonOptions{}
+
+	daemonCmd.Flags().StringVar(&daemonOptions.healthAddr, "health-addr", ":8080", "Address to serve the health status and metrics on, empty to disable")
+	daemonCmd.Flags().DurationVar(&daemonOptions.lockTimeout, "lock-timeout", time.Minute, "How long a scheduled run waits for the app lock held by another run")
+	daemonCmd.Flags().StringVar(&daemonOptions.approveProtected, "approve-protected", "", "Approve scheduled scaling of a protected app, must be set to the app name")
+
+	rootCmd.AddCommand(daemonCmd)
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run in the foreground and scale the app to the profiles of its schedules",
+	Long: `Runs in the foreground and scales the app to the profile of each schedule in the config when its cron expression fires.
+On startup the app is first scaled to the profile of the schedule that fired most recently, so a restarted daemon converges to the profile that should currently be active.
+Scale-ups with --ttl that expired are reverted every minute.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		scalingConfig, err := config.ReadConfig(options.configPath)
+		if err != nil {
+			fatal("error reading config", logging.Err(err))
+		}
+
+		if scalingConfig.Protected && daemonOptions.approveProtected != scalingConfig.Name {
+			fatal("error starting daemon, pass --approve-protected with the app name", logging.Err(errScalingNotApproved), logging.AppKey, scalingConfig.Name)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		scalingDaemon := daemon.New(options.configPath, daemonOptions.lockTimeout)
+
+		if daemonOptions.healthAddr != "" {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/healthz", scalingDaemon.HealthHandler)
+			mux.Handle("/metrics", metrics.Handler())
+			server := &http.Server{Addr: daemonOptions.healthAddr, Handler: mux}
+
+			go func() {
+				if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					fatal("error serving health status", logging.Err(err))
+				}
+			}()
+			defer server.Shutdown(context.Background())
+		}
+
+		if err := scalingDaemon.Run(ctx); err != nil {
+			fatal("error running daemon", logging.Err(err))
+		}
+	},
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+type ExportOptions struct {
+	profile  string
+	cron     string
+	timezone string
+	dryRun   bool
+}
+
+var exportOptions *ExportOptions
+
+func init() {
+	exportOptions = &ExportOptions{}
+
+	exportScheduleCmd.Flags().StringVar(&exportOptions.profile, "profile", "", "Profile to export, defaults to the profiles of the schedules in the config")
+	exportScheduleCmd.Flags().StringVar(&exportOptions.cron, "cron", "", "Cron expression to export the profile with")
+	exportScheduleCmd.Flags().StringVar(&exportOptions.timezone, "timezone", "", "Timezone of the cron expression, defaults to UTC")
+	exportScheduleCmd.Flags().BoolVar(&exportOptions.dryRun, "dry-run", false, "Print the scheduled actions without creating them")
+
+	rootCmd.AddCommand(exportScheduleCmd)
+}
+
+var exportScheduleCmd = &cobra.Command{
+	Use:   "export-schedule",
+	Short: "Create AWS scheduled actions that scale the app on its schedules",
+	Long: `Creates EC2 Auto Scaling and Application Auto Scaling scheduled actions for the EC2 and DynamoDB resources of a profile,
+so AWS scales them on the schedule instead of a run of the CLI. Kinesis and ElastiCache have no scheduled actions and are skipped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		actions, failedServices, err := pkg.ExportSchedule(options.configPath, pkg.ExportOptions{
+			Profile:  exportOptions.profile,
+			Cron:     exportOptions.cron,
+			Timezone: exportOptions.timezone,
+			DryRun:   exportOptions.dryRun,
+		})
+		if err != nil {
+			fatal("error exporting schedule", logging.Err(err))
+		}
+
+		for _, action := range actions {
+			timezone := action.Timezone
+			if timezone == "" {
+				timezone = "UTC"
+			}
+			fmt.Printf("%s %s %s\n    name: %s\n    schedule: %s (%s)\n", action.Region, action.ServiceName, action.IdentifierId, action.Name, action.Schedule, timezone)
+			for _, name := range capacityNames(action.Target) {
+				fmt.Printf("    %s: %d\n", name, action.Target[name])
+			}
+		}
+
+		failed := false
+		if len(failedServices) > 0 {
+			fmt.Println("----------not exported------------")
+			for _, scalingError := range failedServices {
+				fmt.Printf("%s %s (%s): %v\n", scalingError.ServiceName, scalingError.IdentifierId, scalingError.Region, scalingError.Err)
+				failed = failed || !errors.Is(scalingError.Err, service.ErrScheduledActionUnsupported)
+			}
+		}
+
+		if failed {
+			fatal("schedule exported with errors")
+		}
+
+		if exportOptions.dryRun {
+			slog.Info("dry run, no scheduled actions created")
+		} else {
+			slog.Info("schedule exported successfully")
+		}
+	},
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/audit"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"time"
+)
+
+type HistoryOptions struct {
+	limit    int
+	since    time.Duration
+	resource string
+}
+
+var historyOptions *HistoryOptions
+
+func init() {
+	historyOptions = &HistoryOptions{}
+
+	historyCmd.Flags().IntVarP(&historyOptions.limit, "limit", "l", 20, "Max number of runs to show")
+	historyCmd.Flags().DurationVarP(&historyOptions.since, "since", "s", 0, "Only show runs started within this duration, e.g. 24h")
+	historyCmd.Flags().StringVarP(&historyOptions.resource, "resource", "r", "", "Only show runs that touched a resource whose identifier contains this value")
+
+	rootCmd.AddCommand(historyCmd)
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the scaling runs of an app recorded in the audit log",
+	Run: func(cmd *cobra.Command, args []string) {
+		query := audit.Query{
+			Resource: historyOptions.resource,
+			Limit:    historyOptions.limit,
+		}
+		if historyOptions.since > 0 {
+			query.Since = time.Now().Add(-historyOptions.since)
+		}
+
+		records, err := pkg.History(options.configPath, query)
+		if err != nil {
+			fatal("error reading history", logging.Err(err))
+		}
+
+		if len(records) == 0 {
+			slog.Info("no scaling runs found")
+			return
+		}
+
+		for i, record := range records {
+			if i != 0 {
+				fmt.Println("------------------------------------------------")
+			}
+			printAuditRecord(record)
+		}
+	},
+}
+
+func printAuditRecord(record audit.Record) {
+	fmt.Printf("run: %s\nstarted: %s\nfinished: %s\nprofile: %s\ncaller: %s\nconfig hash: %s\noutcome: %s\n",
+		record.RunId, record.StartedAt.Format(time.RFC3339), record.FinishedAt.Format(time.RFC3339),
+		record.Profile, record.Caller, record.ConfigHash, record.Outcome)
+
+	for _, resource := range record.Resources {
+		fmt.Printf("  %s %s %s\n", resource.Region, resource.ServiceName, resource.IdentifierId)
+		for _, name := range capacityNames(resource.Before) {
+			after := "not applied"
+			if value, ok := resource.After[name]; ok {
+				after = fmt.Sprint(value)
+			}
+			fmt.Printf("      %s: %d -> %s\n", name, resource.Before[name], after)
+		}
+		if resource.Error != "" {
+			fmt.Printf("      error: %s\n", resource.Error)
+		}
+	}
+
+	for _, err := range record.Errors {
+		fmt.Printf("  %s %s %s\n      error: %s\n", err.Region, err.ServiceName, err.IdentifierId, err.Error)
+	}
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/discovery"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"log/slog"
+	"os"
+)
+
+type ImportOptions struct {
+	regions        []string
+	namePrefix     string
+	tags           map[string]string
+	appName        string
+	assumedRoleArn string
+	outputPath     string
+}
+
+var importOptions *ImportOptions
+
+func init() {
+	importOptions = &ImportOptions{}
+
+	importCmd.Flags().StringSliceVarP(&importOptions.regions, "regions", "r", nil, "Regions to discover resources in")
+	importCmd.Flags().StringVarP(&importOptions.namePrefix, "name-prefix", "p", "", "Only import resources whose name starts with this prefix")
+	importCmd.Flags().StringToStringVarP(&importOptions.tags, "tag", "t", nil, "Only import resources having this tag (key=value), can be repeated")
+	importCmd.Flags().StringVarP(&importOptions.appName, "app-name", "n", "", "Name of the application")
+	importCmd.Flags().StringVarP(&importOptions.assumedRoleArn, "assumed-role-arn", "a", "", "ARN of the IAM role to assume")
+	importCmd.Flags().StringVarP(&importOptions.outputPath, "output", "o", "", "Path to write the generated config to, defaults to stdout")
+
+	_ = importCmd.MarkFlagRequired("regions")
+	_ = importCmd.MarkFlagRequired("app-name")
+	_ = importCmd.MarkFlagRequired("assumed-role-arn")
+
+	rootCmd.AddCommand(importCmd)
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Generate a scaling config from the resources of an existing account",
+	Long: `Discovers EC2 ASGs, Kinesis streams, DynamoDB scalable targets and ElastiCache clusters in the given regions
+matching a tag filter or name prefix, and emits a scaling config populated with their current capacities.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if importOptions.namePrefix == "" && len(importOptions.tags) == 0 {
+			fatal("error importing config: either --name-prefix or --tag must be provided")
+		}
+
+		filter := discovery.Filter{
+			NamePrefix: importOptions.namePrefix,
+			Tags:       importOptions.tags,
+		}
+
+		ctx := context.Background()
+		scalingConfig := config.ScalingConfig{
+			Name:           importOptions.appName,
+			AssumedRoleArn: importOptions.assumedRoleArn,
+		}
+
+		for _, region := range importOptions.regions {
+			awsCreds, err := service.NewConfig(ctx, region, importOptions.assumedRoleArn)
+			if err != nil {
+				fatal("error creating aws config", logging.RegionKey, region, logging.Err(err))
+			}
+
+			scalingRegion, err := discovery.DiscoverRegion(ctx, awsCreds, region, filter)
+			if err != nil {
+				fatal("error importing config", logging.Err(err))
+			}
+
+			if len(scalingRegion.ServiceScaleConfigs) == 0 {
+				slog.Info("no matching resources found, skipping region", logging.RegionKey, region)
+				continue
+			}
+			scalingConfig.ScalingRegions = append(scalingConfig.ScalingRegions, *scalingRegion)
+		}
+
+		data, err := yaml.Marshal(&scalingConfig)
+		if err != nil {
+			fatal("error encoding config", logging.Err(err))
+		}
+
+		if importOptions.outputPath == "" {
+			fmt.Print(string(data))
+			return
+		}
+
+		if err := os.WriteFile(importOptions.outputPath, data, 0644); err != nil {
+			fatal("error writing config file", logging.Err(err))
+		}
+		slog.Info("config written", "path", importOptions.outputPath)
+	},
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/Cool-fire/aws-infra-scaler/pkg"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"time"
+)
+
+type ReaperOptions struct {
+	force            bool
+	lockTimeout      time.Duration
+	approveProtected string
+}
+
+var reaperOptions *ReaperOptions
+
+func init() {
+	reaperOptions = &ReaperOptions{}
+
+	reaperCmd.Flags().BoolVarP(&reaperOptions.force, "force", "f", false, "Revert even if guardrails are violated")
+	reaperCmd.Flags().DurationVar(&reaperOptions.lockTimeout, "lock-timeout", 0, "How long to wait for the app lock held by another run")
+	reaperCmd.Flags().StringVar(&reaperOptions.approveProtected, "approve-protected", "", "Approve reverting a protected app, must be set to the app name")
+
+	rootCmd.AddCommand(reaperCmd)
+}
+
+var reaperCmd = &cobra.Command{
+	Use:   "reaper",
+	Short: "Revert the resources of the app whose scale-up with --ttl expired",
+	Long: `Reverts the resources of the app whose scale-up with --ttl expired to the capacity they had before it.
+Run it periodically, e.g. from cron, or use the daemon command which reaps expired scale-ups every minute.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		scalingConfig, err := config.ReadConfig(options.configPath)
+		if err != nil {
+			fatal("error reading config", logging.Err(err))
+		}
+
+		if scalingConfig.Protected && reaperOptions.approveProtected != scalingConfig.Name {
+			fatal("error reaping app, pass --approve-protected with the app name", logging.Err(errScalingNotApproved), logging.AppKey, scalingConfig.Name)
+		}
+
+		ctx, stop := cancelOnSignal()
+		defer stop()
+
+		scalingResponse, err := pkg.Reap(ctx, options.configPath, pkg.ScaleOptions{
+			Force:       reaperOptions.force,
+			LockTimeout: reaperOptions.lockTimeout,
+		})
+		if scalingResponse != nil || err != nil {
+			pushMetrics(scalingConfig.Name)
+		}
+		if err != nil {
+			if errors.Is(err, pkg.ErrGuardrailViolation) {
+				fatal("error reaping app, use --force to override", logging.Err(err))
+			}
+			fatal("error reaping app", logging.Err(err))
+		}
+
+		if scalingResponse == nil {
+			slog.Info("no expired scale-ups found")
+			return
+		}
+		exitCode = printScalingResponse(scalingResponse)
+	},
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+// exitDrift is the exit code of reconcile when drift is detected
+const exitDrift = 2
+
+var fixDrift bool
+
+func init() {
+	reconcileCmd.Flags().BoolVar(&fixDrift, "fix", false, "Scale the drifted resources back to the profile")
+
+	rootCmd.AddCommand(reconcileCmd)
+}
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Report resources whose live capacity drifted from the profile and optionally fix them",
+	Long: `Compares the live capacity of every resource of the profile to its config and reports the drift, e.g. an ASG max changed manually.
+Exits with code 2 when drift is detected, even when it was fixed with --fix, so it can run as a periodic check.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := cancelOnSignal()
+		defer stop()
+
+		scalingPlan, err := pkg.DetectDrift(ctx, options.configPath, pkg.ScaleOptions{
+			Profile:     options.selectedProfile(),
+			Force:       options.force,
+			LockTimeout: options.lockTimeout,
+		})
+		if err != nil {
+			fatal("error detecting drift", logging.Err(err))
+		}
+		if ctx.Err() != nil {
+			if err := scalingPlan.Discard(); err != nil {
+				slog.Error("error releasing app lock", logging.Err(err))
+			}
+			slog.Warn("drift detection canceled")
+			exitCode = exitCanceled
+			return
+		}
+
+		printDrift(scalingPlan)
+
+		if len(scalingPlan.Resources) == 0 || !fixDrift {
+			if err := scalingPlan.Discard(); err != nil {
+				slog.Error("error releasing app lock", logging.Err(err))
+			}
+			switch {
+			case len(scalingPlan.FailedServices) > 0:
+				fatal("error detecting drift", "failed", len(scalingPlan.FailedServices))
+			case len(scalingPlan.Resources) > 0:
+				slog.Info("drift detected, use --fix to correct it", "resources", len(scalingPlan.Resources))
+				exitCode = exitDrift
+			default:
+				slog.Info("no drift detected")
+			}
+			return
+		}
+
+		if options.force || !scalingPlan.ViolatesGuardrails() {
+			if err := confirmScaling(ctx, scalingPlan); err != nil {
+				if discardErr := scalingPlan.Decline(context.Background()); discardErr != nil {
+					slog.Error("error releasing app lock", logging.Err(discardErr))
+				}
+				if ctx.Err() != nil {
+					slog.Warn("confirmation canceled, drift not fixed")
+					exitCode = exitCanceled
+					return
+				}
+				fatal("error fixing drift", logging.Err(err))
+			}
+		}
+
+		scalingResponse, err := pkg.ApplyPlan(ctx, scalingPlan)
+		pushMetrics(scalingPlan.ScalingConfig.Name)
+		if err != nil {
+			if errors.Is(err, pkg.ErrGuardrailViolation) {
+				fatal("error fixing drift, use --force to override", logging.Err(err))
+			}
+			fatal("error fixing drift", logging.Err(err))
+		}
+
+		exitCode = printScalingResponse(scalingResponse)
+		if scalingResponse.Canceled {
+			return
+		}
+		exitCode = exitDrift
+		if scalingResponse.ContainsFailedServices {
+			exitCode = 1
+		}
+	},
+}
+
+func printDrift(scalingPlan *pkg.ScalingPlan) {
+	region := ""
+	for _, resourcePlan := range scalingPlan.Resources {
+		if resourcePlan.Region != region {
+			region = resourcePlan.Region
+			fmt.Printf("----------region: %s------------\n", region)
+		}
+
+		fmt.Printf("%s %s\n", resourcePlan.ServiceName, resourcePlan.IdentifierId)
+		for _, name := range capacityNames(resourcePlan.Target) {
+			if current := resourcePlan.Current[name]; current != resourcePlan.Target[name] {
+				fmt.Printf("    %s: live %d, profile %d\n", name, current, resourcePlan.Target[name])
+			}
+		}
+	}
+
+	if len(scalingPlan.FailedServices) > 0 {
+		fmt.Println("----------not checked------------")
+		for _, scalingError := range scalingPlan.FailedServices {
+			fmt.Printf("%s %s (%s): %v\n", scalingError.ServiceName, scalingError.IdentifierId, scalingError.Region, scalingError.Err)
+		}
+	}
+
+	if len(scalingPlan.Skipped) > 0 {
+		fmt.Println("----------skipped------------")
+		for _, scalingError := range scalingPlan.Skipped {
+			fmt.Printf("%s %s (%s): %v\n", scalingError.ServiceName, scalingError.IdentifierId, scalingError.Region, scalingError.Err)
+		}
+	}
+
+	if len(scalingPlan.QuotasNotChecked) > 0 {
+		fmt.Println("----------quotas not checked------------")
+		for _, scalingError := range scalingPlan.QuotasNotChecked {
+			fmt.Printf("%s %s (%s): %v\n", scalingError.ServiceName, scalingError.IdentifierId, scalingError.Region, scalingError.Err)
+		}
+	}
+}
@@ -1,30 +1,75 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/Cool-fire/aws-infra-scaler/pkg"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/metrics"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/tracing"
 	"github.com/spf13/cobra"
-	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 type Options struct {
-	scaleUpFlag   bool
-	scaleDownFlag bool
-	configPath    string
+	scaleUpFlag      bool
+	scaleDownFlag    bool
+	profile          string
+	configPath       string
+	force            bool
+	autoApprove      bool
+	approveProtected string
+	lockTimeout      time.Duration
+	ttl              time.Duration
+	resume           string
+	timeout          time.Duration
+	pushgatewayURL   string
+	otlpEndpoint     string
+	logLevel         string
+	logFormat        string
 }
 
-var options *Options
+var options = &Options{}
+
+var shutdownTracing func(ctx context.Context) error
+
+// exitCode is set by commands that report a result through the exit code after cleaning up
+var exitCode int
+
+// exitCanceled is the exit code of runs canceled by a signal, as shells report processes killed by SIGINT
+const exitCanceled = 130
 
 const (
 	defaultConfigPath = "config.yaml"
 )
 
 func init() {
-	options = &Options{}
-
 	rootCmd.PersistentFlags().BoolVarP(&options.scaleUpFlag, "scale-up", "u", false, "Scale up")
 	rootCmd.PersistentFlags().BoolVarP(&options.scaleDownFlag, "scale-down", "d", false, "Scale down")
 	rootCmd.PersistentFlags().StringVarP(&options.configPath, "config", "c", "config.yaml", "Config file path")
+	rootCmd.PersistentFlags().StringVar(&options.logLevel, "log-level", "info", "Log level, one of debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&options.logFormat, "log-format", logging.TextFormat, "Log format, text or json")
+	rootCmd.PersistentFlags().StringVar(&options.otlpEndpoint, "otlp-endpoint", "", "Export traces over OTLP/HTTP to this endpoint, e.g. http://localhost:4318, defaults to OTEL_EXPORTER_OTLP_ENDPOINT")
+	rootCmd.PersistentFlags().StringVar(&options.pushgatewayURL, "pushgateway-url", "", "Push the metrics of the run to this Pushgateway compatible endpoint")
+	rootCmd.Flags().StringVar(&options.profile, "profile", "", "Name of the profile to scale to, overrides --scale-up and --scale-down")
+	rootCmd.Flags().BoolVarP(&options.force, "force", "f", false, "Scale even if guardrails are violated")
+	rootCmd.Flags().BoolVarP(&options.autoApprove, "auto-approve", "y", false, "Skip the interactive confirmation of the plan")
+	rootCmd.Flags().DurationVar(&options.lockTimeout, "lock-timeout", 0, "How long to wait for the app lock held by another run")
+	rootCmd.Flags().DurationVar(&options.ttl, "ttl", 0, "Revert the scale-up after this duration, e.g. 4h, reverted by the reaper command or the daemon")
+	rootCmd.Flags().DurationVar(&options.timeout, "timeout", 0, "How long the run may take from planning to the end of scaling, including the confirmation, e.g. 30m, overrides the timeout of the config")
+	rootCmd.Flags().StringVar(&options.resume, "resume", "", "Resume the run with this id, skipping the resources it completed, scales to the profile of the run")
+	rootCmd.Flags().StringVar(&options.approveProtected, "approve-protected", "", "Approve scaling a protected app without confirmation, must be set to the app name")
+
+	// reconcile plans the app like a run and shares the flags of the run
+	for _, name := range []string{"profile", "force", "auto-approve", "lock-timeout", "approve-protected"} {
+		reconcileCmd.Flags().AddFlag(rootCmd.Flags().Lookup(name))
+	}
 
 	if options.configPath == "" {
 		options.configPath = defaultConfigPath
@@ -36,31 +81,188 @@ var rootCmd = &cobra.Command{
 	Short: "AWS Auto Scaler CLI is a simple CLI tool to scale AWS infrastructure services via YAML config files",
 	Long: `AWS Auto Scaler CLI is a CLI tool to scale AWS infrastructure services via YAML config files,
 It is designed to scale AWS infrastructure services such as DynamoDB, Kinesis, Elasticache, EC2 etc.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if err := logging.Init(os.Stderr, options.logLevel, options.logFormat); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		shutdown, err := tracing.Init(context.Background(), options.otlpEndpoint)
+		if err != nil {
+			fatal("error initializing tracing", logging.Err(err))
+		}
+		shutdownTracing = shutdown
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		flushTraces()
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		scalingResponse, err := pkg.ScaleApp(options.scaleUpFlag, options.configPath)
+		profile := options.selectedProfile()
+		if options.resume != "" && options.profile == "" && !options.scaleUpFlag && !options.scaleDownFlag {
+			profile = ""
+		}
+
+		// the signals are handled from planning on, so the app lock is released when the run is canceled before scaling
+		ctx, stop := cancelOnSignal()
+		defer stop()
+
+		scalingPlan, err := pkg.PlanApp(ctx, options.configPath, pkg.ScaleOptions{
+			Profile:     profile,
+			Force:       options.force,
+			LockTimeout: options.lockTimeout,
+			TTL:         options.ttl,
+			Resume:      options.resume,
+			Timeout:     options.timeout,
+		})
 		if err != nil {
-			log.Fatalf("error scaling app: %v", err)
+			fatal("error planning app", logging.Err(err))
+		}
+		if ctx.Err() != nil {
+			if err := scalingPlan.Discard(); err != nil {
+				slog.Error("error releasing app lock", logging.Err(err))
+			}
+			slog.Warn("planning canceled, nothing was scaled")
+			exitCode = exitCanceled
+			return
 		}
 
-		if scalingResponse.ContainsFailedServices {
-			log.Printf("scaling completed with errors")
-			for region, scalingErrors := range scalingResponse.RegionalFailedServices {
-				fmt.Printf("----------region: %s------------\n", region)
-				for i, scalingError := range scalingErrors {
-					if i != 0 {
-						fmt.Println("------------------------------------------------")
-					}
-					fmt.Printf("service: %s\nidentifier: %s\nerror: %v\n", scalingError.ServiceName, scalingError.IdentifierId, scalingError.Err)
+		if scalingPlan.HasChanges() && (options.force || !scalingPlan.ViolatesGuardrails()) {
+			printPlan(scalingPlan)
+			if err := confirmScaling(ctx, scalingPlan); err != nil {
+				if discardErr := scalingPlan.Decline(context.Background()); discardErr != nil {
+					slog.Error("error releasing app lock", logging.Err(discardErr))
 				}
+				if ctx.Err() != nil {
+					slog.Warn("confirmation canceled, nothing was scaled")
+					exitCode = exitCanceled
+					return
+				}
+				fatal("error scaling app", logging.Err(err))
+			}
+		}
+
+		scalingResponse, err := pkg.ApplyPlan(ctx, scalingPlan)
+		pushMetrics(scalingPlan.ScalingConfig.Name)
+		if err != nil {
+			if errors.Is(err, pkg.ErrGuardrailViolation) {
+				fatal("error scaling app, use --force to override", logging.Err(err))
 			}
-		} else {
-			log.Printf("scaling completed successfully")
+			fatal("error scaling app", logging.Err(err))
+		}
+
+		exitCode = printScalingResponse(scalingResponse)
+		if scalingResponse.ContainsFailedServices && !scalingResponse.GuardrailsViolated {
+			slog.Info("resume the run to retry the resources it didn't scale", "flag", "--resume "+scalingResponse.RunId)
 		}
 	},
 }
 
+// cancelOnSignal returns a context canceled by the first SIGINT or SIGTERM, so the run skips the resources
+// it hasn't started scaling and reports the partial result, a second signal exits right away
+func cancelOnSignal() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			slog.Warn("canceling run, waiting for the resources being scaled, signal again to exit immediately", "signal", sig.String())
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+// printScalingResponse prints the result of the run and returns the exit code of the run
+func printScalingResponse(scalingResponse *pkg.ScalingResponse) int {
+	if !scalingResponse.ContainsFailedServices {
+		slog.Info("scaling completed successfully")
+		return 0
+	}
+
+	code := 0
+	switch {
+	case scalingResponse.GuardrailsViolated:
+		slog.Error("scaling aborted, guardrails violated, use --force to override")
+	case scalingResponse.Canceled:
+		code = exitCanceled
+		slog.Warn("scaling canceled, the resources not yet being scaled were skipped", "scaled", len(scalingResponse.ScaledResources))
+		printScaledResources(scalingResponse.ScaledResources)
+	default:
+		slog.Error("scaling completed with errors")
+	}
+	for region, scalingErrors := range scalingResponse.RegionalFailedServices {
+		fmt.Printf("----------region: %s------------\n", region)
+		for i, scalingError := range scalingErrors {
+			if i != 0 {
+				fmt.Println("------------------------------------------------")
+			}
+			fmt.Printf("service: %s\nidentifier: %s\nerror: %v\n", scalingError.ServiceName, scalingError.IdentifierId, scalingError.Err)
+		}
+	}
+	return code
+}
+
+func printScaledResources(resources []pkg.ResourceResult) {
+	if len(resources) == 0 {
+		return
+	}
+
+	fmt.Println("----------scaled------------")
+	for _, resource := range resources {
+		fmt.Printf("region: %s, service: %s, identifier: %s, capacity: %v -> %v\n",
+			resource.Region, resource.ServiceName, resource.IdentifierId, resource.Before, resource.After)
+	}
+}
+
+func pushMetrics(appName string) {
+	if options.pushgatewayURL == "" {
+		return
+	}
+	if err := metrics.Push(options.pushgatewayURL, appName); err != nil {
+		slog.Error("error pushing metrics", logging.Err(err))
+	}
+}
+
+// fatal logs the error and exits, use it instead of log.Fatalf so the line is structured. The traces are flushed
+// first, as exiting skips PersistentPostRun.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	flushTraces()
+	os.Exit(1)
+}
+
+func flushTraces() {
+	if shutdownTracing == nil {
+		return
+	}
+	if err := shutdownTracing(context.Background()); err != nil {
+		slog.Error("error flushing traces", logging.Err(err))
+	}
+}
+
+func (o *Options) selectedProfile() string {
+	if o.profile != "" {
+		return o.profile
+	}
+	if o.scaleUpFlag {
+		return config.ScaleUpProfile
+	}
+	return config.ScaleDownProfile
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
 }
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/api"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/metrics"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+const (
+	apiTokenEnv   = "SCALER_API_TOKEN"
+	forceTokenEnv = "SCALER_API_FORCE_TOKEN"
+)
+
+type ServeOptions struct {
+	addr           string
+	configDir      string
+	tokenFile      string
+	forceTokenFile string
+}
+
+var serveOptions *ServeOptions
+
+func init() {
+	serveOptions = &ServeOptions{}
+
+	serveCmd.Flags().StringVar(&serveOptions.addr, "addr", ":8080", "Address to serve the API on")
+	serveCmd.Flags().StringVar(&serveOptions.configDir, "config-dir", "", "Directory of app configs (*.yaml, *.yml) to serve, defaults to the app of --config")
+	serveCmd.Flags().StringVar(&serveOptions.tokenFile, "token-file", "", "File with the bearer token clients must send, defaults to the "+apiTokenEnv+" environment variable")
+	serveCmd.Flags().StringVar(&serveOptions.forceTokenFile, "force-token-file", "", "File with the bearer token clients must send to scale with force, defaults to the "+forceTokenEnv+" environment variable, force is refused without it")
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a REST API to plan and scale apps",
+	Long: `Serves a REST API to list the apps, plan and scale them to a profile and query their history.
+Runs are applied in the background, the API returns their run id to poll the status with.
+Every request must send the token as "Authorization: Bearer <token>", except /healthz and /metrics.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		token, err := readToken(serveOptions.tokenFile, apiTokenEnv)
+		if err == nil && token == "" {
+			err = errors.New("no token, pass --token-file or set " + apiTokenEnv)
+		}
+		if err != nil {
+			fatal("error reading api token", logging.Err(err))
+		}
+
+		forceToken, err := readToken(serveOptions.forceTokenFile, forceTokenEnv)
+		if err == nil && forceToken == token {
+			err = errors.New("the force token must differ from the api token")
+		}
+		if err != nil {
+			fatal("error reading force token", logging.Err(err))
+		}
+
+		configPaths, err := serveConfigPaths()
+		if err != nil {
+			fatal("error listing configs", logging.Err(err))
+		}
+
+		server, err := api.New(configPaths, token, forceToken)
+		if err != nil {
+			fatal("error starting api server", logging.Err(err))
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/v1/", server.Handler())
+		mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		mux.Handle("/metrics", metrics.Handler())
+		httpServer := &http.Server{Addr: serveOptions.addr, Handler: mux}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		go func() {
+			<-ctx.Done()
+			if err := httpServer.Shutdown(context.Background()); err != nil {
+				slog.Error("error shutting down api server", logging.Err(err))
+			}
+			server.Cancel()
+		}()
+
+		slog.Info("serving api", "addr", serveOptions.addr, "apps", len(configPaths))
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("error serving api", logging.Err(err))
+		}
+
+		slog.Info("canceling runs in progress, waiting for the resources being scaled")
+		server.Wait()
+	},
+}
+
+// readToken reads the token from the file, or the environment variable without a file
+func readToken(tokenFile string, env string) (string, error) {
+	if tokenFile == "" {
+		return os.Getenv(env), nil
+	}
+
+	data, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", errors.New("token file is empty")
+	}
+	return token, nil
+}
+
+func serveConfigPaths() ([]string, error) {
+	if serveOptions.configDir == "" {
+		return []string{options.configPath}, nil
+	}
+
+	var configPaths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(serveOptions.configDir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		configPaths = append(configPaths, matches...)
+	}
+	if len(configPaths) == 0 {
+		return nil, errors.New("no configs found in " + serveOptions.configDir)
+	}
+	sort.Strings(configPaths)
+	return configPaths, nil
+}
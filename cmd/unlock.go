@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/Cool-fire/aws-infra-scaler/pkg"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+func init() {
+	rootCmd.AddCommand(forceUnlockCmd)
+}
+
+var forceUnlockCmd = &cobra.Command{
+	Use:   "force-unlock",
+	Short: "Release the lock of an app left behind by a crashed or interrupted run",
+	Long: `Releases the lock of the app defined in the config regardless of which run holds it.
+Only use it when no other run is scaling the app, otherwise both runs may leave resources in a mixed state.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := pkg.ForceUnlock(options.configPath); err != nil {
+			fatal("error unlocking app", logging.Err(err))
+		}
+		slog.Info("app unlocked")
+	},
+}
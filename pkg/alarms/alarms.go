@@ -0,0 +1,99 @@
+package alarms
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"sort"
+)
+
+// the alarm actions APIs and DescribeAlarms accept up to 100 alarm names
+const maxAlarmNames = 100
+
+var alarmTypes = []types.AlarmType{types.AlarmTypeMetricAlarm, types.AlarmTypeCompositeAlarm}
+
+// Client is the part of the CloudWatch API the actions of alarms are managed with
+type Client interface {
+	cloudwatch.DescribeAlarmsAPIClient
+	DisableAlarmActions(ctx context.Context, params *cloudwatch.DisableAlarmActionsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DisableAlarmActionsOutput, error)
+	EnableAlarmActions(ctx context.Context, params *cloudwatch.EnableAlarmActionsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.EnableAlarmActionsOutput, error)
+}
+
+// WithActionsEnabled returns the alarms with the names or prefixes whose actions are enabled,
+// alarms whose actions are already disabled are left out so they stay disabled
+func WithActionsEnabled(ctx context.Context, client Client, names []string, prefixes []string) ([]string, error) {
+	enabled := make(map[string]bool)
+	collect := func(input *cloudwatch.DescribeAlarmsInput) error {
+		paginator := cloudwatch.NewDescribeAlarmsPaginator(client, input)
+		for paginator.HasMorePages() {
+			output, err := paginator.NextPage(ctx)
+			if err != nil {
+				return fmt.Errorf("error describing alarms: %w", err)
+			}
+			for _, alarm := range output.MetricAlarms {
+				if aws.ToBool(alarm.ActionsEnabled) {
+					enabled[aws.ToString(alarm.AlarmName)] = true
+				}
+			}
+			for _, alarm := range output.CompositeAlarms {
+				if aws.ToBool(alarm.ActionsEnabled) {
+					enabled[aws.ToString(alarm.AlarmName)] = true
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, batch := range batches(names) {
+		if err := collect(&cloudwatch.DescribeAlarmsInput{AlarmNames: batch, AlarmTypes: alarmTypes}); err != nil {
+			return nil, err
+		}
+	}
+	for _, prefix := range prefixes {
+		if err := collect(&cloudwatch.DescribeAlarmsInput{AlarmNamePrefix: aws.String(prefix), AlarmTypes: alarmTypes}); err != nil {
+			return nil, err
+		}
+	}
+
+	alarmNames := make([]string, 0, len(enabled))
+	for name := range enabled {
+		alarmNames = append(alarmNames, name)
+	}
+	sort.Strings(alarmNames)
+	return alarmNames, nil
+}
+
+func DisableActions(ctx context.Context, client Client, names []string) error {
+	for _, batch := range batches(names) {
+		if _, err := client.DisableAlarmActions(ctx, &cloudwatch.DisableAlarmActionsInput{AlarmNames: batch}); err != nil {
+			return fmt.Errorf("error disabling alarm actions: %w", err)
+		}
+	}
+	return nil
+}
+
+// EnableActions enables the actions of every alarm even if enabling some fails
+func EnableActions(ctx context.Context, client Client, names []string) error {
+	var failed []string
+	var lastErr error
+	for _, batch := range batches(names) {
+		if _, err := client.EnableAlarmActions(ctx, &cloudwatch.EnableAlarmActionsInput{AlarmNames: batch}); err != nil {
+			failed = append(failed, batch...)
+			lastErr = err
+		}
+	}
+	if lastErr != nil {
+		return fmt.Errorf("error enabling actions of alarms %v: %w", failed, lastErr)
+	}
+	return nil
+}
+
+func batches(names []string) [][]string {
+	var batches [][]string
+	for start := 0; start < len(names); start += maxAlarmNames {
+		batches = append(batches, names[start:min(start+maxAlarmNames, len(names))])
+	}
+	return batches
+}
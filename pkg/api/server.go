@@ -0,0 +1,551 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/audit"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/lock"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+	RunAborted   = "aborted"
+	RunCanceled  = "canceled"
+)
+
+const (
+	// maxRequestBytes limits the body of requests, scale requests only have a few fields
+	maxRequestBytes = 64 << 10
+
+	// finishedRunRetention is how long finished runs can be polled, at most maxFinishedRuns of them are kept
+	finishedRunRetention = 24 * time.Hour
+	maxFinishedRuns      = 1000
+)
+
+var errNotFound = errors.New("not found")
+
+type forceKey struct{}
+
+type App struct {
+	Name       string   `json:"name"`
+	ConfigPath string   `json:"configPath"`
+	Protected  bool     `json:"protected"`
+	Profiles   []string `json:"profiles"`
+}
+
+type ScaleRequest struct {
+	Profile          string `json:"profile"`
+	Force            bool   `json:"force,omitempty"`
+	TTL              string `json:"ttl,omitempty"`
+	Timeout          string `json:"timeout,omitempty"`
+	ApproveProtected string `json:"approveProtected,omitempty"`
+	// Resume is the id of a run to resume, the profile defaults to the profile of the run
+	Resume string `json:"resume,omitempty"`
+}
+
+type Resource struct {
+	Region       string           `json:"region"`
+	ServiceName  string           `json:"serviceName"`
+	IdentifierId string           `json:"identifierId"`
+	Current      service.Capacity `json:"current,omitempty"`
+	Target       service.Capacity `json:"target"`
+	Unchanged    bool             `json:"unchanged,omitempty"`
+}
+
+type FailedService struct {
+	Region       string `json:"region"`
+	ServiceName  string `json:"serviceName"`
+	IdentifierId string `json:"identifierId"`
+	Error        string `json:"error"`
+}
+
+type Plan struct {
+	RunId               string          `json:"runId"`
+	AppName             string          `json:"appName"`
+	Profile             string          `json:"profile"`
+	Resources           []Resource      `json:"resources"`
+	FailedServices      []FailedService `json:"failedServices,omitempty"`
+	GuardrailViolations []FailedService `json:"guardrailViolations,omitempty"`
+	QuotasNotChecked    []FailedService `json:"quotasNotChecked,omitempty"`
+}
+
+type Run struct {
+	Plan
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Server exposes the scaling of a set of apps over HTTP, runs are applied in the background
+// and their status is kept in memory
+type Server struct {
+	Token string
+	// ForceToken authorizes runs with force, which bypass the guardrails, force is refused when it's empty
+	ForceToken string
+
+	apps    map[string]App
+	mu      sync.RWMutex
+	runs    map[string]*Run
+	cancels map[string]context.CancelFunc
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+func New(configPaths []string, token string, forceToken string) (*Server, error) {
+	if token == "" {
+		return nil, errors.New("an api token is required")
+	}
+
+	apps := make(map[string]App)
+	for _, configPath := range configPaths {
+		scalingConfig, err := config.ReadConfig(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading config %s: %w", configPath, err)
+		}
+		if existing, ok := apps[scalingConfig.Name]; ok {
+			return nil, fmt.Errorf("app %s is configured in both %s and %s", scalingConfig.Name, existing.ConfigPath, configPath)
+		}
+
+		profiles := []string{config.ScaleUpProfile, config.ScaleDownProfile}
+		for _, profile := range scalingConfig.Profiles {
+			profiles = append(profiles, profile.Name)
+		}
+		apps[scalingConfig.Name] = App{
+			Name:       scalingConfig.Name,
+			ConfigPath: configPath,
+			Protected:  scalingConfig.Protected,
+			Profiles:   profiles,
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		Token:      token,
+		ForceToken: forceToken,
+		apps:       apps,
+		runs:       make(map[string]*Run),
+		cancels:    make(map[string]context.CancelFunc),
+		ctx:        ctx,
+		cancel:     cancel,
+	}, nil
+}
+
+// Cancel cancels the runs in the background like an interrupted CLI run, call Wait to wait for them
+func (s *Server) Cancel() {
+	s.cancel()
+}
+
+// Wait blocks until the runs in the background finished
+func (s *Server) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Server) Handler() http.Handler {
+	return s.authenticate(http.HandlerFunc(s.route))
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		switch {
+		case ok && s.ForceToken != "" && tokenMatches(token, s.ForceToken):
+			r = r.WithContext(context.WithValue(r.Context(), forceKey{}, true))
+		case ok && tokenMatches(token, s.Token):
+		default:
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("invalid or missing bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func tokenMatches(token string, expected string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// canForce tells whether the request was authenticated with the force token
+func canForce(r *http.Request) bool {
+	allowed, _ := r.Context().Value(forceKey{}).(bool)
+	return allowed
+}
+
+// route serves
+//
+//	GET  /v1/apps
+//	POST /v1/apps/{app}/plan
+//	POST /v1/apps/{app}/runs
+//	GET  /v1/apps/{app}/history
+//	GET  /v1/runs
+//	GET  /v1/runs/{runId}
+//	POST /v1/runs/{runId}/cancel
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "v1" {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && parts[1] == "apps":
+		s.allow(w, r, http.MethodGet, s.listApps)
+	case len(parts) == 4 && parts[1] == "apps" && parts[3] == "plan":
+		s.allow(w, r, http.MethodPost, s.withApp(parts[2], s.plan))
+	case len(parts) == 4 && parts[1] == "apps" && parts[3] == "runs":
+		s.allow(w, r, http.MethodPost, s.withApp(parts[2], s.startRun))
+	case len(parts) == 4 && parts[1] == "apps" && parts[3] == "history":
+		s.allow(w, r, http.MethodGet, s.withApp(parts[2], s.history))
+	case len(parts) == 2 && parts[1] == "runs":
+		s.allow(w, r, http.MethodGet, s.listRuns)
+	case len(parts) == 3 && parts[1] == "runs":
+		s.allow(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request) { s.getRun(w, parts[2]) })
+	case len(parts) == 4 && parts[1] == "runs" && parts[3] == "cancel":
+		s.allow(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request) { s.cancelRun(w, parts[2]) })
+	default:
+		writeError(w, http.StatusNotFound, errNotFound)
+	}
+}
+
+func (s *Server) allow(w http.ResponseWriter, r *http.Request, method string, handler http.HandlerFunc) {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	handler(w, r)
+}
+
+func (s *Server) withApp(name string, handler func(w http.ResponseWriter, r *http.Request, app App)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		app, ok := s.apps[name]
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("app %s not found", name))
+			return
+		}
+		handler(w, r, app)
+	}
+}
+
+func (s *Server) listApps(w http.ResponseWriter, r *http.Request) {
+	apps := make([]App, 0, len(s.apps))
+	for _, app := range s.apps {
+		apps = append(apps, app)
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].Name < apps[j].Name
+	})
+	writeJSON(w, http.StatusOK, apps)
+}
+
+func (s *Server) plan(w http.ResponseWriter, r *http.Request, app App) {
+	_, scaleOptions, err := decodeScaleRequest(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	scalingPlan, err := pkg.PlanApp(r.Context(), app.ConfigPath, scaleOptions)
+	if err != nil {
+		writeError(w, planErrorStatus(err), err)
+		return
+	}
+	if err := scalingPlan.Discard(); err != nil {
+		slog.Error("error releasing app lock", logging.AppKey, app.Name, logging.Err(err))
+	}
+
+	writeJSON(w, http.StatusOK, newPlan(scalingPlan))
+}
+
+func (s *Server) startRun(w http.ResponseWriter, r *http.Request, app App) {
+	request, scaleOptions, err := decodeScaleRequest(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if request.Force && !canForce(r) {
+		writeError(w, http.StatusForbidden, errors.New("force bypasses the guardrails and requires the force token"))
+		return
+	}
+	if app.Protected && request.ApproveProtected != app.Name {
+		writeError(w, http.StatusForbidden, fmt.Errorf("app %s is protected, set approveProtected to the app name", app.Name))
+		return
+	}
+
+	scalingPlan, err := pkg.PlanApp(r.Context(), app.ConfigPath, scaleOptions)
+	if err != nil {
+		writeError(w, planErrorStatus(err), err)
+		return
+	}
+
+	run := &Run{
+		Plan:      newPlan(scalingPlan),
+		Status:    RunRunning,
+		StartedAt: scalingPlan.StartedAt,
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.mu.Lock()
+	s.runs[run.RunId] = run
+	s.cancels[run.RunId] = cancel
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		scalingResponse, err := pkg.ApplyPlan(ctx, scalingPlan)
+		s.finishRun(run.RunId, scalingResponse, err)
+	}()
+
+	w.Header().Set("Location", "/v1/runs/"+run.RunId)
+	writeJSON(w, http.StatusAccepted, s.snapshot(run))
+}
+
+func (s *Server) finishRun(runId string, scalingResponse *pkg.ScalingResponse, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run := s.runs[runId]
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	delete(s.cancels, runId)
+	defer s.evictRuns(finishedAt)
+
+	switch {
+	case err != nil:
+		run.Status = RunFailed
+		if errors.Is(err, pkg.ErrGuardrailViolation) {
+			run.Status = RunAborted
+		}
+		run.Error = err.Error()
+	case scalingResponse.GuardrailsViolated:
+		run.Status = RunAborted
+		run.Error = "scaling aborted, guardrails violated"
+	case scalingResponse.Canceled:
+		run.Status = RunCanceled
+		run.Error = "scaling canceled"
+	case scalingResponse.ContainsFailedServices:
+		run.Status = RunFailed
+		run.Error = "scaling completed with errors"
+	default:
+		run.Status = RunSucceeded
+	}
+
+	if scalingResponse != nil {
+		run.FailedServices = nil
+		for _, scalingErrors := range scalingResponse.RegionalFailedServices {
+			run.FailedServices = append(run.FailedServices, newFailedServices(scalingErrors)...)
+		}
+	}
+}
+
+// evictRuns drops the finished runs older than the retention and the oldest ones beyond maxFinishedRuns, the lock
+// must be held
+func (s *Server) evictRuns(now time.Time) {
+	var finished []*Run
+	for runId, run := range s.runs {
+		if run.FinishedAt == nil {
+			continue
+		}
+		if now.Sub(*run.FinishedAt) > finishedRunRetention {
+			delete(s.runs, runId)
+			continue
+		}
+		finished = append(finished, run)
+	}
+
+	if len(finished) <= maxFinishedRuns {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].FinishedAt.Before(*finished[j].FinishedAt)
+	})
+	for _, run := range finished[:len(finished)-maxFinishedRuns] {
+		delete(s.runs, run.RunId)
+	}
+}
+
+func (s *Server) listRuns(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	runs := make([]Run, 0, len(s.runs))
+	for _, run := range s.runs {
+		runs = append(runs, *run)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	writeJSON(w, http.StatusOK, runs)
+}
+
+func (s *Server) getRun(w http.ResponseWriter, runId string) {
+	s.mu.RLock()
+	run, ok := s.runs[runId]
+	s.mu.RUnlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("run %s not found", runId))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.snapshot(run))
+}
+
+func (s *Server) cancelRun(w http.ResponseWriter, runId string) {
+	s.mu.RLock()
+	run, ok := s.runs[runId]
+	cancel, running := s.cancels[runId]
+	s.mu.RUnlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("run %s not found", runId))
+		return
+	}
+	if !running {
+		writeError(w, http.StatusConflict, fmt.Errorf("run %s already finished", runId))
+		return
+	}
+
+	cancel()
+	writeJSON(w, http.StatusAccepted, s.snapshot(run))
+}
+
+func (s *Server) snapshot(run *Run) Run {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return *run
+}
+
+func (s *Server) history(w http.ResponseWriter, r *http.Request, app App) {
+	query := audit.Query{
+		Resource: r.URL.Query().Get("resource"),
+		Limit:    20,
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %s", limit))
+			return
+		}
+		query.Limit = value
+	}
+	if since := r.URL.Query().Get("since"); since != "" {
+		duration, err := time.ParseDuration(since)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid since %s, expected a duration like 24h", since))
+			return
+		}
+		query.Since = time.Now().Add(-duration)
+	}
+
+	records, err := pkg.History(app.ConfigPath, query)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if records == nil {
+		records = []audit.Record{}
+	}
+	writeJSON(w, http.StatusOK, records)
+}
+
+func decodeScaleRequest(w http.ResponseWriter, r *http.Request) (ScaleRequest, pkg.ScaleOptions, error) {
+	var request ScaleRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&request); err != nil {
+		return request, pkg.ScaleOptions{}, fmt.Errorf("invalid request body: %w", err)
+	}
+	if request.Profile == "" && request.Resume == "" {
+		return request, pkg.ScaleOptions{}, errors.New("profile is required")
+	}
+
+	scaleOptions := pkg.ScaleOptions{
+		Profile: request.Profile,
+		Force:   request.Force,
+		Resume:  request.Resume,
+	}
+	if request.TTL != "" {
+		ttl, err := time.ParseDuration(request.TTL)
+		if err != nil {
+			return request, pkg.ScaleOptions{}, fmt.Errorf("invalid ttl %s, expected a duration like 4h", request.TTL)
+		}
+		scaleOptions.TTL = ttl
+	}
+	if request.Timeout != "" {
+		timeout, err := time.ParseDuration(request.Timeout)
+		if err != nil || timeout < 0 {
+			return request, pkg.ScaleOptions{}, fmt.Errorf("invalid timeout %s, expected a duration like 30m", request.Timeout)
+		}
+		scaleOptions.Timeout = timeout
+	}
+	return request, scaleOptions, nil
+}
+
+func planErrorStatus(err error) int {
+	if errors.Is(err, lock.ErrLockHeld) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func newPlan(scalingPlan *pkg.ScalingPlan) Plan {
+	plan := Plan{
+		RunId:               scalingPlan.RunId,
+		AppName:             scalingPlan.ScalingConfig.Name,
+		Profile:             scalingPlan.Profile,
+		Resources:           []Resource{},
+		FailedServices:      newFailedServices(scalingPlan.FailedServices),
+		GuardrailViolations: newFailedServices(scalingPlan.GuardrailViolations),
+		QuotasNotChecked:    newFailedServices(scalingPlan.QuotasNotChecked),
+	}
+	for _, resourcePlan := range scalingPlan.Resources {
+		plan.Resources = append(plan.Resources, Resource{
+			Region:       resourcePlan.Region,
+			ServiceName:  resourcePlan.ServiceName,
+			IdentifierId: resourcePlan.IdentifierId,
+			Current:      resourcePlan.Current,
+			Target:       resourcePlan.Target,
+			Unchanged:    !resourcePlan.IsChange(),
+		})
+	}
+	return plan
+}
+
+func newFailedServices(scalingErrors []*service.ScalingError) []FailedService {
+	var failedServices []FailedService
+	for _, scalingError := range scalingErrors {
+		failedServices = append(failedServices, FailedService{
+			Region:       scalingError.Region,
+			ServiceName:  scalingError.ServiceName,
+			IdentifierId: scalingError.IdentifierId,
+			Error:        scalingError.Err.Error(),
+		})
+	}
+	return failedServices
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		slog.Error("error encoding response", logging.Err(err))
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
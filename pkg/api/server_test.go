@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer() *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	finishedAt := time.Now()
+	return &Server{
+		Token:      "token",
+		ForceToken: "force-token",
+		apps: map[string]App{
+			"my-app": {Name: "my-app", ConfigPath: "/nonexistent/config.yaml"},
+		},
+		runs: map[string]*Run{
+			"finished": {Plan: Plan{RunId: "finished"}, Status: RunSucceeded, FinishedAt: &finishedAt},
+		},
+		cancels: make(map[string]context.CancelFunc),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		body       string
+		wantStatus int
+		wantAllow  string
+	}{
+		{name: "missing token", method: http.MethodGet, path: "/v1/apps", wantStatus: http.StatusUnauthorized},
+		{name: "invalid token", method: http.MethodGet, path: "/v1/apps", token: "other", wantStatus: http.StatusUnauthorized},
+		{name: "list apps", method: http.MethodGet, path: "/v1/apps", token: "token", wantStatus: http.StatusOK},
+		{name: "list apps with the force token", method: http.MethodGet, path: "/v1/apps", token: "force-token", wantStatus: http.StatusOK},
+		{name: "trailing slash", method: http.MethodGet, path: "/v1/apps/", token: "token", wantStatus: http.StatusOK},
+		{name: "wrong method", method: http.MethodPost, path: "/v1/apps", token: "token", wantStatus: http.StatusMethodNotAllowed, wantAllow: http.MethodGet},
+		{name: "unknown version", method: http.MethodGet, path: "/v2/apps", token: "token", wantStatus: http.StatusNotFound},
+		{name: "unknown path", method: http.MethodGet, path: "/v1/apps/my-app/unknown", token: "token", wantStatus: http.StatusNotFound},
+		{name: "unknown app", method: http.MethodPost, path: "/v1/apps/other/plan", token: "token", body: `{"profile": "scale-up"}`, wantStatus: http.StatusNotFound},
+		{name: "plan without profile", method: http.MethodPost, path: "/v1/apps/my-app/plan", token: "token", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid body", method: http.MethodPost, path: "/v1/apps/my-app/runs", token: "token", body: `{"profile":`, wantStatus: http.StatusBadRequest},
+		{name: "body too large", method: http.MethodPost, path: "/v1/apps/my-app/runs", token: "token", body: `{"profile": "` + strings.Repeat("a", maxRequestBytes) + `"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid ttl", method: http.MethodPost, path: "/v1/apps/my-app/runs", token: "token", body: `{"profile": "scale-up", "ttl": "soon"}`, wantStatus: http.StatusBadRequest},
+		{name: "force without the force token", method: http.MethodPost, path: "/v1/apps/my-app/runs", token: "token", body: `{"profile": "scale-up", "force": true}`, wantStatus: http.StatusForbidden},
+		{name: "list runs", method: http.MethodGet, path: "/v1/runs", token: "token", wantStatus: http.StatusOK},
+		{name: "get run", method: http.MethodGet, path: "/v1/runs/finished", token: "token", wantStatus: http.StatusOK},
+		{name: "unknown run", method: http.MethodGet, path: "/v1/runs/unknown", token: "token", wantStatus: http.StatusNotFound},
+		{name: "cancel finished run", method: http.MethodPost, path: "/v1/runs/finished/cancel", token: "token", wantStatus: http.StatusConflict},
+		{name: "cancel unknown run", method: http.MethodPost, path: "/v1/runs/unknown/cancel", token: "token", wantStatus: http.StatusNotFound},
+		{name: "cancel with get", method: http.MethodGet, path: "/v1/runs/finished/cancel", token: "token", wantStatus: http.StatusMethodNotAllowed, wantAllow: http.MethodPost},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			if test.token != "" {
+				request.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			recorder := httptest.NewRecorder()
+
+			newTestServer().Handler().ServeHTTP(recorder, request)
+
+			if recorder.Code != test.wantStatus {
+				t.Errorf("%s %s returned status %d, want %d: %s", test.method, test.path, recorder.Code, test.wantStatus, recorder.Body)
+			}
+			if allow := recorder.Header().Get("Allow"); allow != test.wantAllow {
+				t.Errorf("%s %s returned Allow %q, want %q", test.method, test.path, allow, test.wantAllow)
+			}
+		})
+	}
+}
+
+func TestCancelRun(t *testing.T) {
+	server := newTestServer()
+	ctx, cancel := context.WithCancel(server.ctx)
+	server.runs["running"] = &Run{Plan: Plan{RunId: "running"}, Status: RunRunning}
+	server.cancels["running"] = cancel
+
+	request := httptest.NewRequest(http.MethodPost, "/v1/runs/running/cancel", nil)
+	request.Header.Set("Authorization", "Bearer token")
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusAccepted {
+		t.Fatalf("canceling a running run returned status %d, want %d", recorder.Code, http.StatusAccepted)
+	}
+	if ctx.Err() == nil {
+		t.Error("context of the run wasn't canceled")
+	}
+}
+
+func TestEvictRuns(t *testing.T) {
+	now := time.Now()
+	finishedAt := func(age time.Duration) *time.Time {
+		finishedAt := now.Add(-age)
+		return &finishedAt
+	}
+
+	server := newTestServer()
+	server.runs = map[string]*Run{
+		"running": {Plan: Plan{RunId: "running"}, Status: RunRunning},
+		"recent":  {Plan: Plan{RunId: "recent"}, Status: RunSucceeded, FinishedAt: finishedAt(time.Minute)},
+		"expired": {Plan: Plan{RunId: "expired"}, Status: RunSucceeded, FinishedAt: finishedAt(finishedRunRetention + time.Minute)},
+	}
+	for i := 0; i < maxFinishedRuns; i++ {
+		runId := "old-" + time.Duration(i).String()
+		server.runs[runId] = &Run{Plan: Plan{RunId: runId}, Status: RunFailed, FinishedAt: finishedAt(time.Hour + time.Duration(i))}
+	}
+
+	server.evictRuns(now)
+
+	if len(server.runs) != maxFinishedRuns+1 {
+		t.Errorf("evictRuns() kept %d runs, want %d", len(server.runs), maxFinishedRuns+1)
+	}
+	for _, runId := range []string{"running", "recent"} {
+		if _, ok := server.runs[runId]; !ok {
+			t.Errorf("evictRuns() evicted run %s", runId)
+		}
+	}
+	for _, runId := range []string{"expired", "old-" + time.Duration(maxFinishedRuns-1).String()} {
+		if _, ok := server.runs[runId]; ok {
+			t.Errorf("evictRuns() kept run %s", runId)
+		}
+	}
+}
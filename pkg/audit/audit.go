@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+	OutcomeAborted   = "aborted"
+	OutcomeCanceled  = "canceled"
+	OutcomeDeclined  = "declined"
+)
+
+type Record struct {
+	RunId      string           `json:"runId"`
+	AppName    string           `json:"appName"`
+	Profile    string           `json:"profile"`
+	Caller     string           `json:"caller"`
+	ConfigHash string           `json:"configHash"`
+	StartedAt  time.Time        `json:"startedAt"`
+	FinishedAt time.Time        `json:"finishedAt"`
+	Outcome    string           `json:"outcome"`
+	Resources  []ResourceRecord `json:"resources"`
+	Errors     []ErrorRecord    `json:"errors,omitempty"`
+}
+
+type ResourceRecord struct {
+	Region       string         `json:"region"`
+	ServiceName  string         `json:"serviceName"`
+	IdentifierId string         `json:"identifierId"`
+	Before       map[string]int `json:"before,omitempty"`
+	After        map[string]int `json:"after,omitempty"`
+	// Unchanged is set when the resource was already at its target and wasn't scaled
+	Unchanged bool   `json:"unchanged,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ErrorRecord struct {
+	Region       string `json:"region"`
+	ServiceName  string `json:"serviceName"`
+	IdentifierId string `json:"identifierId"`
+	Error        string `json:"error"`
+}
+
+type Query struct {
+	AppName  string
+	Since    time.Time
+	Resource string
+	Limit    int
+}
+
+type Sink interface {
+	Write(ctx context.Context, record Record) error
+	Query(ctx context.Context, query Query) ([]Record, error)
+}
+
+func (q Query) matches(record Record) bool {
+	if q.AppName != "" && record.AppName != q.AppName {
+		return false
+	}
+	if !q.Since.IsZero() && record.StartedAt.Before(q.Since) {
+		return false
+	}
+	if q.Resource == "" {
+		return true
+	}
+
+	for _, resource := range record.Resources {
+		if strings.Contains(resource.IdentifierId, q.Resource) {
+			return true
+		}
+	}
+	for _, err := range record.Errors {
+		if strings.Contains(err.IdentifierId, q.Resource) {
+			return true
+		}
+	}
+	return false
+}
+
+func filterRecords(records []Record, query Query) []Record {
+	var matched []Record
+	for _, record := range records {
+		if query.matches(record) {
+			matched = append(matched, record)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].StartedAt.After(matched[j].StartedAt)
+	})
+
+	if query.Limit > 0 && len(matched) > query.Limit {
+		matched = matched[:query.Limit]
+	}
+	return matched
+}
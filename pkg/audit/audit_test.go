@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testRecords() []Record {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	return []Record{
+		{
+			RunId:     "run-1",
+			AppName:   "orders",
+			StartedAt: start,
+			Resources: []ResourceRecord{{Region: "us-east-1", ServiceName: "ec2", IdentifierId: "orders-asg"}},
+		},
+		{
+			RunId:     "run-2",
+			AppName:   "payments",
+			StartedAt: start.Add(time.Hour),
+			Resources: []ResourceRecord{{Region: "us-east-1", ServiceName: "dynamodb", IdentifierId: "table/payments"}},
+		},
+		{
+			RunId:     "run-3",
+			AppName:   "orders",
+			StartedAt: start.Add(2 * time.Hour),
+			Errors:    []ErrorRecord{{Region: "eu-west-1", ServiceName: "kinesis", IdentifierId: "arn:aws:kinesis:eu-west-1:123456789012:stream/orders", Error: "throttled"}},
+		},
+	}
+}
+
+func runIds(records []Record) []string {
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.RunId)
+	}
+	return ids
+}
+
+func TestFilterRecords(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{name: "all, newest first", query: Query{}, want: []string{"run-3", "run-2", "run-1"}},
+		{name: "app", query: Query{AppName: "orders"}, want: []string{"run-3", "run-1"}},
+		{name: "since", query: Query{Since: start.Add(time.Hour)}, want: []string{"run-3", "run-2"}},
+		{name: "resource", query: Query{Resource: "orders-asg"}, want: []string{"run-1"}},
+		{name: "resource substring", query: Query{Resource: "payments"}, want: []string{"run-2"}},
+		{name: "resource of an error", query: Query{Resource: "stream/orders"}, want: []string{"run-3"}},
+		{name: "limit", query: Query{Limit: 2}, want: []string{"run-3", "run-2"}},
+		{name: "app and limit", query: Query{AppName: "orders", Limit: 1}, want: []string{"run-3"}},
+		{name: "no match", query: Query{AppName: "inventory"}, want: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := runIds(filterRecords(testRecords(), test.query)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("filterRecords(%+v) = %v, want %v", test.query, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFileSinkQuery(t *testing.T) {
+	ctx := context.Background()
+	sink := NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+
+	records, err := sink.Query(ctx, Query{})
+	if err != nil || len(records) != 0 {
+		t.Fatalf("Query() of a missing audit log = %v, %v, want no records", records, err)
+	}
+
+	for _, record := range testRecords() {
+		if err := sink.Write(ctx, record); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	records, err = sink.Query(ctx, Query{AppName: "orders"})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if got, want := runIds(records), []string{"run-3", "run-1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(records[0].Errors, testRecords()[2].Errors) {
+		t.Errorf("Query() errors = %v, want %v", records[0].Errors, testRecords()[2].Errors)
+	}
+}
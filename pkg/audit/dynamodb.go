@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"time"
+)
+
+const (
+	appNameAttribute = "AppName"
+	runKeyAttribute  = "RunKey"
+	recordAttribute  = "Record"
+
+	runKeyTimeFormat = "2006-01-02T15:04:05.000000000Z"
+)
+
+type DynamoDBSink struct {
+	Client    *dynamodb.Client
+	TableName string
+}
+
+func NewDynamoDBSink(client *dynamodb.Client, tableName string) *DynamoDBSink {
+	return &DynamoDBSink{
+		Client:    client,
+		TableName: tableName,
+	}
+}
+
+func (d *DynamoDBSink) Write(ctx context.Context, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error encoding audit record: %w", err)
+	}
+
+	_, err = d.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &d.TableName,
+		Item: map[string]types.AttributeValue{
+			appNameAttribute: &types.AttributeValueMemberS{Value: record.AppName},
+			runKeyAttribute:  &types.AttributeValueMemberS{Value: runKey(record.StartedAt, record.RunId)},
+			recordAttribute:  &types.AttributeValueMemberS{Value: string(data)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error writing audit record to dynamodb: %w", err)
+	}
+	return nil
+}
+
+func (d *DynamoDBSink) Query(ctx context.Context, query Query) ([]Record, error) {
+	if query.AppName == "" {
+		return nil, fmt.Errorf("app name is required to query the dynamodb audit sink")
+	}
+
+	input := dynamodb.QueryInput{
+		TableName:              &d.TableName,
+		KeyConditionExpression: aws.String("#appName = :appName AND #runKey >= :since"),
+		ExpressionAttributeNames: map[string]string{
+			"#appName": appNameAttribute,
+			"#runKey":  runKeyAttribute,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":appName": &types.AttributeValueMemberS{Value: query.AppName},
+			":since":   &types.AttributeValueMemberS{Value: query.Since.UTC().Format(runKeyTimeFormat)},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}
+
+	var records []Record
+	paginator := dynamodb.NewQueryPaginator(d.Client, &input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error querying audit records: %w", err)
+		}
+
+		for _, item := range page.Items {
+			data, ok := item[recordAttribute].(*types.AttributeValueMemberS)
+			if !ok {
+				continue
+			}
+
+			var record Record
+			if err := json.Unmarshal([]byte(data.Value), &record); err != nil {
+				return nil, fmt.Errorf("error decoding audit record: %w", err)
+			}
+
+			if query.matches(record) {
+				records = append(records, record)
+			}
+		}
+
+		if query.Limit > 0 && len(records) >= query.Limit {
+			break
+		}
+	}
+
+	return filterRecords(records, query), nil
+}
+
+func runKey(startedAt time.Time, runId string) string {
+	return fmt.Sprintf("%s#%s", startedAt.UTC().Format(runKeyTimeFormat), runId)
+}
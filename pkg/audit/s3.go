@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"io"
+	"path"
+	"time"
+)
+
+type S3Sink struct {
+	Client *s3.Client
+	Bucket string
+	Prefix string
+}
+
+func NewS3Sink(client *s3.Client, bucket string, prefix string) *S3Sink {
+	return &S3Sink{
+		Client: client,
+		Bucket: bucket,
+		Prefix: prefix,
+	}
+}
+
+func (s *S3Sink) Write(ctx context.Context, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error encoding audit record: %w", err)
+	}
+
+	key := path.Join(s.Prefix, record.AppName, fmt.Sprintf("%s-%s.json", record.StartedAt.UTC().Format(time.RFC3339), record.RunId))
+	_, err = s.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &s.Bucket,
+		Key:         &key,
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return fmt.Errorf("error writing audit record to s3: %w", err)
+	}
+	return nil
+}
+
+func (s *S3Sink) Query(ctx context.Context, query Query) ([]Record, error) {
+	prefix := s.Prefix
+	if query.AppName != "" {
+		prefix = path.Join(s.Prefix, query.AppName) + "/"
+	}
+
+	var records []Record
+	paginator := s3.NewListObjectsV2Paginator(s.Client, &s3.ListObjectsV2Input{
+		Bucket: &s.Bucket,
+		Prefix: &prefix,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing audit records: %w", err)
+		}
+
+		for _, object := range page.Contents {
+			if !query.Since.IsZero() && object.LastModified != nil && object.LastModified.Before(query.Since) {
+				continue
+			}
+
+			record, err := s.read(ctx, object.Key)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, *record)
+		}
+	}
+
+	return filterRecords(records, query), nil
+}
+
+func (s *S3Sink) read(ctx context.Context, key *string) (*Record, error) {
+	output, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &s.Bucket,
+		Key:    key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading audit record %s: %w", aws.ToString(key), err)
+	}
+	defer output.Body.Close()
+
+	data, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading audit record %s: %w", aws.ToString(key), err)
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("error decoding audit record %s: %w", aws.ToString(key), err)
+	}
+	return &record, nil
+}
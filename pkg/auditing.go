@@ -0,0 +1,157 @@
+package pkg
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/audit"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"time"
+)
+
+func newAuditSink(ctx context.Context, scalingConfig *config.ScalingConfig, awsConfig AWSConfigProvider) (audit.Sink, error) {
+	auditConfig := scalingConfig.Audit
+	if auditConfig == nil {
+		return nil, nil
+	}
+
+	if auditConfig.Sink == config.FileAuditSink {
+		return audit.NewFileSink(auditConfig.Path), nil
+	}
+
+	region := auditConfig.Region
+	if region == "" {
+		region = scalingConfig.DefaultRegion()
+	}
+
+	awsCreds, err := awsConfig(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	switch auditConfig.Sink {
+	case config.S3AuditSink:
+		return audit.NewS3Sink(service.NewS3Client(awsCreds), auditConfig.Bucket, auditConfig.Prefix), nil
+	case config.DynamoDBAuditSink:
+		return audit.NewDynamoDBSink(service.NewDynamoDBClient(awsCreds), auditConfig.TableName), nil
+	default:
+		return nil, fmt.Errorf("audit sink %s is not supported", auditConfig.Sink)
+	}
+}
+
+func writeAuditRecord(ctx context.Context, scalingPlan *ScalingPlan, failedServices []*service.ScalingError, outcome string) {
+	sink, err := newAuditSink(ctx, scalingPlan.ScalingConfig, scalingPlan.scaler.awsConfig)
+	if err != nil {
+		logging.FromContext(ctx).Error("error creating audit sink", logging.Err(err))
+		return
+	}
+	if sink == nil {
+		return
+	}
+
+	record := newAuditRecord(scalingPlan, failedServices, outcome)
+	record.Caller = callerIdentity(ctx, scalingPlan)
+
+	if err := sink.Write(ctx, record); err != nil {
+		logging.FromContext(ctx).Error("error writing audit record", logging.Err(err))
+	}
+}
+
+// callerIdentity is the identity the scaler acts as, with the aws config it scales the resources of the app with
+func callerIdentity(ctx context.Context, scalingPlan *ScalingPlan) string {
+	awsCreds, err := scalingPlan.scaler.awsConfig(ctx, scalingPlan.ScalingConfig.DefaultRegion())
+	if err != nil {
+		return "unknown"
+	}
+
+	caller, err := service.CallerIdentity(ctx, awsCreds)
+	if err != nil {
+		return "unknown"
+	}
+	return caller
+}
+
+func newAuditRecord(scalingPlan *ScalingPlan, failedServices []*service.ScalingError, outcome string) audit.Record {
+	resourceErrors := make(map[string]error)
+	for _, failedService := range failedServices {
+		resourceErrors[resourceKey(failedService.Region, failedService.ServiceName, failedService.IdentifierId)] = failedService.Err
+	}
+
+	record := audit.Record{
+		RunId:      scalingPlan.RunId,
+		AppName:    scalingPlan.ScalingConfig.Name,
+		Profile:    scalingPlan.Profile,
+		ConfigHash: scalingPlan.ScalingConfig.Hash,
+		StartedAt:  scalingPlan.StartedAt,
+		FinishedAt: time.Now(),
+		Outcome:    outcome,
+	}
+
+	for _, resourcePlan := range scalingPlan.Resources {
+		resourceRecord := audit.ResourceRecord{
+			Region:       resourcePlan.Region,
+			ServiceName:  resourcePlan.ServiceName,
+			IdentifierId: resourcePlan.IdentifierId,
+			Before:       resourcePlan.Current,
+			Unchanged:    !resourcePlan.IsChange(),
+		}
+
+		key := resourceKey(resourcePlan.Region, resourcePlan.ServiceName, resourcePlan.IdentifierId)
+		if err, failed := resourceErrors[key]; failed {
+			resourceRecord.Error = err.Error()
+			delete(resourceErrors, key)
+		} else if outcome != audit.OutcomeAborted && outcome != audit.OutcomeDeclined {
+			resourceRecord.After = resourcePlan.Target
+		}
+		record.Resources = append(record.Resources, resourceRecord)
+	}
+
+	for _, failedService := range failedServices {
+		if _, ok := resourceErrors[resourceKey(failedService.Region, failedService.ServiceName, failedService.IdentifierId)]; !ok {
+			continue
+		}
+		record.Errors = append(record.Errors, audit.ErrorRecord{
+			Region:       failedService.Region,
+			ServiceName:  failedService.ServiceName,
+			IdentifierId: failedService.IdentifierId,
+			Error:        failedService.Err.Error(),
+		})
+	}
+
+	return record
+}
+
+func resourceKey(region string, serviceName string, identifierId string) string {
+	return fmt.Sprintf("%s/%s/%s", region, serviceName, identifierId)
+}
+
+func newRunId() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+func History(configPath string, query audit.Query) ([]audit.Record, error) {
+	scalingConfig, err := config.ReadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	sink, err := newAuditSink(ctx, scalingConfig, AssumeRoleConfigProvider(scalingConfig.AssumedRoleArn))
+	if err != nil {
+		return nil, err
+	}
+	if sink == nil {
+		return nil, errors.New("no audit sink configured")
+	}
+
+	query.AppName = scalingConfig.Name
+	return sink.Query(ctx, query)
+}
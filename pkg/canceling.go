@@ -0,0 +1,33 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCanceled fails the resources a canceled run didn't start scaling
+var ErrCanceled = errors.New("run canceled")
+
+// cancelGrace is how long the work in progress of a canceled run may take to finish
+const cancelGrace = 30 * time.Second
+
+// detach returns a context that outlives the cancellation or the deadline of ctx by up to cancelGrace, so a canceled
+// or timed out run lets the resources being scaled finish and still runs the post hooks and records its result.
+// Once the grace is over the context is canceled with the cause of ctx, which tells timeouts apart from cancellations.
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		timer := time.NewTimer(cancelGrace)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			cancel(context.Cause(ctx))
+		case <-detached.Done():
+		}
+	})
+	return detached, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
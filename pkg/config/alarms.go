@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+)
+
+// SuppressAlarmsConfig lists the CloudWatch alarms, by name or name prefix, whose actions are disabled while a region is scaled
+type SuppressAlarmsConfig struct {
+	Names    []string `yaml:"names,omitempty"`
+	Prefixes []string `yaml:"prefixes,omitempty"`
+}
+
+func (s *SuppressAlarmsConfig) validate() error {
+	if s == nil {
+		return nil
+	}
+
+	if len(s.Names) == 0 && len(s.Prefixes) == 0 {
+		return fmt.Errorf("config error: suppressAlarms must have names or prefixes")
+	}
+	for _, prefix := range s.Prefixes {
+		if prefix == "" {
+			return fmt.Errorf("config error: suppressAlarms prefixes must not be empty")
+		}
+	}
+	return nil
+}
+
+func (s *ScalingConfig) validateSuppressAlarms() error {
+	if err := s.SuppressAlarms.validate(); err != nil {
+		return err
+	}
+
+	regions := s.ScalingRegions
+	for _, profile := range s.Profiles {
+		regions = append(regions, profile.ScalingRegions...)
+	}
+
+	for _, region := range regions {
+		if err := region.SuppressAlarms.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeSuppressAlarms(value interface{}) (*SuppressAlarmsConfig, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("config error: invalid suppressAlarms: %w", err)
+	}
+
+	var suppressAlarms SuppressAlarmsConfig
+	if err := yaml.Unmarshal(data, &suppressAlarms); err != nil {
+		return nil, fmt.Errorf("config error: invalid suppressAlarms: %w", err)
+	}
+	return &suppressAlarms, nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+)
+
+const (
+	FileAuditSink     = "file"
+	S3AuditSink       = "s3"
+	DynamoDBAuditSink = "dynamodb"
+)
+
+type AuditConfig struct {
+	Sink      string `yaml:"sink"`
+	Path      string `yaml:"path,omitempty"`
+	Bucket    string `yaml:"bucket,omitempty"`
+	Prefix    string `yaml:"prefix,omitempty"`
+	TableName string `yaml:"tableName,omitempty"`
+	Region    string `yaml:"region,omitempty"`
+}
+
+func (a *AuditConfig) validate() error {
+	switch a.Sink {
+	case FileAuditSink:
+		if a.Path == "" {
+			return fmt.Errorf("config error: audit path is required for the file sink")
+		}
+	case S3AuditSink:
+		if a.Bucket == "" {
+			return fmt.Errorf("config error: audit bucket is required for the s3 sink")
+		}
+	case DynamoDBAuditSink:
+		if a.TableName == "" {
+			return fmt.Errorf("config error: audit tableName is required for the dynamodb sink")
+		}
+	default:
+		return fmt.Errorf("config error: audit sink %s is not supported", a.Sink)
+	}
+	return nil
+}
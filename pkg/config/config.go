@@ -1,14 +1,16 @@
 package config
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"github.com/mitchellh/mapstructure"
 	"gopkg.in/yaml.v3"
+	"log/slog"
 	"os"
 )
 
 func ReadConfig(configPath string) (*ScalingConfig, error) {
-	fmt.Println("Reading config from path: ", configPath)
+	slog.Debug("reading config", "path", configPath)
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("error reading config file: %w", err)
@@ -19,6 +21,62 @@ func ReadConfig(configPath string) (*ScalingConfig, error) {
 		return nil, fmt.Errorf("error decoding config file: %w", err)
 	}
 
+	if scalingConfig.Guardrails != nil {
+		if err := scalingConfig.Guardrails.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if scalingConfig.Lock != nil {
+		if err := scalingConfig.Lock.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if scalingConfig.Audit != nil {
+		if err := scalingConfig.Audit.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if scalingConfig.TTL != nil {
+		if err := scalingConfig.TTL.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, notification := range scalingConfig.Notifications {
+		if err := notification.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := scalingConfig.validateProfiles(); err != nil {
+		return nil, err
+	}
+
+	if err := scalingConfig.validateHooks(); err != nil {
+		return nil, err
+	}
+
+	if err := scalingConfig.validateTimeouts(); err != nil {
+		return nil, err
+	}
+
+	if err := scalingConfig.validateRollouts(); err != nil {
+		return nil, err
+	}
+
+	if err := scalingConfig.validateHealthGates(); err != nil {
+		return nil, err
+	}
+
+	if err := scalingConfig.validateSuppressAlarms(); err != nil {
+		return nil, err
+	}
+
+	scalingConfig.Hash = fmt.Sprintf("%x", sha256.Sum256(data))
+
 	return &scalingConfig, nil
 }
 
@@ -46,6 +104,14 @@ func convertMapToConfig(data map[string]interface{}) (interface{}, error) {
 		WeaklyTypedInput: true,
 		ErrorUnused:      true,
 		ErrorUnset:       true,
+		DecodeHook:       mapstructure.ComposeDecodeHookFunc(capacityTargetHook, hooksHook, healthGateHook, mapstructure.StringToTimeDurationHookFunc()),
+	}
+
+	// hooks, timeout and healthGate are the only optional fields of a service entry
+	for _, key := range []string{"hooks", "timeout", "healthGate"} {
+		if _, ok := data[key]; !ok {
+			data[key] = nil
+		}
 	}
 
 	switch s {
@@ -90,7 +156,6 @@ func convertMapToConfig(data map[string]interface{}) (interface{}, error) {
 		return dynamoDBServiceScalingConfig, nil
 
 	default:
-		fmt.Println("Service is not supported")
 		return nil, fmt.Errorf("config error: Service %s is not supported", s)
 	}
 }
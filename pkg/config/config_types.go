@@ -2,17 +2,37 @@ package config
 
 import (
 	"fmt"
+	"time"
 )
 
 type ScalingConfig struct {
-	Name           string          `yaml:"name"`
-	AssumedRoleArn string          `yaml:"assumedRoleArn"`
-	ScalingRegions []ScalingRegion `yaml:"scalingRegions"`
+	Name           string                `yaml:"name"`
+	AssumedRoleArn string                `yaml:"assumedRoleArn"`
+	Protected      bool                  `yaml:"protected,omitempty"`
+	ScalingRegions []ScalingRegion       `yaml:"scalingRegions"`
+	Guardrails     *Guardrails           `yaml:"guardrails,omitempty"`
+	Lock           *LockConfig           `yaml:"lock,omitempty"`
+	Audit          *AuditConfig          `yaml:"audit,omitempty"`
+	TTL            *TTLConfig            `yaml:"ttl,omitempty"`
+	State          *StateConfig          `yaml:"state,omitempty"`
+	Notifications  []NotificationConfig  `yaml:"notifications,omitempty"`
+	Hooks          *HooksConfig          `yaml:"hooks,omitempty"`
+	Timeout        time.Duration         `yaml:"timeout,omitempty"`
+	Rollout        *RolloutConfig        `yaml:"rollout,omitempty"`
+	SuppressAlarms *SuppressAlarmsConfig `yaml:"suppressAlarms,omitempty"`
+	Profiles       []Profile             `yaml:"profiles,omitempty"`
+	Schedules      []Schedule            `yaml:"schedules,omitempty"`
+
+	Hash string `yaml:"-"`
 }
 
 type ScalingRegion struct {
-	Region              string        `yaml:"region"`
-	ServiceScaleConfigs []interface{} `yaml:"serviceScaleConfigs"`
+	Region              string                `yaml:"region"`
+	ServiceScaleConfigs []interface{}         `yaml:"serviceScaleConfigs"`
+	Hooks               *HooksConfig          `yaml:"hooks,omitempty"`
+	Timeout             time.Duration         `yaml:"timeout,omitempty"`
+	HealthGate          *HealthGate           `yaml:"healthGate,omitempty"`
+	SuppressAlarms      *SuppressAlarmsConfig `yaml:"suppressAlarms,omitempty"`
 }
 
 func (s *ScalingRegion) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -49,6 +69,38 @@ func (s *ScalingRegion) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 				s.ServiceScaleConfigs = append(s.ServiceScaleConfigs, serviceConfig)
 			}
+
+		case "hooks":
+			hooks, err := decodeHooks(value)
+			if err != nil {
+				return err
+			}
+			s.Hooks = hooks
+
+		case "timeout":
+			timeout, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("config error: region timeout must be a duration, e.g. 10m")
+			}
+			duration, err := time.ParseDuration(timeout)
+			if err != nil {
+				return fmt.Errorf("config error: invalid region timeout %s: %w", timeout, err)
+			}
+			s.Timeout = duration
+
+		case "healthGate":
+			healthGate, err := decodeHealthGate(value)
+			if err != nil {
+				return err
+			}
+			s.HealthGate = healthGate
+
+		case "suppressAlarms":
+			suppressAlarms, err := decodeSuppressAlarms(value)
+			if err != nil {
+				return err
+			}
+			s.SuppressAlarms = suppressAlarms
 		}
 	}
 
@@ -57,60 +109,221 @@ func (s *ScalingRegion) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 type ServiceScalingConfig interface {
 	GetName() string
+	GetService() string
+	GetIdentifier() string
+	Targets() map[string]CapacityTarget
+	WithTargets(targets map[string]CapacityTarget) ServiceScalingConfig
+	GetHooks() *HooksConfig
+	// GetTimeout returns how long scaling the resource may take, 0 when it has no timeout of its own
+	GetTimeout() time.Duration
+	// GetHealthGate returns the gate checked after scaling the resource, nil when it has none
+	GetHealthGate() *HealthGate
 }
 
 type KinesisServiceScalingConfig struct {
-	Service           string `mapstructure:"service"`
-	StreamArn         string `mapstructure:"streamArn"`
-	DesiredShardCount int    `mapstructure:"desiredShardCount"`
+	Service           string         `mapstructure:"service" yaml:"service"`
+	StreamArn         string         `mapstructure:"streamArn" yaml:"streamArn"`
+	DesiredShardCount CapacityTarget `mapstructure:"desiredShardCount" yaml:"desiredShardCount"`
+	Hooks             *HooksConfig   `mapstructure:"hooks" yaml:"hooks,omitempty"`
+	Timeout           time.Duration  `mapstructure:"timeout" yaml:"timeout,omitempty"`
+	HealthGate        *HealthGate    `mapstructure:"healthGate" yaml:"healthGate,omitempty"`
 }
 
 func (k KinesisServiceScalingConfig) GetName() string {
 	return fmt.Sprintf("Kinesis scaling config for stream %s", k.StreamArn)
 }
 
+func (k KinesisServiceScalingConfig) GetService() string {
+	return k.Service
+}
+
+func (k KinesisServiceScalingConfig) GetIdentifier() string {
+	return k.StreamArn
+}
+
+func (k KinesisServiceScalingConfig) Targets() map[string]CapacityTarget {
+	return map[string]CapacityTarget{
+		"desiredShardCount": k.DesiredShardCount,
+	}
+}
+
+func (k KinesisServiceScalingConfig) WithTargets(targets map[string]CapacityTarget) ServiceScalingConfig {
+	withTarget(&k.DesiredShardCount, targets, "desiredShardCount")
+	return k
+}
+
+func (k KinesisServiceScalingConfig) GetHooks() *HooksConfig {
+	return k.Hooks
+}
+
+func (k KinesisServiceScalingConfig) GetTimeout() time.Duration {
+	return k.Timeout
+}
+
+func (k KinesisServiceScalingConfig) GetHealthGate() *HealthGate {
+	return k.HealthGate
+}
+
 type EC2ServiceScalingConfig struct {
-	Service      string `mapstructure:"service"`
-	AsgName      string `mapstructure:"asgName"`
-	MinCount     int    `mapstructure:"minCount"`
-	DesiredCount int    `mapstructure:"desiredCount"`
-	MaxCount     int    `mapstructure:"maxCount"`
+	Service      string         `mapstructure:"service" yaml:"service"`
+	AsgName      string         `mapstructure:"asgName" yaml:"asgName"`
+	MinCount     CapacityTarget `mapstructure:"minCount" yaml:"minCount"`
+	DesiredCount CapacityTarget `mapstructure:"desiredCount" yaml:"desiredCount"`
+	MaxCount     CapacityTarget `mapstructure:"maxCount" yaml:"maxCount"`
+	Hooks        *HooksConfig   `mapstructure:"hooks" yaml:"hooks,omitempty"`
+	Timeout      time.Duration  `mapstructure:"timeout" yaml:"timeout,omitempty"`
+	HealthGate   *HealthGate    `mapstructure:"healthGate" yaml:"healthGate,omitempty"`
 }
 
 func (e EC2ServiceScalingConfig) GetName() string {
 	return fmt.Sprintf("EC2 scaling config for ASG %s", e.AsgName)
 }
 
+func (e EC2ServiceScalingConfig) GetService() string {
+	return e.Service
+}
+
+func (e EC2ServiceScalingConfig) GetIdentifier() string {
+	return e.AsgName
+}
+
+func (e EC2ServiceScalingConfig) Targets() map[string]CapacityTarget {
+	return map[string]CapacityTarget{
+		"minCount":     e.MinCount,
+		"desiredCount": e.DesiredCount,
+		"maxCount":     e.MaxCount,
+	}
+}
+
+func (e EC2ServiceScalingConfig) WithTargets(targets map[string]CapacityTarget) ServiceScalingConfig {
+	withTarget(&e.MinCount, targets, "minCount")
+	withTarget(&e.DesiredCount, targets, "desiredCount")
+	withTarget(&e.MaxCount, targets, "maxCount")
+	return e
+}
+
+func (e EC2ServiceScalingConfig) GetHooks() *HooksConfig {
+	return e.Hooks
+}
+
+func (e EC2ServiceScalingConfig) GetTimeout() time.Duration {
+	return e.Timeout
+}
+
+func (e EC2ServiceScalingConfig) GetHealthGate() *HealthGate {
+	return e.HealthGate
+}
+
 type ElasticCacheServiceScalingConfig struct {
-	Service       string   `mapstructure:"service"`
-	ClusterId     string   `mapstructure:"clusterId"`
-	Engine        string   `mapstructure:"engine"`
-	NodeCount     int      `mapstructure:"nodeCount"`
-	NodesToDelete []string `mapstructure:"nodesToDelete"`
+	Service       string         `mapstructure:"service" yaml:"service"`
+	ClusterId     string         `mapstructure:"clusterId" yaml:"clusterId"`
+	Engine        string         `mapstructure:"engine" yaml:"engine"`
+	NodeCount     CapacityTarget `mapstructure:"nodeCount" yaml:"nodeCount"`
+	NodesToDelete []string       `mapstructure:"nodesToDelete" yaml:"nodesToDelete"`
+	Hooks         *HooksConfig   `mapstructure:"hooks" yaml:"hooks,omitempty"`
+	Timeout       time.Duration  `mapstructure:"timeout" yaml:"timeout,omitempty"`
+	HealthGate    *HealthGate    `mapstructure:"healthGate" yaml:"healthGate,omitempty"`
 }
 
 func (ec ElasticCacheServiceScalingConfig) GetName() string {
 	return fmt.Sprintf("ElasticCache scaling config for cluster %s", ec.ClusterId)
 }
 
+func (ec ElasticCacheServiceScalingConfig) GetService() string {
+	return ec.Service
+}
+
+func (ec ElasticCacheServiceScalingConfig) GetIdentifier() string {
+	return ec.ClusterId
+}
+
+func (ec ElasticCacheServiceScalingConfig) Targets() map[string]CapacityTarget {
+	return map[string]CapacityTarget{
+		"nodeCount": ec.NodeCount,
+	}
+}
+
+func (ec ElasticCacheServiceScalingConfig) WithTargets(targets map[string]CapacityTarget) ServiceScalingConfig {
+	withTarget(&ec.NodeCount, targets, "nodeCount")
+	return ec
+}
+
+func (ec ElasticCacheServiceScalingConfig) GetHooks() *HooksConfig {
+	return ec.Hooks
+}
+
+func (ec ElasticCacheServiceScalingConfig) GetTimeout() time.Duration {
+	return ec.Timeout
+}
+
+func (ec ElasticCacheServiceScalingConfig) GetHealthGate() *HealthGate {
+	return ec.HealthGate
+}
+
 type DynamoDBServiceScalingConfig struct {
-	Service   string `mapstructure:"service"`
-	TableName string `mapstructure:"tableName"`
-	IsIndex   bool   `mapstructure:"isIndex"`
-	RCU       RCU    `mapstructure:"rcu"`
-	WCU       WCU    `mapstructure:"wcu"`
+	Service    string        `mapstructure:"service" yaml:"service"`
+	TableName  string        `mapstructure:"tableName" yaml:"tableName"`
+	IsIndex    bool          `mapstructure:"isIndex" yaml:"isIndex"`
+	RCU        RCU           `mapstructure:"rcu" yaml:"rcu"`
+	WCU        WCU           `mapstructure:"wcu" yaml:"wcu"`
+	Hooks      *HooksConfig  `mapstructure:"hooks" yaml:"hooks,omitempty"`
+	Timeout    time.Duration `mapstructure:"timeout" yaml:"timeout,omitempty"`
+	HealthGate *HealthGate   `mapstructure:"healthGate" yaml:"healthGate,omitempty"`
 }
 
 func (d DynamoDBServiceScalingConfig) GetName() string {
 	return fmt.Sprintf("DynamoDB scaling config for table %s", d.TableName)
 }
 
+func (d DynamoDBServiceScalingConfig) GetService() string {
+	return d.Service
+}
+
+func (d DynamoDBServiceScalingConfig) GetIdentifier() string {
+	return d.TableName
+}
+
+func (d DynamoDBServiceScalingConfig) Targets() map[string]CapacityTarget {
+	return map[string]CapacityTarget{
+		"rcu.minProvisionedCapacity": d.RCU.MinProvisionedCapacity,
+		"rcu.maxProvisionedCapacity": d.RCU.MaxProvisionedCapacity,
+		"wcu.minProvisionedCapacity": d.WCU.MinProvisionedCapacity,
+		"wcu.maxProvisionedCapacity": d.WCU.MaxProvisionedCapacity,
+	}
+}
+
+func (d DynamoDBServiceScalingConfig) WithTargets(targets map[string]CapacityTarget) ServiceScalingConfig {
+	withTarget(&d.RCU.MinProvisionedCapacity, targets, "rcu.minProvisionedCapacity")
+	withTarget(&d.RCU.MaxProvisionedCapacity, targets, "rcu.maxProvisionedCapacity")
+	withTarget(&d.WCU.MinProvisionedCapacity, targets, "wcu.minProvisionedCapacity")
+	withTarget(&d.WCU.MaxProvisionedCapacity, targets, "wcu.maxProvisionedCapacity")
+	return d
+}
+
+func (d DynamoDBServiceScalingConfig) GetHooks() *HooksConfig {
+	return d.Hooks
+}
+
+func (d DynamoDBServiceScalingConfig) GetTimeout() time.Duration {
+	return d.Timeout
+}
+
+func (d DynamoDBServiceScalingConfig) GetHealthGate() *HealthGate {
+	return d.HealthGate
+}
+
 type RCU struct {
-	MinProvisionedCapacity int `mapstructure:"minProvisionedCapacity"`
-	MaxProvisionedCapacity int `mapstructure:"maxProvisionedCapacity"`
+	MinProvisionedCapacity CapacityTarget `mapstructure:"minProvisionedCapacity" yaml:"minProvisionedCapacity"`
+	MaxProvisionedCapacity CapacityTarget `mapstructure:"maxProvisionedCapacity" yaml:"maxProvisionedCapacity"`
 }
 
 type WCU struct {
-	MinProvisionedCapacity int `mapstructure:"minProvisionedCapacity"`
-	MaxProvisionedCapacity int `mapstructure:"maxProvisionedCapacity"`
+	MinProvisionedCapacity CapacityTarget `mapstructure:"minProvisionedCapacity" yaml:"minProvisionedCapacity"`
+	MaxProvisionedCapacity CapacityTarget `mapstructure:"maxProvisionedCapacity" yaml:"maxProvisionedCapacity"`
+}
+
+func withTarget(field *CapacityTarget, targets map[string]CapacityTarget, name string) {
+	if target, ok := targets[name]; ok {
+		*field = target
+	}
 }
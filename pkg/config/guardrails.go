@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+)
+
+type Guardrails struct {
+	MaxCapacity         map[string]int `yaml:"maxCapacity"`
+	MaxChangeFactor     float64        `yaml:"maxChangeFactor"`
+	MaxResourcesChanged int            `yaml:"maxResourcesChanged"`
+	CheckQuotas         bool           `yaml:"checkQuotas"`
+}
+
+func (g *Guardrails) validate() error {
+	for service, maxCapacity := range g.MaxCapacity {
+		switch service {
+		case "kinesis", "ec2", "elasticache", "dynamodb":
+		default:
+			return fmt.Errorf("config error: guardrail maxCapacity has unsupported service %s", service)
+		}
+		if maxCapacity <= 0 {
+			return fmt.Errorf("config error: guardrail maxCapacity for %s must be positive", service)
+		}
+	}
+
+	if g.MaxChangeFactor != 0 && g.MaxChangeFactor < 1 {
+		return fmt.Errorf("config error: guardrail maxChangeFactor must be at least 1")
+	}
+
+	if g.MaxResourcesChanged < 0 {
+		return fmt.Errorf("config error: guardrail maxResourcesChanged must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"time"
+)
+
+const (
+	GreaterThanThreshold          = "GreaterThanThreshold"
+	GreaterThanOrEqualToThreshold = "GreaterThanOrEqualToThreshold"
+	LessThanThreshold             = "LessThanThreshold"
+	LessThanOrEqualToThreshold    = "LessThanOrEqualToThreshold"
+)
+
+// HealthGate watches CloudWatch alarms and metrics for a soak period after scaling a region or a resource,
+// the scaling fails, and is rolled back with rollback, when an alarm goes into ALARM state or a metric breaches its threshold
+type HealthGate struct {
+	Alarms   []string          `yaml:"alarms,omitempty"`
+	Metrics  []MetricThreshold `yaml:"metrics,omitempty"`
+	Soak     time.Duration     `yaml:"soak,omitempty"`
+	Rollback bool              `yaml:"rollback,omitempty"`
+}
+
+// MetricThreshold breaches when the latest statistic of the metric over the period compares to the threshold
+type MetricThreshold struct {
+	Namespace  string            `yaml:"namespace"`
+	MetricName string            `yaml:"metricName"`
+	Dimensions map[string]string `yaml:"dimensions,omitempty"`
+	Statistic  string            `yaml:"statistic,omitempty"`
+	Period     time.Duration     `yaml:"period,omitempty"`
+	Threshold  float64           `yaml:"threshold"`
+	Comparison string            `yaml:"comparison,omitempty"`
+}
+
+func (m MetricThreshold) String() string {
+	return fmt.Sprintf("%s/%s", m.Namespace, m.MetricName)
+}
+
+func (h *HealthGate) validate() error {
+	if h == nil {
+		return nil
+	}
+
+	if len(h.Alarms) == 0 && len(h.Metrics) == 0 {
+		return fmt.Errorf("config error: healthGate must have alarms or metrics")
+	}
+	if h.Soak < 0 {
+		return fmt.Errorf("config error: healthGate soak must not be negative")
+	}
+
+	for _, metric := range h.Metrics {
+		if metric.Namespace == "" || metric.MetricName == "" {
+			return fmt.Errorf("config error: healthGate metrics must have a namespace and a metricName")
+		}
+		switch metric.Statistic {
+		case "", "Average", "Sum", "Minimum", "Maximum", "SampleCount":
+		default:
+			return fmt.Errorf("config error: statistic %s of metric %s is not supported, expected Average, Sum, Minimum, Maximum or SampleCount", metric.Statistic, metric)
+		}
+		if metric.Period < 0 || metric.Period%time.Minute != 0 {
+			return fmt.Errorf("config error: period of metric %s must be a multiple of 1m", metric)
+		}
+		switch metric.Comparison {
+		case "", GreaterThanThreshold, GreaterThanOrEqualToThreshold, LessThanThreshold, LessThanOrEqualToThreshold:
+		default:
+			return fmt.Errorf("config error: comparison %s of metric %s is not supported", metric.Comparison, metric)
+		}
+	}
+	return nil
+}
+
+func (s *ScalingConfig) validateHealthGates() error {
+	regions := append([]ScalingRegion{}, s.ScalingRegions...)
+	for _, profile := range s.Profiles {
+		regions = append(regions, profile.ScalingRegions...)
+	}
+
+	for _, region := range regions {
+		if err := region.HealthGate.validate(); err != nil {
+			return err
+		}
+		for _, serviceScaleConfig := range region.ServiceScaleConfigs {
+			if serviceConfig, ok := serviceScaleConfig.(ServiceScalingConfig); ok {
+				if err := serviceConfig.GetHealthGate().validate(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// rolling back a scale-up scales down, which elasticache clusters only support with nodesToDelete
+	scaleUpRegions := append([]ScalingRegion{}, s.ScalingRegions...)
+	for _, profile := range s.Profiles {
+		if profile.ScaleUp {
+			scaleUpRegions = append(scaleUpRegions, profile.ScalingRegions...)
+		}
+	}
+	for _, region := range scaleUpRegions {
+		for _, serviceScaleConfig := range region.ServiceScaleConfigs {
+			elasticCacheConfig, ok := serviceScaleConfig.(ElasticCacheServiceScalingConfig)
+			if !ok || !(region.HealthGate.rollsBack() || elasticCacheConfig.HealthGate.rollsBack()) {
+				continue
+			}
+			gated := []ScalingRegion{{Region: region.Region, ServiceScaleConfigs: []interface{}{elasticCacheConfig}}}
+			if clusters := s.UnrevertibleClusters(gated); len(clusters) > 0 {
+				return fmt.Errorf("config error: healthGate can't roll back elasticache cluster %s, no entry of it in the config lists nodesToDelete", clusters[0])
+			}
+		}
+	}
+	return nil
+}
+
+func (h *HealthGate) rollsBack() bool {
+	return h != nil && h.Rollback
+}
+
+func decodeHealthGate(value interface{}) (*HealthGate, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("config error: invalid healthGate: %w", err)
+	}
+
+	var healthGate HealthGate
+	if err := yaml.Unmarshal(data, &healthGate); err != nil {
+		return nil, fmt.Errorf("config error: invalid healthGate: %w", err)
+	}
+	return &healthGate, nil
+}
+
+// healthGateHook decodes the optional health gate of service entries, like hooksHook decodes their hooks
+func healthGateHook(_ reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(HealthGate{}) || data == nil {
+		return data, nil
+	}
+
+	healthGate, err := decodeHealthGate(data)
+	if err != nil {
+		return nil, err
+	}
+	return *healthGate, nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateHealthGatesRollback(t *testing.T) {
+	rollback := &HealthGate{Alarms: []string{"my-app-5xx"}, Rollback: true}
+	cluster := func(nodesToDelete []string, healthGate *HealthGate) ElasticCacheServiceScalingConfig {
+		return ElasticCacheServiceScalingConfig{
+			Service:       "elasticache",
+			ClusterId:     "my-cluster",
+			Engine:        "redis",
+			NodeCount:     AbsoluteTarget(4),
+			NodesToDelete: nodesToDelete,
+			HealthGate:    healthGate,
+		}
+	}
+	profile := func(scaleUp bool, regions ...ScalingRegion) Profile {
+		return Profile{Name: "peak", ScaleUp: scaleUp, ScalingRegions: regions}
+	}
+
+	tests := []struct {
+		name     string
+		profiles []Profile
+		wantErr  bool
+	}{
+		{
+			name:     "gate of the cluster rolls back a scale-up without nodesToDelete",
+			profiles: []Profile{profile(true, ScalingRegion{Region: "us-east-1", ServiceScaleConfigs: []interface{}{cluster(nil, rollback)}})},
+			wantErr:  true,
+		},
+		{
+			name:     "gate of the region rolls back a scale-up without nodesToDelete",
+			profiles: []Profile{profile(true, ScalingRegion{Region: "us-east-1", HealthGate: rollback, ServiceScaleConfigs: []interface{}{cluster(nil, nil)}})},
+			wantErr:  true,
+		},
+		{
+			name:     "scale-up with nodesToDelete",
+			profiles: []Profile{profile(true, ScalingRegion{Region: "us-east-1", ServiceScaleConfigs: []interface{}{cluster([]string{"0003"}, rollback)}})},
+		},
+		{
+			name: "nodesToDelete in another profile",
+			profiles: []Profile{
+				profile(true, ScalingRegion{Region: "us-east-1", ServiceScaleConfigs: []interface{}{cluster(nil, rollback)}}),
+				profile(false, ScalingRegion{Region: "us-east-1", ServiceScaleConfigs: []interface{}{cluster([]string{"0003"}, nil)}}),
+			},
+		},
+		{
+			name:     "rollback of a scale-down",
+			profiles: []Profile{profile(false, ScalingRegion{Region: "us-east-1", ServiceScaleConfigs: []interface{}{cluster(nil, rollback)}})},
+		},
+		{
+			name:     "gate without rollback",
+			profiles: []Profile{profile(true, ScalingRegion{Region: "us-east-1", ServiceScaleConfigs: []interface{}{cluster(nil, &HealthGate{Alarms: []string{"my-app-5xx"}})}})},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scalingConfig := &ScalingConfig{Profiles: test.profiles}
+
+			err := scalingConfig.validateHealthGates()
+
+			if test.wantErr != (err != nil) {
+				t.Fatalf("validateHealthGates() = %v, want error: %v", err, test.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "my-cluster") {
+				t.Errorf("validateHealthGates() = %v, want the cluster named", err)
+			}
+		})
+	}
+}
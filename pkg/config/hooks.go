@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"time"
+)
+
+const (
+	AbortOnFailure    = "abort"
+	ContinueOnFailure = "continue"
+)
+
+type HooksConfig struct {
+	Pre  []Hook `yaml:"pre,omitempty"`
+	Post []Hook `yaml:"post,omitempty"`
+}
+
+// Hook runs a shell command or calls a URL, a failing hook aborts the scaling it guards unless onFailure is continue
+type Hook struct {
+	Name      string            `yaml:"name,omitempty"`
+	Command   string            `yaml:"command,omitempty"`
+	URL       string            `yaml:"url,omitempty"`
+	Method    string            `yaml:"method,omitempty"`
+	Headers   map[string]string `yaml:"headers,omitempty"`
+	Timeout   time.Duration     `yaml:"timeout,omitempty"`
+	OnFailure string            `yaml:"onFailure,omitempty"`
+}
+
+func (h Hook) String() string {
+	switch {
+	case h.Name != "":
+		return h.Name
+	case h.Command != "":
+		return h.Command
+	default:
+		return h.URL
+	}
+}
+
+func (h Hook) Aborts() bool {
+	return h.OnFailure != ContinueOnFailure
+}
+
+func (h *HooksConfig) validate() error {
+	if h == nil {
+		return nil
+	}
+
+	for _, hook := range append(append([]Hook{}, h.Pre...), h.Post...) {
+		if (hook.Command == "") == (hook.URL == "") {
+			return fmt.Errorf("config error: hook %s must have either a command or a url", hook)
+		}
+		if hook.Timeout < 0 {
+			return fmt.Errorf("config error: timeout of hook %s must not be negative", hook)
+		}
+		switch hook.OnFailure {
+		case "", AbortOnFailure, ContinueOnFailure:
+		default:
+			return fmt.Errorf("config error: onFailure of hook %s must be %s or %s", hook, AbortOnFailure, ContinueOnFailure)
+		}
+	}
+	return nil
+}
+
+func (s *ScalingConfig) validateHooks() error {
+	if err := s.Hooks.validate(); err != nil {
+		return err
+	}
+
+	regions := s.ScalingRegions
+	for _, profile := range s.Profiles {
+		regions = append(regions, profile.ScalingRegions...)
+	}
+
+	for _, region := range regions {
+		if err := region.Hooks.validate(); err != nil {
+			return err
+		}
+		for _, serviceScaleConfig := range region.ServiceScaleConfigs {
+			if serviceConfig, ok := serviceScaleConfig.(ServiceScalingConfig); ok {
+				if err := serviceConfig.GetHooks().validate(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func decodeHooks(value interface{}) (*HooksConfig, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("config error: invalid hooks: %w", err)
+	}
+
+	var hooks HooksConfig
+	if err := yaml.Unmarshal(data, &hooks); err != nil {
+		return nil, fmt.Errorf("config error: invalid hooks: %w", err)
+	}
+	return &hooks, nil
+}
+
+// hooksHook decodes the optional hooks of service entries, the strict decoder of the entries
+// would otherwise require every field of every hook
+func hooksHook(_ reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(HooksConfig{}) || data == nil {
+		return data, nil
+	}
+
+	hooks, err := decodeHooks(data)
+	if err != nil {
+		return nil, err
+	}
+	return *hooks, nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	FileLockBackend     = "file"
+	DynamoDBLockBackend = "dynamodb"
+)
+
+type LockConfig struct {
+	Backend       string        `yaml:"backend"`
+	Path          string        `yaml:"path,omitempty"`
+	TableName     string        `yaml:"tableName,omitempty"`
+	Region        string        `yaml:"region,omitempty"`
+	LeaseDuration time.Duration `yaml:"leaseDuration,omitempty"`
+}
+
+func (l *LockConfig) validate() error {
+	switch l.Backend {
+	case "", FileLockBackend:
+	case DynamoDBLockBackend:
+		if l.TableName == "" {
+			return fmt.Errorf("config error: lock tableName is required for the dynamodb backend")
+		}
+	default:
+		return fmt.Errorf("config error: lock backend %s is not supported", l.Backend)
+	}
+
+	if l.LeaseDuration < 0 {
+		return fmt.Errorf("config error: lock leaseDuration must not be negative")
+	}
+	return nil
+}
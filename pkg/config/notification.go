@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"text/template"
+)
+
+const (
+	WebhookNotificationSink = "webhook"
+	SlackNotificationSink   = "slack"
+	SNSNotificationSink     = "sns"
+)
+
+const (
+	StartEvent   = "start"
+	FinishEvent  = "finish"
+	FailureEvent = "failure"
+)
+
+type NotificationConfig struct {
+	Sink     string            `yaml:"sink"`
+	URL      string            `yaml:"url,omitempty"`
+	Headers  map[string]string `yaml:"headers,omitempty"`
+	TopicArn string            `yaml:"topicArn,omitempty"`
+	Region   string            `yaml:"region,omitempty"`
+	Events   []string          `yaml:"events,omitempty"`
+	Template string            `yaml:"template,omitempty"`
+}
+
+// Notifies reports whether the sink subscribed to the event, sinks without events get all of them
+func (n NotificationConfig) Notifies(event string) bool {
+	if len(n.Events) == 0 {
+		return true
+	}
+	for _, e := range n.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (n NotificationConfig) validate() error {
+	switch n.Sink {
+	case WebhookNotificationSink, SlackNotificationSink:
+		if n.URL == "" {
+			return fmt.Errorf("config error: notification url is required for the %s sink", n.Sink)
+		}
+	case SNSNotificationSink:
+		if n.TopicArn == "" {
+			return fmt.Errorf("config error: notification topicArn is required for the sns sink")
+		}
+	default:
+		return fmt.Errorf("config error: notification sink %s is not supported", n.Sink)
+	}
+
+	for _, event := range n.Events {
+		switch event {
+		case StartEvent, FinishEvent, FailureEvent:
+		default:
+			return fmt.Errorf("config error: notification event %s is not supported, expected %s, %s or %s", event, StartEvent, FinishEvent, FailureEvent)
+		}
+	}
+
+	if n.Template != "" {
+		if _, err := template.New(n.Sink).Parse(n.Template); err != nil {
+			return fmt.Errorf("config error: invalid notification template: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"time"
+)
+
+const (
+	ScaleUpProfile   = "scale-up"
+	ScaleDownProfile = "scale-down"
+	RevertProfile    = "ttl-revert"
+)
+
+type Profile struct {
+	Name           string          `yaml:"name"`
+	ScaleUp        bool            `yaml:"scaleUp"`
+	ScalingRegions []ScalingRegion `yaml:"scalingRegions"`
+	Rollout        *RolloutConfig  `yaml:"rollout,omitempty"`
+}
+
+type Schedule struct {
+	Cron     string `yaml:"cron"`
+	Profile  string `yaml:"profile"`
+	Timezone string `yaml:"timezone,omitempty"`
+}
+
+func (s Schedule) Spec() string {
+	if s.Timezone == "" {
+		return s.Cron
+	}
+	return fmt.Sprintf("CRON_TZ=%s %s", s.Timezone, s.Cron)
+}
+
+func (s Schedule) Validate() error {
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("config error: invalid timezone %s for schedule %s: %w", s.Timezone, s.Cron, err)
+		}
+	}
+
+	if _, err := cron.ParseStandard(s.Spec()); err != nil {
+		return fmt.Errorf("config error: invalid cron expression %s: %w", s.Cron, err)
+	}
+	return nil
+}
+
+func (s *ScalingConfig) Profile(name string) (*Profile, error) {
+	switch name {
+	case ScaleUpProfile, ScaleDownProfile:
+		return &Profile{
+			Name:           name,
+			ScaleUp:        name == ScaleUpProfile,
+			ScalingRegions: s.ScalingRegions,
+		}, nil
+	}
+
+	for i := range s.Profiles {
+		if s.Profiles[i].Name == name {
+			return &s.Profiles[i], nil
+		}
+	}
+	return nil, fmt.Errorf("config error: profile %s is not defined", name)
+}
+
+func (s *ScalingConfig) DefaultRegion() string {
+	if len(s.ScalingRegions) > 0 {
+		return s.ScalingRegions[0].Region
+	}
+
+	for _, profile := range s.Profiles {
+		if len(profile.ScalingRegions) > 0 {
+			return profile.ScalingRegions[0].Region
+		}
+	}
+	return ""
+}
+
+// ResourceConfig finds the config of a resource in the top level scaling regions or any profile. ElastiCache clusters
+// are only scaled down with the nodes to delete of their config, so an entry listing them is preferred.
+func (s *ScalingConfig) ResourceConfig(region string, serviceName string, identifier string) (ServiceScalingConfig, bool) {
+	scalingRegions := append([]ScalingRegion{}, s.ScalingRegions...)
+	for _, profile := range s.Profiles {
+		scalingRegions = append(scalingRegions, profile.ScalingRegions...)
+	}
+
+	var found ServiceScalingConfig
+	for _, scalingRegion := range scalingRegions {
+		if scalingRegion.Region != region {
+			continue
+		}
+		for _, serviceScaleConfig := range scalingRegion.ServiceScaleConfigs {
+			serviceConfig, ok := serviceScaleConfig.(ServiceScalingConfig)
+			if !ok || serviceConfig.GetService() != serviceName || serviceConfig.GetIdentifier() != identifier {
+				continue
+			}
+			if elasticCacheConfig, ok := serviceConfig.(ElasticCacheServiceScalingConfig); !ok || len(elasticCacheConfig.NodesToDelete) > 0 {
+				return serviceConfig, true
+			}
+			if found == nil {
+				found = serviceConfig
+			}
+		}
+	}
+	return found, found != nil
+}
+
+// UnrevertibleClusters lists the ElastiCache clusters of the regions that can't be scaled back down after a scale-up,
+// as no entry of the config lists their nodes to delete
+func (s *ScalingConfig) UnrevertibleClusters(scalingRegions []ScalingRegion) []string {
+	var clusters []string
+	for _, scalingRegion := range scalingRegions {
+		for _, serviceScaleConfig := range scalingRegion.ServiceScaleConfigs {
+			elasticCacheConfig, ok := serviceScaleConfig.(ElasticCacheServiceScalingConfig)
+			if !ok {
+				continue
+			}
+			resourceConfig, _ := s.ResourceConfig(scalingRegion.Region, elasticCacheConfig.GetService(), elasticCacheConfig.GetIdentifier())
+			if revertConfig, ok := resourceConfig.(ElasticCacheServiceScalingConfig); !ok || len(revertConfig.NodesToDelete) == 0 {
+				clusters = append(clusters, fmt.Sprintf("%s (%s)", elasticCacheConfig.ClusterId, scalingRegion.Region))
+			}
+		}
+	}
+	return clusters
+}
+
+func (s *ScalingConfig) validateProfiles() error {
+	names := make(map[string]bool)
+	for _, profile := range s.Profiles {
+		switch profile.Name {
+		case "":
+			return fmt.Errorf("config error: profile name is missing")
+		case ScaleUpProfile, ScaleDownProfile, RevertProfile:
+			return fmt.Errorf("config error: profile name %s is reserved", profile.Name)
+		}
+
+		if names[profile.Name] {
+			return fmt.Errorf("config error: profile %s is defined more than once", profile.Name)
+		}
+		names[profile.Name] = true
+	}
+
+	for _, schedule := range s.Schedules {
+		if _, err := s.Profile(schedule.Profile); err != nil {
+			return err
+		}
+
+		if err := schedule.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	ParallelRollout   = "parallel"
+	SequentialRollout = "sequential"
+	WavesRollout      = "waves"
+)
+
+// DefaultRolloutWait is how long the resources scaled in a wave may take to be stable when the rollout sets no wait
+const DefaultRolloutWait = 10 * time.Minute
+
+// RolloutConfig sets how the regions of a run are scaled: all at once, one after the other or in waves.
+// The rollout halts when a wave fails, when the resources of a wave aren't stable within Wait or when the health
+// check after a wave fails.
+type RolloutConfig struct {
+	Strategy    string        `yaml:"strategy"`
+	Waves       [][]string    `yaml:"waves,omitempty"`
+	Wait        time.Duration `yaml:"wait,omitempty"`
+	HealthCheck *HealthCheck  `yaml:"healthCheck,omitempty"`
+}
+
+// HealthCheck passes when none of the CloudWatch alarms is in ALARM state and the url answers with a 2xx status
+type HealthCheck struct {
+	Alarms  []string          `yaml:"alarms,omitempty"`
+	URL     string            `yaml:"url,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Timeout time.Duration     `yaml:"timeout,omitempty"`
+}
+
+// StableWait returns how long the resources scaled in a wave may take to be stable
+func (r *RolloutConfig) StableWait() time.Duration {
+	if r.Wait > 0 {
+		return r.Wait
+	}
+	return DefaultRolloutWait
+}
+
+// ProfileRollout returns the rollout of the profile, profiles without one use the rollout of the app
+func (s *ScalingConfig) ProfileRollout(profile *Profile) *RolloutConfig {
+	if profile.Rollout != nil {
+		return profile.Rollout
+	}
+	return s.Rollout
+}
+
+// GroupRegions groups the regions in the waves they are scaled in, regions missing from the waves are scaled last
+func (r *RolloutConfig) GroupRegions(regions []string) [][]string {
+	strategy := ParallelRollout
+	if r != nil && r.Strategy != "" {
+		strategy = r.Strategy
+	}
+
+	switch strategy {
+	case SequentialRollout:
+		waves := make([][]string, 0, len(regions))
+		for _, region := range regions {
+			waves = append(waves, []string{region})
+		}
+		return waves
+	case WavesRollout:
+		included := make(map[string]bool)
+		for _, region := range regions {
+			included[region] = true
+		}
+
+		var waves [][]string
+		listed := make(map[string]bool)
+		for _, wave := range r.Waves {
+			var planned []string
+			for _, region := range wave {
+				listed[region] = true
+				if included[region] {
+					planned = append(planned, region)
+				}
+			}
+			if len(planned) > 0 {
+				waves = append(waves, planned)
+			}
+		}
+
+		var remaining []string
+		for _, region := range regions {
+			if !listed[region] {
+				remaining = append(remaining, region)
+			}
+		}
+		if len(remaining) > 0 {
+			waves = append(waves, remaining)
+		}
+		return waves
+	default:
+		if len(regions) == 0 {
+			return nil
+		}
+		return [][]string{regions}
+	}
+}
+
+func (r *RolloutConfig) validate() error {
+	if r == nil {
+		return nil
+	}
+
+	switch r.Strategy {
+	case "", ParallelRollout, SequentialRollout:
+		if len(r.Waves) > 0 {
+			return fmt.Errorf("config error: rollout waves are only supported by the %s strategy", WavesRollout)
+		}
+	case WavesRollout:
+		if len(r.Waves) == 0 {
+			return fmt.Errorf("config error: rollout waves are required by the %s strategy", WavesRollout)
+		}
+		regions := make(map[string]bool)
+		for _, wave := range r.Waves {
+			if len(wave) == 0 {
+				return fmt.Errorf("config error: rollout waves must not be empty")
+			}
+			for _, region := range wave {
+				if regions[region] {
+					return fmt.Errorf("config error: region %s is in more than one rollout wave", region)
+				}
+				regions[region] = true
+			}
+		}
+	default:
+		return fmt.Errorf("config error: rollout strategy %s is not supported, expected %s, %s or %s", r.Strategy, ParallelRollout, SequentialRollout, WavesRollout)
+	}
+
+	if r.Wait < 0 {
+		return fmt.Errorf("config error: rollout wait must not be negative")
+	}
+
+	if healthCheck := r.HealthCheck; healthCheck != nil {
+		if len(healthCheck.Alarms) == 0 && healthCheck.URL == "" {
+			return fmt.Errorf("config error: rollout healthCheck must have alarms or a url")
+		}
+		if healthCheck.Timeout < 0 {
+			return fmt.Errorf("config error: rollout healthCheck timeout must not be negative")
+		}
+	}
+	return nil
+}
+
+func (s *ScalingConfig) validateRollouts() error {
+	if err := s.Rollout.validate(); err != nil {
+		return err
+	}
+
+	for _, profile := range s.Profiles {
+		if err := profile.Rollout.validate(); err != nil {
+			return fmt.Errorf("%w in profile %s", err, profile.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGroupRegions(t *testing.T) {
+	tests := []struct {
+		name    string
+		rollout *RolloutConfig
+		regions []string
+		want    [][]string
+	}{
+		{name: "no rollout", regions: []string{"us-east-1", "eu-west-1"}, want: [][]string{{"us-east-1", "eu-west-1"}}},
+		{name: "no regions", rollout: &RolloutConfig{}, want: nil},
+		{name: "parallel", rollout: &RolloutConfig{Strategy: ParallelRollout}, regions: []string{"us-east-1", "eu-west-1"}, want: [][]string{{"us-east-1", "eu-west-1"}}},
+		{name: "sequential", rollout: &RolloutConfig{Strategy: SequentialRollout}, regions: []string{"us-east-1", "eu-west-1"}, want: [][]string{{"us-east-1"}, {"eu-west-1"}}},
+		{
+			name:    "waves",
+			rollout: &RolloutConfig{Strategy: WavesRollout, Waves: [][]string{{"us-west-2"}, {"us-east-1", "eu-west-1"}}},
+			regions: []string{"eu-west-1", "us-east-1", "us-west-2"},
+			want:    [][]string{{"us-west-2"}, {"us-east-1", "eu-west-1"}},
+		},
+		{
+			name:    "regions missing from the waves are scaled last",
+			rollout: &RolloutConfig{Strategy: WavesRollout, Waves: [][]string{{"us-west-2"}}},
+			regions: []string{"eu-west-1", "us-west-2", "us-east-1"},
+			want:    [][]string{{"us-west-2"}, {"eu-west-1", "us-east-1"}},
+		},
+		{
+			name:    "waves without planned regions are dropped",
+			rollout: &RolloutConfig{Strategy: WavesRollout, Waves: [][]string{{"ap-south-1"}, {"us-east-1", "eu-central-1"}}},
+			regions: []string{"us-east-1"},
+			want:    [][]string{{"us-east-1"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.rollout.GroupRegions(test.regions); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GroupRegions(%v) = %v, want %v", test.regions, got, test.want)
+			}
+		})
+	}
+}
+
+func TestStableWait(t *testing.T) {
+	tests := []struct {
+		name    string
+		rollout *RolloutConfig
+		want    time.Duration
+	}{
+		{name: "default", rollout: &RolloutConfig{}, want: DefaultRolloutWait},
+		{name: "wait", rollout: &RolloutConfig{Wait: 5 * time.Minute}, want: 5 * time.Minute},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.rollout.StableWait(); got != test.want {
+				t.Errorf("StableWait() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
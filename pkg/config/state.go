@@ -0,0 +1,6 @@
+package config
+
+// StateConfig sets where runs checkpoint the progress of their resources to be resumed
+type StateConfig struct {
+	Path string `yaml:"path,omitempty"`
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type targetOperation int
+
+const (
+	absoluteTarget targetOperation = iota
+	deltaTarget
+	percentTarget
+	factorTarget
+)
+
+// CapacityTarget is a capacity value that is either absolute ("5") or relative to the
+// current capacity of the resource ("+3", "-2", "+50%", "-25%", "x2"), optionally clamped
+// between a floor and a ceiling once resolved.
+type CapacityTarget struct {
+	Value   string
+	Floor   *int
+	Ceiling *int
+
+	operation targetOperation
+	amount    float64
+}
+
+func AbsoluteTarget(value int) CapacityTarget {
+	return CapacityTarget{
+		Value:     strconv.Itoa(value),
+		operation: absoluteTarget,
+		amount:    float64(value),
+	}
+}
+
+func ParseCapacityTarget(value string) (CapacityTarget, error) {
+	expression := strings.TrimSpace(value)
+	target := CapacityTarget{Value: expression}
+
+	var err error
+	switch {
+	case strings.HasPrefix(expression, "x"):
+		target.operation = factorTarget
+		target.amount, err = strconv.ParseFloat(strings.TrimPrefix(expression, "x"), 64)
+		if err == nil && target.amount < 0 {
+			err = fmt.Errorf("factor must not be negative")
+		}
+
+	case strings.HasSuffix(expression, "%"):
+		target.operation = percentTarget
+		target.amount, err = strconv.ParseFloat(strings.TrimSuffix(expression, "%"), 64)
+		if err == nil && !strings.HasPrefix(expression, "+") && !strings.HasPrefix(expression, "-") {
+			err = fmt.Errorf("percentage must start with + or -")
+		}
+
+	case strings.HasPrefix(expression, "+") || strings.HasPrefix(expression, "-"):
+		target.operation = deltaTarget
+		var delta int
+		delta, err = strconv.Atoi(expression)
+		target.amount = float64(delta)
+
+	default:
+		target.operation = absoluteTarget
+		var absolute int
+		absolute, err = strconv.Atoi(expression)
+		if err == nil && absolute < 0 {
+			err = fmt.Errorf("absolute value must not be negative")
+		}
+		target.amount = float64(absolute)
+	}
+
+	if err != nil {
+		return CapacityTarget{}, fmt.Errorf("config error: invalid capacity target %q: %w", value, err)
+	}
+	return target, nil
+}
+
+func (t CapacityTarget) IsRelative() bool {
+	return t.operation != absoluteTarget
+}
+
+func (t CapacityTarget) Resolve(current int) int {
+	var value int
+	switch t.operation {
+	case deltaTarget:
+		value = current + int(t.amount)
+	case percentTarget:
+		value = int(math.Round(float64(current) * (1 + t.amount/100)))
+	case factorTarget:
+		value = int(math.Round(float64(current) * t.amount))
+	default:
+		value = int(t.amount)
+	}
+
+	if t.Floor != nil && value < *t.Floor {
+		value = *t.Floor
+	}
+	if t.Ceiling != nil && value > *t.Ceiling {
+		value = *t.Ceiling
+	}
+	if value < 0 {
+		value = 0
+	}
+	return value
+}
+
+func (t CapacityTarget) String() string {
+	return t.Value
+}
+
+func (t CapacityTarget) MarshalYAML() (interface{}, error) {
+	if t.Floor == nil && t.Ceiling == nil {
+		if !t.IsRelative() {
+			return int(t.amount), nil
+		}
+		return t.Value, nil
+	}
+
+	clamped := map[string]interface{}{
+		"value": t.Value,
+	}
+	if t.Floor != nil {
+		clamped["floor"] = *t.Floor
+	}
+	if t.Ceiling != nil {
+		clamped["ceiling"] = *t.Ceiling
+	}
+	return clamped, nil
+}
+
+func decodeCapacityTarget(data interface{}) (CapacityTarget, error) {
+	switch v := data.(type) {
+	case int:
+		return ParseCapacityTarget(strconv.Itoa(v))
+	case float64:
+		if v != math.Trunc(v) {
+			return CapacityTarget{}, fmt.Errorf("config error: invalid capacity target %v: must be a whole number", v)
+		}
+		return ParseCapacityTarget(strconv.Itoa(int(v)))
+	case string:
+		return ParseCapacityTarget(v)
+	case map[string]interface{}:
+		return decodeClampedCapacityTarget(v)
+	default:
+		return CapacityTarget{}, fmt.Errorf("config error: invalid capacity target %v", data)
+	}
+}
+
+func decodeClampedCapacityTarget(data map[string]interface{}) (CapacityTarget, error) {
+	value, ok := data["value"]
+	if !ok {
+		return CapacityTarget{}, fmt.Errorf("config error: capacity target is missing the value field")
+	}
+
+	target, err := decodeCapacityTarget(value)
+	if err != nil {
+		return CapacityTarget{}, err
+	}
+
+	for key, v := range data {
+		switch key {
+		case "value":
+		case "floor":
+			floor, ok := v.(int)
+			if !ok {
+				return CapacityTarget{}, fmt.Errorf("config error: capacity target floor must be an integer")
+			}
+			target.Floor = &floor
+		case "ceiling":
+			ceiling, ok := v.(int)
+			if !ok {
+				return CapacityTarget{}, fmt.Errorf("config error: capacity target ceiling must be an integer")
+			}
+			target.Ceiling = &ceiling
+		default:
+			return CapacityTarget{}, fmt.Errorf("config error: unknown capacity target field %s", key)
+		}
+	}
+
+	if target.Floor != nil && target.Ceiling != nil && *target.Floor > *target.Ceiling {
+		return CapacityTarget{}, fmt.Errorf("config error: capacity target floor %d is greater than ceiling %d", *target.Floor, *target.Ceiling)
+	}
+	return target, nil
+}
+
+func capacityTargetHook(_ reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(CapacityTarget{}) {
+		return data, nil
+	}
+	return decodeCapacityTarget(data)
+}
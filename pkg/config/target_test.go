@@ -0,0 +1,135 @@
+package config
+
+import (
+	"testing"
+)
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func TestParseCapacityTarget(t *testing.T) {
+	tests := []struct {
+		value    string
+		relative bool
+		wantErr  bool
+	}{
+		{value: "5"},
+		{value: " 7 "},
+		{value: "0"},
+		{value: "+3", relative: true},
+		{value: "-2", relative: true},
+		{value: "+50%", relative: true},
+		{value: "-25%", relative: true},
+		{value: "+12.5%", relative: true},
+		{value: "x2", relative: true},
+		{value: "x0.5", relative: true},
+		{value: "-1", relative: true},
+		{value: "50%", wantErr: true},
+		{value: "x-2", wantErr: true},
+		{value: "xabc", wantErr: true},
+		{value: "+1.5", wantErr: true},
+		{value: "abc", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			target, err := ParseCapacityTarget(test.value)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("ParseCapacityTarget(%q) = %v, want an error", test.value, target)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCapacityTarget(%q) failed: %v", test.value, err)
+			}
+			if target.IsRelative() != test.relative {
+				t.Errorf("ParseCapacityTarget(%q).IsRelative() = %v, want %v", test.value, target.IsRelative(), test.relative)
+			}
+		})
+	}
+}
+
+func TestCapacityTargetResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		floor   *int
+		ceiling *int
+		current int
+		want    int
+	}{
+		{name: "absolute ignores current", value: "5", current: 100, want: 5},
+		{name: "delta up", value: "+3", current: 4, want: 7},
+		{name: "delta down", value: "-2", current: 4, want: 2},
+		{name: "delta below zero", value: "-10", current: 4, want: 0},
+		{name: "percent up", value: "+50%", current: 4, want: 6},
+		{name: "percent down", value: "-25%", current: 8, want: 6},
+		{name: "percent rounds half away from zero", value: "+50%", current: 3, want: 5},
+		{name: "percent rounds down", value: "+10%", current: 4, want: 4},
+		{name: "percent of zero", value: "+50%", current: 0, want: 0},
+		{name: "factor", value: "x2", current: 3, want: 6},
+		{name: "fractional factor rounds", value: "x0.5", current: 5, want: 3},
+		{name: "floor", value: "-50%", floor: intPtr(3), current: 4, want: 3},
+		{name: "ceiling", value: "x3", ceiling: intPtr(10), current: 4, want: 10},
+		{name: "within floor and ceiling", value: "+1", floor: intPtr(2), ceiling: intPtr(10), current: 4, want: 5},
+		{name: "negative floor", value: "-10", floor: intPtr(-5), current: 4, want: 0},
+		{name: "ceiling clamps absolute", value: "20", ceiling: intPtr(10), current: 4, want: 10},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			target, err := ParseCapacityTarget(test.value)
+			if err != nil {
+				t.Fatalf("ParseCapacityTarget(%q) failed: %v", test.value, err)
+			}
+			target.Floor = test.floor
+			target.Ceiling = test.ceiling
+
+			if got := target.Resolve(test.current); got != test.want {
+				t.Errorf("%q.Resolve(%d) = %d, want %d", test.value, test.current, got, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCapacityTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    interface{}
+		current int
+		want    int
+		wantErr bool
+	}{
+		{name: "int", data: 5, current: 1, want: 5},
+		{name: "whole float", data: 5.0, current: 1, want: 5},
+		{name: "fractional float", data: 5.5, wantErr: true},
+		{name: "string", data: "+2", current: 1, want: 3},
+		{name: "clamped", data: map[string]interface{}{"value": "x2", "floor": 2, "ceiling": 20}, current: 15, want: 20},
+		{name: "clamped without value", data: map[string]interface{}{"floor": 2}, wantErr: true},
+		{name: "clamped with unknown field", data: map[string]interface{}{"value": 1, "step": 2}, wantErr: true},
+		{name: "floor above ceiling", data: map[string]interface{}{"value": 1, "floor": 5, "ceiling": 2}, wantErr: true},
+		{name: "non integer floor", data: map[string]interface{}{"value": 1, "floor": "2"}, wantErr: true},
+		{name: "unsupported type", data: true, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			target, err := decodeCapacityTarget(test.data)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("decodeCapacityTarget(%v) = %v, want an error", test.data, target)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeCapacityTarget(%v) failed: %v", test.data, err)
+			}
+			if got := target.Resolve(test.current); got != test.want {
+				t.Errorf("decodeCapacityTarget(%v).Resolve(%d) = %d, want %d", test.data, test.current, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+)
+
+func (s *ScalingConfig) validateTimeouts() error {
+	if s.Timeout < 0 {
+		return fmt.Errorf("config error: app timeout must not be negative")
+	}
+
+	regions := s.ScalingRegions
+	for _, profile := range s.Profiles {
+		regions = append(regions, profile.ScalingRegions...)
+	}
+
+	for _, region := range regions {
+		if region.Timeout < 0 {
+			return fmt.Errorf("config error: timeout of region %s must not be negative", region.Region)
+		}
+		for _, serviceScaleConfig := range region.ServiceScaleConfigs {
+			if serviceConfig, ok := serviceScaleConfig.(ServiceScalingConfig); ok && serviceConfig.GetTimeout() < 0 {
+				return fmt.Errorf("config error: timeout of %s must not be negative", serviceConfig.GetName())
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+)
+
+const (
+	FileTTLStore     = "file"
+	DynamoDBTTLStore = "dynamodb"
+)
+
+type TTLConfig struct {
+	Store     string `yaml:"store"`
+	Path      string `yaml:"path,omitempty"`
+	TableName string `yaml:"tableName,omitempty"`
+	Region    string `yaml:"region,omitempty"`
+}
+
+func (t *TTLConfig) validate() error {
+	switch t.Store {
+	case "", FileTTLStore:
+	case DynamoDBTTLStore:
+		if t.TableName == "" {
+			return fmt.Errorf("config error: ttl tableName is required for the dynamodb store")
+		}
+	default:
+		return fmt.Errorf("config error: ttl store %s is not supported", t.Store)
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/robfig/cron/v3"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// schedules firing less often than this are not reconciled on startup
+	reconcileLookback = 35 * 24 * time.Hour
+
+	reapSchedule = "@every 1m"
+)
+
+type RunStatus struct {
+	Profile    string    `json:"profile"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Succeeded  bool      `json:"succeeded"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type ScheduledRun struct {
+	Profile string    `json:"profile"`
+	Cron    string    `json:"cron"`
+	Next    time.Time `json:"next"`
+}
+
+type Status struct {
+	AppName       string         `json:"appName"`
+	StartedAt     time.Time      `json:"startedAt"`
+	ActiveProfile string         `json:"activeProfile,omitempty"`
+	Running       bool           `json:"running"`
+	LastRun       *RunStatus     `json:"lastRun,omitempty"`
+	NextRuns      []ScheduledRun `json:"nextRuns"`
+}
+
+type Daemon struct {
+	ConfigPath  string
+	LockTimeout time.Duration
+
+	runMu     sync.Mutex
+	statusMu  sync.RWMutex
+	status    Status
+	cron      *cron.Cron
+	schedules map[cron.EntryID]config.Schedule
+	logger    *slog.Logger
+}
+
+func New(configPath string, lockTimeout time.Duration) *Daemon {
+	return &Daemon{
+		ConfigPath:  configPath,
+		LockTimeout: lockTimeout,
+	}
+}
+
+func (d *Daemon) Run(ctx context.Context) error {
+	scalingConfig, err := config.ReadConfig(d.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	if len(scalingConfig.Schedules) == 0 {
+		return errors.New("no schedules configured")
+	}
+
+	d.logger = slog.With(logging.AppKey, scalingConfig.Name)
+
+	cronScheduler := cron.New(cron.WithLocation(time.UTC))
+	schedules := make(map[cron.EntryID]config.Schedule)
+	for _, schedule := range scalingConfig.Schedules {
+		profile := schedule.Profile
+		id, err := cronScheduler.AddFunc(schedule.Spec(), func() { d.runProfile(ctx, profile) })
+		if err != nil {
+			return fmt.Errorf("error scheduling profile %s: %w", profile, err)
+		}
+		schedules[id] = schedule
+	}
+
+	if _, err := cronScheduler.AddFunc(reapSchedule, func() { d.reap(ctx) }); err != nil {
+		return fmt.Errorf("error scheduling reaper: %w", err)
+	}
+
+	d.statusMu.Lock()
+	d.status = Status{
+		AppName:   scalingConfig.Name,
+		StartedAt: time.Now(),
+	}
+	d.cron = cronScheduler
+	d.schedules = schedules
+	d.statusMu.Unlock()
+
+	if profile, firedAt, ok := activeProfile(scalingConfig.Schedules, time.Now().UTC()); ok {
+		d.logger.Info("reconciling to the active profile", logging.ProfileKey, profile, "scheduledAt", firedAt)
+		d.runProfile(ctx, profile)
+	}
+
+	d.cron.Start()
+	d.logger.Info("daemon started", "schedules", len(scalingConfig.Schedules))
+
+	<-ctx.Done()
+	<-d.cron.Stop().Done()
+	d.logger.Info("daemon stopped")
+	return nil
+}
+
+func (d *Daemon) runProfile(ctx context.Context, profile string) {
+	d.runMu.Lock()
+	defer d.runMu.Unlock()
+
+	startedAt := time.Now()
+	d.statusMu.Lock()
+	d.status.Running = true
+	d.statusMu.Unlock()
+
+	logger := d.logger.With(logging.ProfileKey, profile)
+	logger.Info("scaling to profile")
+	scalingResponse, err := pkg.ScaleApp(ctx, d.ConfigPath, pkg.ScaleOptions{
+		Profile:     profile,
+		LockTimeout: d.LockTimeout,
+	})
+
+	runStatus := d.recordRun(profile, startedAt, scalingResponse, err)
+
+	d.statusMu.Lock()
+	d.status.ActiveProfile = profile
+	d.statusMu.Unlock()
+
+	if runStatus.Succeeded {
+		logger.Info("scaling to profile completed successfully")
+	} else {
+		logger.Error("error scaling to profile", logging.ErrorKey, runStatus.Error)
+	}
+}
+
+func (d *Daemon) reap(ctx context.Context) {
+	d.runMu.Lock()
+	defer d.runMu.Unlock()
+
+	startedAt := time.Now()
+	scalingResponse, err := pkg.Reap(ctx, d.ConfigPath, pkg.ScaleOptions{
+		LockTimeout: d.LockTimeout,
+	})
+	if err == nil && scalingResponse == nil {
+		return
+	}
+
+	runStatus := d.recordRun(config.RevertProfile, startedAt, scalingResponse, err)
+	if runStatus.Succeeded {
+		d.logger.Info("reverted expired scale-ups")
+	} else {
+		d.logger.Error("error reverting expired scale-ups", logging.ErrorKey, runStatus.Error)
+	}
+}
+
+func (d *Daemon) recordRun(profile string, startedAt time.Time, scalingResponse *pkg.ScalingResponse, err error) *RunStatus {
+	runStatus := &RunStatus{
+		Profile:   profile,
+		StartedAt: startedAt,
+	}
+
+	switch {
+	case err != nil:
+		runStatus.Error = err.Error()
+	case scalingResponse.GuardrailsViolated:
+		runStatus.Error = "scaling aborted, guardrails violated"
+	case scalingResponse.Canceled:
+		runStatus.Error = fmt.Sprintf("scaling canceled after scaling %d resources", len(scalingResponse.ScaledResources))
+	case scalingResponse.ContainsFailedServices:
+		runStatus.Error = fmt.Sprintf("scaling completed with errors in %d regions", len(scalingResponse.RegionalFailedServices))
+	default:
+		runStatus.Succeeded = true
+	}
+	runStatus.FinishedAt = time.Now()
+
+	d.statusMu.Lock()
+	d.status.Running = false
+	d.status.LastRun = runStatus
+	d.statusMu.Unlock()
+
+	return runStatus
+}
+
+func (d *Daemon) Status() Status {
+	d.statusMu.RLock()
+	defer d.statusMu.RUnlock()
+
+	status := d.status
+	if d.cron == nil {
+		return status
+	}
+
+	status.NextRuns = make([]ScheduledRun, 0, len(d.schedules))
+	for id, schedule := range d.schedules {
+		status.NextRuns = append(status.NextRuns, ScheduledRun{
+			Profile: schedule.Profile,
+			Cron:    schedule.Spec(),
+			Next:    d.cron.Entry(id).Next,
+		})
+	}
+	sort.Slice(status.NextRuns, func(i, j int) bool {
+		return status.NextRuns[i].Next.Before(status.NextRuns[j].Next)
+	})
+	return status
+}
+
+func (d *Daemon) HealthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(d.Status()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// activeProfile returns the profile of the schedule that fired most recently before now, schedules without a timezone
+// are in the location of now
+func activeProfile(schedules []config.Schedule, now time.Time) (string, time.Time, bool) {
+	var profile string
+	var lastFired time.Time
+	for _, schedule := range schedules {
+		cronSchedule, err := cron.ParseStandard(schedule.Spec())
+		if err != nil {
+			continue
+		}
+
+		var fired time.Time
+		for next := cronSchedule.Next(now.Add(-reconcileLookback)); !next.IsZero() && !next.After(now); next = cronSchedule.Next(next) {
+			fired = next
+		}
+
+		if !fired.IsZero() && fired.After(lastFired) {
+			profile = schedule.Profile
+			lastFired = fired
+		}
+	}
+	return profile, lastFired, profile != ""
+}
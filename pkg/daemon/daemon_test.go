@@ -0,0 +1,106 @@
+package daemon
+
+import (
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"testing"
+	"time"
+)
+
+func TestActiveProfile(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2024, 1, 10, 12, 30, 0, 0, time.UTC)
+	weekdays := []config.Schedule{
+		{Cron: "0 8 * * 1-5", Profile: "up"},
+		{Cron: "0 20 * * 1-5", Profile: "down"},
+	}
+
+	tests := []struct {
+		name      string
+		schedules []config.Schedule
+		now       time.Time
+		want      string
+		wantFired time.Time
+		wantOk    bool
+	}{
+		{
+			name:      "latest schedule of the day",
+			schedules: weekdays,
+			now:       now,
+			want:      "up",
+			wantFired: time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC),
+			wantOk:    true,
+		},
+		{
+			name:      "schedule of the previous day",
+			schedules: weekdays,
+			now:       time.Date(2024, 1, 10, 7, 0, 0, 0, time.UTC),
+			want:      "down",
+			wantFired: time.Date(2024, 1, 9, 20, 0, 0, 0, time.UTC),
+			wantOk:    true,
+		},
+		{
+			name:      "weekend keeps the profile of friday",
+			schedules: weekdays,
+			now:       time.Date(2024, 1, 14, 12, 0, 0, 0, time.UTC),
+			want:      "down",
+			wantFired: time.Date(2024, 1, 12, 20, 0, 0, 0, time.UTC),
+			wantOk:    true,
+		},
+		{
+			name:      "schedule firing now",
+			schedules: weekdays,
+			now:       time.Date(2024, 1, 10, 20, 0, 0, 0, time.UTC),
+			want:      "down",
+			wantFired: time.Date(2024, 1, 10, 20, 0, 0, 0, time.UTC),
+			wantOk:    true,
+		},
+		{
+			name: "timezone",
+			schedules: []config.Schedule{
+				{Cron: "0 8 * * *", Profile: "up", Timezone: "America/New_York"},
+				{Cron: "0 12 * * *", Profile: "down"},
+			},
+			now:       now,
+			want:      "down",
+			wantFired: time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC),
+			wantOk:    true,
+		},
+		{
+			name: "timezone fires later",
+			schedules: []config.Schedule{
+				{Cron: "0 8 * * *", Profile: "up", Timezone: "America/New_York"},
+				{Cron: "0 12 * * *", Profile: "down"},
+			},
+			now:       time.Date(2024, 1, 10, 13, 30, 0, 0, time.UTC),
+			want:      "up",
+			wantFired: time.Date(2024, 1, 10, 13, 0, 0, 0, time.UTC),
+			wantOk:    true,
+		},
+		{
+			name:      "schedule older than the lookback",
+			schedules: []config.Schedule{{Cron: "0 0 1 1 *", Profile: "yearly"}},
+			now:       now.AddDate(0, 3, 0),
+		},
+		{
+			name:      "invalid schedule is ignored",
+			schedules: []config.Schedule{{Cron: "not a cron", Profile: "broken"}, {Cron: "0 8 * * *", Profile: "up"}},
+			now:       now,
+			want:      "up",
+			wantFired: time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC),
+			wantOk:    true,
+		},
+		{
+			name: "no schedules",
+			now:  now,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			profile, fired, ok := activeProfile(test.schedules, test.now)
+			if profile != test.want || ok != test.wantOk || !fired.Equal(test.wantFired) {
+				t.Errorf("activeProfile() = %q, %v, %v, want %q, %v, %v", profile, fired, ok, test.want, test.wantFired, test.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"strings"
+)
+
+type Filter struct {
+	NamePrefix string
+	Tags       map[string]string
+}
+
+func (f Filter) matchesName(name string) bool {
+	return strings.HasPrefix(name, f.NamePrefix)
+}
+
+func (f Filter) matchesTags(tags map[string]string) bool {
+	for key, value := range f.Tags {
+		if v, ok := tags[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (f Filter) hasTags() bool {
+	return len(f.Tags) > 0
+}
+
+func DiscoverRegion(ctx context.Context, awsCreds *aws.Config, region string, filter Filter) (*config.ScalingRegion, error) {
+	scalingRegion := &config.ScalingRegion{
+		Region: region,
+	}
+
+	ec2Configs, err := discoverEC2(ctx, service.NewAutoScalingClient(awsCreds), filter)
+	if err != nil {
+		return nil, fmt.Errorf("error discovering %s resources in region %s: %w", service.EC2, region, err)
+	}
+
+	kinesisConfigs, err := discoverKinesis(ctx, service.NewKinesisClient(awsCreds), filter)
+	if err != nil {
+		return nil, fmt.Errorf("error discovering %s resources in region %s: %w", service.Kinesis, region, err)
+	}
+
+	dynamoDBConfigs, err := discoverDynamoDB(ctx, service.NewApplicationAutoScalingClient(awsCreds), service.NewDynamoDBClient(awsCreds), filter)
+	if err != nil {
+		return nil, fmt.Errorf("error discovering %s resources in region %s: %w", service.DynamoDB, region, err)
+	}
+
+	elasticCacheConfigs, err := discoverElasticCache(ctx, service.NewElasticCacheClient(awsCreds), filter)
+	if err != nil {
+		return nil, fmt.Errorf("error discovering %s resources in region %s: %w", service.ElasticCache, region, err)
+	}
+
+	scalingRegion.ServiceScaleConfigs = append(scalingRegion.ServiceScaleConfigs, ec2Configs...)
+	scalingRegion.ServiceScaleConfigs = append(scalingRegion.ServiceScaleConfigs, kinesisConfigs...)
+	scalingRegion.ServiceScaleConfigs = append(scalingRegion.ServiceScaleConfigs, dynamoDBConfigs...)
+	scalingRegion.ServiceScaleConfigs = append(scalingRegion.ServiceScaleConfigs, elasticCacheConfigs...)
+
+	return scalingRegion, nil
+}
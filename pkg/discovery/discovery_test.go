@@ -0,0 +1,174 @@
+package discovery
+
+import (
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilterMatchesName(t *testing.T) {
+	tests := []struct {
+		name       string
+		namePrefix string
+		resource   string
+		want       bool
+	}{
+		{name: "no prefix", namePrefix: "", resource: "orders-asg", want: true},
+		{name: "matching prefix", namePrefix: "orders-", resource: "orders-asg", want: true},
+		{name: "prefix is the whole name", namePrefix: "orders-asg", resource: "orders-asg", want: true},
+		{name: "other prefix", namePrefix: "payments-", resource: "orders-asg", want: false},
+		{name: "prefix in the middle", namePrefix: "asg", resource: "orders-asg", want: false},
+		{name: "prefix is case sensitive", namePrefix: "Orders-", resource: "orders-asg", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter := Filter{NamePrefix: test.namePrefix}
+			if got := filter.matchesName(test.resource); got != test.want {
+				t.Errorf("matchesName(%q) = %v, want %v", test.resource, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFilterMatchesTags(t *testing.T) {
+	tests := []struct {
+		name       string
+		filterTags map[string]string
+		tags       map[string]string
+		want       bool
+	}{
+		{name: "no filter tags", tags: map[string]string{"team": "orders"}, want: true},
+		{name: "no filter tags and untagged", want: true},
+		{name: "matching tag", filterTags: map[string]string{"team": "orders"}, tags: map[string]string{"team": "orders", "env": "prod"}, want: true},
+		{name: "all tags match", filterTags: map[string]string{"team": "orders", "env": "prod"}, tags: map[string]string{"team": "orders", "env": "prod"}, want: true},
+		{name: "one tag differs", filterTags: map[string]string{"team": "orders", "env": "prod"}, tags: map[string]string{"team": "orders", "env": "dev"}, want: false},
+		{name: "tag missing", filterTags: map[string]string{"team": "orders"}, tags: map[string]string{"env": "prod"}, want: false},
+		{name: "untagged", filterTags: map[string]string{"team": "orders"}, want: false},
+		{name: "empty value must match", filterTags: map[string]string{"team": ""}, tags: map[string]string{"team": "orders"}, want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter := Filter{Tags: test.filterTags}
+			if got := filter.matchesTags(test.tags); got != test.want {
+				t.Errorf("matchesTags(%v) = %v, want %v", test.tags, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTableNameFromResourceId(t *testing.T) {
+	tests := []struct {
+		name       string
+		resourceId string
+		want       string
+	}{
+		{name: "table", resourceId: "table/orders", want: "orders"},
+		{name: "index", resourceId: "table/orders/index/by-customer", want: "orders"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := tableNameFromResourceId(test.resourceId); got != test.want {
+				t.Errorf("tableNameFromResourceId(%q) = %q, want %q", test.resourceId, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGeneratedConfig(t *testing.T) {
+	scalingConfig := config.ScalingConfig{
+		Name:           "orders",
+		AssumedRoleArn: "arn:aws:iam::123456789012:role/orders",
+		ScalingRegions: []config.ScalingRegion{
+			{
+				Region: "us-east-1",
+				ServiceScaleConfigs: []interface{}{
+					config.EC2ServiceScalingConfig{
+						Service:      string(service.EC2),
+						AsgName:      "orders-asg",
+						MinCount:     config.AbsoluteTarget(1),
+						DesiredCount: config.AbsoluteTarget(2),
+						MaxCount:     config.AbsoluteTarget(4),
+					},
+					config.KinesisServiceScalingConfig{
+						Service:           string(service.Kinesis),
+						StreamArn:         "arn:aws:kinesis:us-east-1:123456789012:stream/orders",
+						DesiredShardCount: config.AbsoluteTarget(4),
+					},
+					config.DynamoDBServiceScalingConfig{
+						Service:   string(service.DynamoDB),
+						TableName: "table/orders/index/by-customer",
+						IsIndex:   true,
+						RCU:       config.RCU{MinProvisionedCapacity: config.AbsoluteTarget(5), MaxProvisionedCapacity: config.AbsoluteTarget(50)},
+						WCU:       config.WCU{MinProvisionedCapacity: config.AbsoluteTarget(5), MaxProvisionedCapacity: config.AbsoluteTarget(20)},
+					},
+					config.ElasticCacheServiceScalingConfig{
+						Service:       string(service.ElasticCache),
+						ClusterId:     "orders-cache",
+						Engine:        string(service.Redis),
+						NodeCount:     config.AbsoluteTarget(3),
+						NodesToDelete: []string{},
+					},
+				},
+			},
+		},
+	}
+
+	data, err := yaml.Marshal(&scalingConfig)
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+
+	want := `name: orders
+assumedRoleArn: arn:aws:iam::123456789012:role/orders
+scalingRegions:
+    - region: us-east-1
+      serviceScaleConfigs:
+        - service: ec2
+          asgName: orders-asg
+          minCount: 1
+          desiredCount: 2
+          maxCount: 4
+        - service: kinesis
+          streamArn: arn:aws:kinesis:us-east-1:123456789012:stream/orders
+          desiredShardCount: 4
+        - service: dynamodb
+          tableName: table/orders/index/by-customer
+          isIndex: true
+          rcu:
+            minProvisionedCapacity: 5
+            maxProvisionedCapacity: 50
+          wcu:
+            minProvisionedCapacity: 5
+            maxProvisionedCapacity: 20
+        - service: elasticache
+          clusterId: orders-cache
+          engine: redis
+          nodeCount: 3
+          nodesToDelete: []
+`
+	if string(data) != want {
+		t.Errorf("generated config = \n%s\nwant\n%s", data, want)
+	}
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	readConfig, err := config.ReadConfig(configPath)
+	if err != nil {
+		t.Fatalf("ReadConfig() error = %v", err)
+	}
+	if readConfig.Name != scalingConfig.Name {
+		t.Errorf("ReadConfig().Name = %q, want %q", readConfig.Name, scalingConfig.Name)
+	}
+	if got := len(readConfig.ScalingRegions[0].ServiceScaleConfigs); got != 4 {
+		t.Errorf("len(ReadConfig().ServiceScaleConfigs) = %d, want 4", got)
+	}
+}
@@ -0,0 +1,126 @@
+package discovery
+
+import (
+	"context"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"sort"
+	"strings"
+)
+
+type dynamoDBTarget struct {
+	rcu *config.RCU
+	wcu *config.WCU
+}
+
+func discoverDynamoDB(ctx context.Context, client *applicationautoscaling.Client, dynamoDBClient *dynamodb.Client, filter Filter) ([]interface{}, error) {
+	targets := make(map[string]*dynamoDBTarget)
+	input := applicationautoscaling.DescribeScalableTargetsInput{
+		ServiceNamespace: service.DynamodbServiceNamespace,
+	}
+
+	paginator := applicationautoscaling.NewDescribeScalableTargetsPaginator(client, &input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, scalableTarget := range page.ScalableTargets {
+			resourceId := aws.ToString(scalableTarget.ResourceId)
+			target, ok := targets[resourceId]
+			if !ok {
+				target = &dynamoDBTarget{}
+				targets[resourceId] = target
+			}
+
+			minCapacity := int(aws.ToInt32(scalableTarget.MinCapacity))
+			maxCapacity := int(aws.ToInt32(scalableTarget.MaxCapacity))
+			switch scalableTarget.ScalableDimension {
+			case types.ScalableDimensionDynamoDBTableReadCapacityUnits, types.ScalableDimensionDynamoDBIndexReadCapacityUnits:
+				target.rcu = &config.RCU{MinProvisionedCapacity: config.AbsoluteTarget(minCapacity), MaxProvisionedCapacity: config.AbsoluteTarget(maxCapacity)}
+			case types.ScalableDimensionDynamoDBTableWriteCapacityUnits, types.ScalableDimensionDynamoDBIndexWriteCapacityUnits:
+				target.wcu = &config.WCU{MinProvisionedCapacity: config.AbsoluteTarget(minCapacity), MaxProvisionedCapacity: config.AbsoluteTarget(maxCapacity)}
+			}
+		}
+	}
+
+	resourceIds := make([]string, 0, len(targets))
+	for resourceId := range targets {
+		resourceIds = append(resourceIds, resourceId)
+	}
+	sort.Strings(resourceIds)
+
+	var configs []interface{}
+	for _, resourceId := range resourceIds {
+		tableName := tableNameFromResourceId(resourceId)
+		if !filter.matchesName(tableName) {
+			continue
+		}
+
+		target := targets[resourceId]
+		if target.rcu == nil || target.wcu == nil {
+			logging.FromContext(ctx).Warn("skipping table, both read and write capacity must be registered for scaling", logging.IdentifierKey, resourceId)
+			continue
+		}
+
+		if filter.hasTags() {
+			tags, err := listTableTags(ctx, dynamoDBClient, tableName)
+			if err != nil {
+				return nil, err
+			}
+			if !filter.matchesTags(tags) {
+				continue
+			}
+		}
+
+		configs = append(configs, config.DynamoDBServiceScalingConfig{
+			Service:   string(service.DynamoDB),
+			TableName: resourceId,
+			IsIndex:   strings.Contains(resourceId, "/index/"),
+			RCU:       *target.rcu,
+			WCU:       *target.wcu,
+		})
+	}
+
+	return configs, nil
+}
+
+func tableNameFromResourceId(resourceId string) string {
+	return strings.SplitN(strings.TrimPrefix(resourceId, "table/"), "/", 2)[0]
+}
+
+func listTableTags(ctx context.Context, client *dynamodb.Client, tableName string) (map[string]string, error) {
+	table, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: &tableName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string)
+	input := dynamodb.ListTagsOfResourceInput{
+		ResourceArn: table.Table.TableArn,
+	}
+
+	for {
+		output, err := client.ListTagsOfResource(ctx, &input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tag := range output.Tags {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+
+		if output.NextToken == nil {
+			return tags, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
@@ -0,0 +1,47 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+)
+
+func discoverEC2(ctx context.Context, client *autoscaling.Client, filter Filter) ([]interface{}, error) {
+	input := autoscaling.DescribeAutoScalingGroupsInput{}
+	for key, value := range filter.Tags {
+		input.Filters = append(input.Filters, types.Filter{
+			Name:   aws.String(fmt.Sprintf("tag:%s", key)),
+			Values: []string{value},
+		})
+	}
+
+	var configs []interface{}
+	paginator := autoscaling.NewDescribeAutoScalingGroupsPaginator(client, &input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, group := range page.AutoScalingGroups {
+			asgName := aws.ToString(group.AutoScalingGroupName)
+			if !filter.matchesName(asgName) {
+				continue
+			}
+
+			configs = append(configs, config.EC2ServiceScalingConfig{
+				Service:      string(service.EC2),
+				AsgName:      asgName,
+				MinCount:     config.AbsoluteTarget(int(aws.ToInt32(group.MinSize))),
+				DesiredCount: config.AbsoluteTarget(int(aws.ToInt32(group.DesiredCapacity))),
+				MaxCount:     config.AbsoluteTarget(int(aws.ToInt32(group.MaxSize))),
+			})
+		}
+	}
+
+	return configs, nil
+}
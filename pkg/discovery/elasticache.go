@@ -0,0 +1,115 @@
+package discovery
+
+import (
+	"context"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+)
+
+func discoverElasticCache(ctx context.Context, client *elasticache.Client, filter Filter) ([]interface{}, error) {
+	redisConfigs, err := discoverRedis(ctx, client, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	memcachedConfigs, err := discoverMemcached(ctx, client, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(redisConfigs, memcachedConfigs...), nil
+}
+
+func discoverRedis(ctx context.Context, client *elasticache.Client, filter Filter) ([]interface{}, error) {
+	var configs []interface{}
+	paginator := elasticache.NewDescribeReplicationGroupsPaginator(client, &elasticache.DescribeReplicationGroupsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, replicationGroup := range page.ReplicationGroups {
+			clusterId := aws.ToString(replicationGroup.ReplicationGroupId)
+			if !filter.matchesName(clusterId) {
+				continue
+			}
+
+			matches, err := matchesElasticCacheTags(ctx, client, replicationGroup.ARN, filter)
+			if err != nil {
+				return nil, err
+			}
+			if !matches {
+				continue
+			}
+
+			configs = append(configs, config.ElasticCacheServiceScalingConfig{
+				Service:       string(service.ElasticCache),
+				ClusterId:     clusterId,
+				Engine:        string(service.Redis),
+				NodeCount:     config.AbsoluteTarget(len(replicationGroup.NodeGroups)),
+				NodesToDelete: []string{},
+			})
+		}
+	}
+
+	return configs, nil
+}
+
+func discoverMemcached(ctx context.Context, client *elasticache.Client, filter Filter) ([]interface{}, error) {
+	var configs []interface{}
+	paginator := elasticache.NewDescribeCacheClustersPaginator(client, &elasticache.DescribeCacheClustersInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, cacheCluster := range page.CacheClusters {
+			clusterId := aws.ToString(cacheCluster.CacheClusterId)
+			if aws.ToString(cacheCluster.Engine) != string(service.Memcached) || !filter.matchesName(clusterId) {
+				continue
+			}
+
+			matches, err := matchesElasticCacheTags(ctx, client, cacheCluster.ARN, filter)
+			if err != nil {
+				return nil, err
+			}
+			if !matches {
+				continue
+			}
+
+			configs = append(configs, config.ElasticCacheServiceScalingConfig{
+				Service:       string(service.ElasticCache),
+				ClusterId:     clusterId,
+				Engine:        string(service.Memcached),
+				NodeCount:     config.AbsoluteTarget(int(aws.ToInt32(cacheCluster.NumCacheNodes))),
+				NodesToDelete: []string{},
+			})
+		}
+	}
+
+	return configs, nil
+}
+
+func matchesElasticCacheTags(ctx context.Context, client *elasticache.Client, arn *string, filter Filter) (bool, error) {
+	if !filter.hasTags() {
+		return true, nil
+	}
+
+	output, err := client.ListTagsForResource(ctx, &elasticache.ListTagsForResourceInput{
+		ResourceName: arn,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	tags := make(map[string]string)
+	for _, tag := range output.TagList {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+
+	return filter.matchesTags(tags), nil
+}
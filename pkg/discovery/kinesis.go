@@ -0,0 +1,74 @@
+package discovery
+
+import (
+	"context"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+)
+
+func discoverKinesis(ctx context.Context, client *kinesis.Client, filter Filter) ([]interface{}, error) {
+	var configs []interface{}
+	paginator := kinesis.NewListStreamsPaginator(client, &kinesis.ListStreamsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, stream := range page.StreamSummaries {
+			if !filter.matchesName(aws.ToString(stream.StreamName)) {
+				continue
+			}
+
+			if filter.hasTags() {
+				tags, err := listStreamTags(ctx, client, stream.StreamARN)
+				if err != nil {
+					return nil, err
+				}
+				if !filter.matchesTags(tags) {
+					continue
+				}
+			}
+
+			summary, err := client.DescribeStreamSummary(ctx, &kinesis.DescribeStreamSummaryInput{
+				StreamARN: stream.StreamARN,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			configs = append(configs, config.KinesisServiceScalingConfig{
+				Service:           string(service.Kinesis),
+				StreamArn:         aws.ToString(stream.StreamARN),
+				DesiredShardCount: config.AbsoluteTarget(int(aws.ToInt32(summary.StreamDescriptionSummary.OpenShardCount))),
+			})
+		}
+	}
+
+	return configs, nil
+}
+
+func listStreamTags(ctx context.Context, client *kinesis.Client, streamArn *string) (map[string]string, error) {
+	tags := make(map[string]string)
+	input := kinesis.ListTagsForStreamInput{
+		StreamARN: streamArn,
+	}
+
+	for {
+		output, err := client.ListTagsForStream(ctx, &input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tag := range output.Tags {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+
+		if !aws.ToBool(output.HasMoreTags) || len(output.Tags) == 0 {
+			return tags, nil
+		}
+		input.ExclusiveStartTagKey = output.Tags[len(output.Tags)-1].Key
+	}
+}
@@ -0,0 +1,152 @@
+package pkg
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"regexp"
+)
+
+var actionNameReplacer = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+type ExportOptions struct {
+	Profile  string
+	Cron     string
+	Timezone string
+	DryRun   bool
+}
+
+// ExportSchedule creates native scheduled actions for the EC2 and DynamoDB resources of the profiles of the
+// schedules in the config, or of a single profile and cron expression
+func ExportSchedule(configPath string, options ExportOptions) ([]*service.ScheduledAction, []*service.ScalingError, error) {
+	scalingConfig, err := config.ReadConfig(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schedules := scalingConfig.Schedules
+	if options.Profile != "" || options.Cron != "" {
+		if options.Profile == "" || options.Cron == "" {
+			return nil, nil, errors.New("both a profile and a cron expression are required")
+		}
+
+		schedule := config.Schedule{Cron: options.Cron, Profile: options.Profile, Timezone: options.Timezone}
+		if err := schedule.Validate(); err != nil {
+			return nil, nil, err
+		}
+		schedules = []config.Schedule{schedule}
+	}
+	if len(schedules) == 0 {
+		return nil, nil, errors.New("no schedules configured")
+	}
+
+	if !options.DryRun && scalingConfig.AssumedRoleArn == "" {
+		return nil, nil, errors.New("no assumed role ARN provided")
+	}
+
+	ctx := context.Background()
+	var actions []*service.ScheduledAction
+	var failedServices []*service.ScalingError
+	for _, schedule := range schedules {
+		profile, err := scalingConfig.Profile(schedule.Profile)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		name := scheduledActionName(scalingConfig.Name, schedule)
+		for _, scalingRegion := range profile.ScalingRegions {
+			regionActions, regionErrors := scheduledActions(ctx, scalingConfig, scalingRegion, name, schedule, options.DryRun)
+			actions = append(actions, regionActions...)
+			failedServices = append(failedServices, regionErrors...)
+		}
+	}
+
+	if options.DryRun {
+		return actions, failedServices, nil
+	}
+
+	var putActions []*service.ScheduledAction
+	for _, action := range actions {
+		if errs := action.Put(ctx); len(errs) > 0 {
+			failedServices = append(failedServices, errs...)
+			continue
+		}
+		putActions = append(putActions, action)
+	}
+	return putActions, failedServices, nil
+}
+
+func scheduledActions(ctx context.Context, scalingConfig *config.ScalingConfig, scalingRegion config.ScalingRegion, name string, schedule config.Schedule, dryRun bool) ([]*service.ScheduledAction, []*service.ScalingError) {
+	var autoScalingClient *autoscaling.Client
+	var appAutoScalingClient *applicationautoscaling.Client
+	if !dryRun {
+		awsCreds, err := service.NewConfig(ctx, scalingRegion.Region, scalingConfig.AssumedRoleArn)
+		if err != nil {
+			return nil, []*service.ScalingError{{
+				Region:       scalingRegion.Region,
+				ServiceName:  "sts",
+				IdentifierId: scalingConfig.AssumedRoleArn,
+				Err:          err,
+			}}
+		}
+		autoScalingClient = service.NewAutoScalingClient(awsCreds)
+		appAutoScalingClient = service.NewApplicationAutoScalingClient(awsCreds)
+	}
+
+	var actions []*service.ScheduledAction
+	var failedServices []*service.ScalingError
+	for _, serviceScaleConfig := range scalingRegion.ServiceScaleConfigs {
+		var action *service.ScheduledAction
+		var err *service.ScalingError
+
+		switch serviceConfig := serviceScaleConfig.(type) {
+		case config.EC2ServiceScalingConfig:
+			ec2 := service.EC2Service{
+				Region: scalingRegion.Region,
+				Client: autoScalingClient,
+			}
+			action, err = ec2.ScheduledAction(serviceConfig, name, schedule)
+
+		case config.DynamoDBServiceScalingConfig:
+			ds := service.DynamoDBService{
+				Region: scalingRegion.Region,
+				Client: appAutoScalingClient,
+			}
+			action, err = ds.ScheduledAction(serviceConfig, name, schedule)
+
+		case config.ServiceScalingConfig:
+			err = &service.ScalingError{
+				ServiceName:  serviceConfig.GetService(),
+				IdentifierId: serviceConfig.GetIdentifier(),
+				Err:          fmt.Errorf("%w for %s", service.ErrScheduledActionUnsupported, serviceConfig.GetService()),
+			}
+
+		default:
+			err = &service.ScalingError{
+				ServiceName:  "Unknown",
+				IdentifierId: "Unknown",
+				Err:          fmt.Errorf("unknown service"),
+			}
+		}
+
+		if err != nil {
+			err.Region = scalingRegion.Region
+			failedServices = append(failedServices, err)
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions, failedServices
+}
+
+// scheduledActionName is stable for a schedule, so exporting it again updates the existing actions
+func scheduledActionName(appName string, schedule config.Schedule) string {
+	hash := sha256.Sum256([]byte(schedule.Spec()))
+	name := fmt.Sprintf("aws-infra-scaler-%s-%s-%x", appName, schedule.Profile, hash[:4])
+	return actionNameReplacer.ReplaceAllString(name, "-")
+}
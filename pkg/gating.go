@@ -0,0 +1,160 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/health"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/state"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/ttl"
+)
+
+// ErrHealthGate fails the resources and regions whose health gate failed after scaling them
+var ErrHealthGate = errors.New("health gate failed")
+
+// ErrRolledBack fails the resources scaled back to their capacity before the run
+var ErrRolledBack = errors.New("rolled back")
+
+// healthGateService names the failures of region health gates, which aren't tied to a resource
+const healthGateService = "health-gate"
+
+// checkGate watches the alarms and metrics of the gate in the region for its soak period
+func (s *Scaler) checkGate(ctx context.Context, gate *config.HealthGate, region string) error {
+	awsCreds, err := s.awsConfig(ctx, region)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrHealthGate, err)
+	}
+	client := service.NewCloudWatchClient(awsCreds)
+
+	logging.FromContext(ctx).Info("checking health gate", "soak", gate.Soak)
+	err = health.Soak(ctx, gate.Soak, func(ctx context.Context) error {
+		if len(gate.Alarms) > 0 {
+			if err := health.CheckAlarms(ctx, client, gate.Alarms); err != nil {
+				return err
+			}
+		}
+		for _, metric := range gate.Metrics {
+			if err := health.CheckMetric(ctx, client, metric); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() != nil {
+		// a gate interrupted before the end of its soak didn't find the resources healthy
+		return fmt.Errorf("%w, health gate not checked to the end: %w", contextErr(ctx), err)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("health gate failed", logging.Err(err))
+		return fmt.Errorf("%w: %w", ErrHealthGate, err)
+	}
+	return nil
+}
+
+// gateResource checks the health gate of a scaled resource and rolls the resource back when the gate found it unhealthy
+func (s *Scaler) gateResource(ctx context.Context, scalingPlan *ScalingPlan, resourcePlan *service.ResourcePlan) []*service.ScalingError {
+	err := s.checkGate(ctx, resourcePlan.HealthGate, resourcePlan.Region)
+	if err == nil {
+		return nil
+	}
+
+	if resourcePlan.HealthGate.Rollback && errors.Is(err, health.ErrUnhealthy) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+
+		return s.rollBack(ctx, scalingPlan, []*service.ResourcePlan{resourcePlan}, err)
+	}
+	return []*service.ScalingError{{
+		Region:       resourcePlan.Region,
+		ServiceName:  resourcePlan.ServiceName,
+		IdentifierId: resourcePlan.IdentifierId,
+		Err:          err,
+	}}
+}
+
+// gateRegion checks the health gate of a region once its resources are scaled and rolls back the resources
+// it scaled when the gate found the region unhealthy
+func (s *Scaler) gateRegion(ctx context.Context, scalingPlan *ScalingPlan, region string, gate *config.HealthGate, scaled []*service.ResourcePlan, checkpoint *checkpoint) []*service.ScalingError {
+	if len(scaled) == 0 {
+		return nil
+	}
+
+	err := s.checkGate(ctx, gate, region)
+	if err == nil {
+		return nil
+	}
+
+	if gate.Rollback && errors.Is(err, health.ErrUnhealthy) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+
+		rolledBack := s.rollBack(ctx, scalingPlan, scaled, err)
+		for i, resourcePlan := range scaled {
+			checkpoint.update(ctx, resourcePlan, state.StatusFailed, rolledBack[i:i+1])
+		}
+		return rolledBack
+	}
+	return []*service.ScalingError{{
+		Region:       region,
+		ServiceName:  healthGateService,
+		IdentifierId: region,
+		Err:          err,
+	}}
+}
+
+// rollBack scales the resources back to the capacity they had before the run, the way the reaper reverts
+// expired scale-ups, and fails every resource with the reason of the rollback. The rollback isn't checked against
+// the guardrails and quotas, it only restores a capacity the resources already had.
+func (s *Scaler) rollBack(ctx context.Context, scalingPlan *ScalingPlan, resourcePlans []*service.ResourcePlan, reason error) []*service.ScalingError {
+	logging.FromContext(ctx).Warn("rolling back, bypassing guardrails and quotas", "resources", len(resourcePlans), logging.Err(reason))
+
+	failures := make(map[string][]error)
+	var resources []ttl.ResourceSnapshot
+	for _, resourcePlan := range resourcePlans {
+		key := resourceKey(resourcePlan.Region, resourcePlan.ServiceName, resourcePlan.IdentifierId)
+		if resourcePlan.Current == nil {
+			failures[key] = append(failures[key], fmt.Errorf("current capacity unknown"))
+			continue
+		}
+		resources = append(resources, ttl.ResourceSnapshot{
+			Region:       resourcePlan.Region,
+			ServiceName:  resourcePlan.ServiceName,
+			IdentifierId: resourcePlan.IdentifierId,
+			Capacity:     resourcePlan.Current,
+		})
+	}
+
+	profile, missing := revertProfile(scalingPlan.ScalingConfig, resources)
+	profile.ScaleUp = !scalingPlan.scaleUp
+	rollbackPlan := s.planApp(ctx, profile)
+
+	scalingErrors := append(missing, rollbackPlan.FailedServices...)
+	for _, resourcePlan := range rollbackPlan.Resources {
+		applyCtx, cancel := withTimeout(ctx, resourcePlan.Timeout)
+		scalingErrors = append(scalingErrors, timedOut(applyCtx, resourcePlan.Apply(applyCtx))...)
+		cancel()
+	}
+	for _, scalingError := range scalingErrors {
+		key := resourceKey(scalingError.Region, scalingError.ServiceName, scalingError.IdentifierId)
+		failures[key] = append(failures[key], scalingError.Err)
+	}
+
+	rolledBack := make([]*service.ScalingError, 0, len(resourcePlans))
+	for _, resourcePlan := range resourcePlans {
+		err := fmt.Errorf("%w to %v: %w", ErrRolledBack, resourcePlan.Current, reason)
+		if errs := failures[resourceKey(resourcePlan.Region, resourcePlan.ServiceName, resourcePlan.IdentifierId)]; len(errs) > 0 {
+			err = fmt.Errorf("%w, error rolling back: %w", reason, errors.Join(errs...))
+			logging.FromContext(ctx).Error("error rolling back", logging.RegionKey, resourcePlan.Region, logging.ServiceKey, resourcePlan.ServiceName, logging.IdentifierKey, resourcePlan.IdentifierId, logging.Err(err))
+		}
+		rolledBack = append(rolledBack, &service.ScalingError{
+			Region:       resourcePlan.Region,
+			ServiceName:  resourcePlan.ServiceName,
+			IdentifierId: resourcePlan.IdentifierId,
+			Err:          err,
+		})
+	}
+	return rolledBack
+}
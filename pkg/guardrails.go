@@ -0,0 +1,93 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"sort"
+)
+
+var ErrGuardrailViolation = errors.New("guardrail violation")
+
+// checkPlanGuardrails checks the guardrails of the app against the resources of the plan, and the service quotas of
+// their regions when the guardrails check them
+func (s *Scaler) checkPlanGuardrails(ctx context.Context, scalingPlan *ScalingPlan, resourcePlans []*service.ResourcePlan) {
+	guardrails := scalingPlan.ScalingConfig.Guardrails
+	scalingPlan.GuardrailViolations, scalingPlan.GuardrailErr = checkGuardrails(guardrails, resourcePlans)
+	scalingPlan.QuotasNotChecked = nil
+	if guardrails != nil && guardrails.CheckQuotas {
+		violations, unchecked := s.checkQuotas(ctx, resourcePlans)
+		scalingPlan.GuardrailViolations = append(scalingPlan.GuardrailViolations, violations...)
+		scalingPlan.QuotasNotChecked = unchecked
+	}
+}
+
+func checkGuardrails(guardrails *config.Guardrails, resourcePlans []*service.ResourcePlan) ([]*service.ScalingError, error) {
+	if guardrails == nil {
+		return nil, nil
+	}
+
+	changedResources := 0
+	var violations []*service.ScalingError
+	for _, resourcePlan := range resourcePlans {
+		if resourcePlan.IsChange() {
+			changedResources++
+		}
+
+		for _, err := range checkResourceGuardrails(guardrails, resourcePlan) {
+			violations = append(violations, &service.ScalingError{
+				Region:       resourcePlan.Region,
+				ServiceName:  resourcePlan.ServiceName,
+				IdentifierId: resourcePlan.IdentifierId,
+				Err:          err,
+			})
+		}
+	}
+
+	if guardrails.MaxResourcesChanged > 0 && changedResources > guardrails.MaxResourcesChanged {
+		return violations, fmt.Errorf("%w: run changes %d resources, limit is %d", ErrGuardrailViolation, changedResources, guardrails.MaxResourcesChanged)
+	}
+	return violations, nil
+}
+
+func checkResourceGuardrails(guardrails *config.Guardrails, resourcePlan *service.ResourcePlan) []error {
+	names := make([]string, 0, len(resourcePlan.Target))
+	for name := range resourcePlan.Target {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	maxCapacity, hasMaxCapacity := guardrails.MaxCapacity[resourcePlan.ServiceName]
+	for _, name := range names {
+		target := resourcePlan.Target[name]
+		if hasMaxCapacity && target > maxCapacity {
+			errs = append(errs, fmt.Errorf("%w: %s %d exceeds max capacity %d", ErrGuardrailViolation, name, target, maxCapacity))
+		}
+
+		current, ok := resourcePlan.Current[name]
+		if guardrails.MaxChangeFactor > 0 && ok {
+			if factor := changeFactor(current, target); factor > guardrails.MaxChangeFactor {
+				errs = append(errs, fmt.Errorf("%w: %s change from %d to %d is a factor of %.2f, limit is %.2f", ErrGuardrailViolation, name, current, target, factor, guardrails.MaxChangeFactor))
+			}
+		}
+	}
+	return errs
+}
+
+func changeFactor(current int, target int) float64 {
+	from, to := float64(current), float64(target)
+	if from < 1 {
+		from = 1
+	}
+	if to < 1 {
+		to = 1
+	}
+
+	if to > from {
+		return to / from
+	}
+	return from / to
+}
@@ -0,0 +1,110 @@
+package pkg
+
+import (
+	"errors"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"testing"
+)
+
+func TestChangeFactor(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int
+		target  int
+		want    float64
+	}{
+		{name: "unchanged", current: 4, target: 4, want: 1},
+		{name: "grow", current: 2, target: 6, want: 3},
+		{name: "shrink", current: 6, target: 2, want: 3},
+		{name: "fractional", current: 4, target: 5, want: 1.25},
+		{name: "from zero", current: 0, target: 5, want: 5},
+		{name: "to zero", current: 5, target: 0, want: 5},
+		{name: "zero to one", current: 0, target: 1, want: 1},
+		{name: "zero to zero", current: 0, target: 0, want: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := changeFactor(test.current, test.target); got != test.want {
+				t.Errorf("changeFactor(%d, %d) = %v, want %v", test.current, test.target, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckGuardrails(t *testing.T) {
+	resourcePlan := func(identifierId string, current int, target int) *service.ResourcePlan {
+		return &service.ResourcePlan{
+			Region:       "us-east-1",
+			ServiceName:  string(service.EC2),
+			IdentifierId: identifierId,
+			Current:      service.Capacity{"desiredCount": current},
+			Target:       service.Capacity{"desiredCount": target},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		guardrails *config.Guardrails
+		plans      []*service.ResourcePlan
+		violations int
+		wantErr    bool
+	}{
+		{
+			name:  "no guardrails",
+			plans: []*service.ResourcePlan{resourcePlan("a", 1, 1000)},
+		},
+		{
+			name:       "within guardrails",
+			guardrails: &config.Guardrails{MaxCapacity: map[string]int{"ec2": 10}, MaxChangeFactor: 2, MaxResourcesChanged: 2},
+			plans:      []*service.ResourcePlan{resourcePlan("a", 4, 8), resourcePlan("b", 4, 2)},
+		},
+		{
+			name:       "max capacity",
+			guardrails: &config.Guardrails{MaxCapacity: map[string]int{"ec2": 10}},
+			plans:      []*service.ResourcePlan{resourcePlan("a", 4, 11), resourcePlan("b", 4, 10)},
+			violations: 1,
+		},
+		{
+			name:       "max capacity of another service",
+			guardrails: &config.Guardrails{MaxCapacity: map[string]int{"kinesis": 10}},
+			plans:      []*service.ResourcePlan{resourcePlan("a", 4, 11)},
+		},
+		{
+			name:       "max change factor",
+			guardrails: &config.Guardrails{MaxChangeFactor: 2},
+			plans:      []*service.ResourcePlan{resourcePlan("a", 4, 9), resourcePlan("b", 4, 1)},
+			violations: 2,
+		},
+		{
+			name:       "max capacity and change factor on one resource",
+			guardrails: &config.Guardrails{MaxCapacity: map[string]int{"ec2": 10}, MaxChangeFactor: 2},
+			plans:      []*service.ResourcePlan{resourcePlan("a", 4, 20)},
+			violations: 2,
+		},
+		{
+			name:       "max resources changed",
+			guardrails: &config.Guardrails{MaxResourcesChanged: 1},
+			plans:      []*service.ResourcePlan{resourcePlan("a", 4, 5), resourcePlan("b", 4, 5), resourcePlan("c", 4, 4)},
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			violations, err := checkGuardrails(test.guardrails, test.plans)
+			if len(violations) != test.violations {
+				t.Errorf("checkGuardrails() returned %d violations, want %d: %v", len(violations), test.violations, violations)
+			}
+			for _, violation := range violations {
+				if !errors.Is(violation.Err, ErrGuardrailViolation) {
+					t.Errorf("violation %v isn't a guardrail violation", violation.Err)
+				}
+			}
+			if (err != nil) != test.wantErr {
+				t.Errorf("checkGuardrails() error = %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
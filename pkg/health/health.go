@@ -0,0 +1,232 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// pollInterval is how often a failing check is retried
+	pollInterval = 15 * time.Second
+
+	// checkTimeout is how long a single check may take, so a hanging probe or API call doesn't outlast the timeout
+	checkTimeout = 30 * time.Second
+
+	// DescribeAlarms accepts up to 100 alarm names
+	maxAlarmNames = 100
+
+	// defaultPeriod is the period of metric statistics without one
+	defaultPeriod = time.Minute
+)
+
+// ErrUnhealthy marks the failures of checks that found an alarm or a metric unhealthy, as opposed to checks that failed to run
+var ErrUnhealthy = errors.New("unhealthy")
+
+// CheckAlarms fails when an alarm is in ALARM state or doesn't exist
+func CheckAlarms(ctx context.Context, client *cloudwatch.Client, names []string) error {
+	states := make(map[string]types.StateValue)
+	for start := 0; start < len(names); start += maxAlarmNames {
+		end := min(start+maxAlarmNames, len(names))
+		paginator := cloudwatch.NewDescribeAlarmsPaginator(client, &cloudwatch.DescribeAlarmsInput{
+			AlarmNames: names[start:end],
+			AlarmTypes: []types.AlarmType{types.AlarmTypeMetricAlarm, types.AlarmTypeCompositeAlarm},
+		})
+		for paginator.HasMorePages() {
+			output, err := paginator.NextPage(ctx)
+			if err != nil {
+				return fmt.Errorf("error describing alarms: %w", err)
+			}
+			for _, alarm := range output.MetricAlarms {
+				states[aws.ToString(alarm.AlarmName)] = alarm.StateValue
+			}
+			for _, alarm := range output.CompositeAlarms {
+				states[aws.ToString(alarm.AlarmName)] = alarm.StateValue
+			}
+		}
+	}
+
+	var alarming, missing []string
+	for _, name := range names {
+		state, ok := states[name]
+		switch {
+		case !ok:
+			missing = append(missing, name)
+		case state == types.StateValueAlarm:
+			alarming = append(alarming, name)
+		}
+	}
+	if len(alarming) > 0 {
+		return fmt.Errorf("%w: alarms in ALARM state: %s", ErrUnhealthy, strings.Join(alarming, ", "))
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("alarms not found: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Probe fails when the url doesn't answer a GET with a 2xx status
+func Probe(ctx context.Context, url string, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating health probe of %s: %w", url, err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("health probe of %s failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("health probe of %s failed: unexpected status %s", url, resp.Status)
+	}
+	return nil
+}
+
+// CheckMetric fails when the latest statistic of the metric breaches its threshold, a metric without data passes
+func CheckMetric(ctx context.Context, client *cloudwatch.Client, metric config.MetricThreshold) error {
+	period := metric.Period
+	if period == 0 {
+		period = defaultPeriod
+	}
+	statistic := types.Statistic(metric.Statistic)
+	if statistic == "" {
+		statistic = types.StatisticAverage
+	}
+
+	dimensions := make([]types.Dimension, 0, len(metric.Dimensions))
+	for name, value := range metric.Dimensions {
+		dimensions = append(dimensions, types.Dimension{Name: aws.String(name), Value: aws.String(value)})
+	}
+
+	now := time.Now()
+	output, err := client.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String(metric.Namespace),
+		MetricName: aws.String(metric.MetricName),
+		Dimensions: dimensions,
+		StartTime:  aws.Time(now.Add(-2 * period)),
+		EndTime:    aws.Time(now),
+		Period:     aws.Int32(int32(period.Seconds())),
+		Statistics: []types.Statistic{statistic},
+	})
+	if err != nil {
+		return fmt.Errorf("error getting statistics of metric %s: %w", metric, err)
+	}
+
+	var latest *types.Datapoint
+	for i := range output.Datapoints {
+		datapoint := &output.Datapoints[i]
+		if latest == nil || aws.ToTime(datapoint.Timestamp).After(aws.ToTime(latest.Timestamp)) {
+			latest = datapoint
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+
+	value := statisticValue(latest, statistic)
+	if breaches(value, metric.Threshold, metric.Comparison) {
+		return fmt.Errorf("%w: %s %s of metric %s breaches threshold %v", ErrUnhealthy, statistic, formatValue(value), metric, metric.Threshold)
+	}
+	return nil
+}
+
+func statisticValue(datapoint *types.Datapoint, statistic types.Statistic) float64 {
+	switch statistic {
+	case types.StatisticSum:
+		return aws.ToFloat64(datapoint.Sum)
+	case types.StatisticMinimum:
+		return aws.ToFloat64(datapoint.Minimum)
+	case types.StatisticMaximum:
+		return aws.ToFloat64(datapoint.Maximum)
+	case types.StatisticSampleCount:
+		return aws.ToFloat64(datapoint.SampleCount)
+	default:
+		return aws.ToFloat64(datapoint.Average)
+	}
+}
+
+func breaches(value float64, threshold float64, comparison string) bool {
+	switch comparison {
+	case config.GreaterThanOrEqualToThreshold:
+		return value >= threshold
+	case config.LessThanThreshold:
+		return value < threshold
+	case config.LessThanOrEqualToThreshold:
+		return value <= threshold
+	default:
+		return value > threshold
+	}
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// Soak runs the check every poll interval for the duration, a duration of 0 runs it once. It returns at the first
+// unhealthy check, a check that failed to run is retried and only returned when the last check failed to run.
+// A soak whose context is done before the end of the duration fails.
+func Soak(ctx context.Context, duration time.Duration, check func(ctx context.Context) error) error {
+	deadline := time.Now().Add(duration)
+	for {
+		err := runCheck(ctx, check)
+		if errors.Is(err, ErrUnhealthy) || !time.Now().Before(deadline) {
+			return err
+		}
+
+		timer := time.NewTimer(min(pollInterval, time.Until(deadline)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			// a soak cut short didn't watch for the whole duration, so it can't pass
+			return fmt.Errorf("soak interrupted: %w", context.Cause(ctx))
+		case <-timer.C:
+		}
+	}
+}
+
+// Poll retries the check until it passes or the timeout expires, a timeout of 0 runs the check once
+func Poll(ctx context.Context, timeout time.Duration, check func(ctx context.Context) error) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := runCheck(ctx, check)
+		if err == nil || time.Now().Add(pollInterval).After(deadline) {
+			return err
+		}
+
+		timer := time.NewTimer(pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func runCheck(ctx context.Context, check func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	if err := check(ctx); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("check timed out: %w", err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRunCheck(t *testing.T) {
+	unhealthy := errors.New("unhealthy")
+	tests := []struct {
+		name    string
+		check   func(ctx context.Context) error
+		wantErr error
+	}{
+		{name: "passing", check: func(ctx context.Context) error { return nil }},
+		{name: "failing", check: func(ctx context.Context) error { return unhealthy }, wantErr: unhealthy},
+		{
+			name: "bounded by the check timeout",
+			check: func(ctx context.Context) error {
+				deadline, ok := ctx.Deadline()
+				if !ok || time.Until(deadline) > checkTimeout {
+					return errors.New("check has no deadline of its own")
+				}
+				return nil
+			},
+		},
+		{
+			name: "timed out",
+			check: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.wantErr == context.DeadlineExceeded {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, time.Millisecond)
+				defer cancel()
+			}
+
+			err := runCheck(ctx, test.check)
+
+			if !errors.Is(err, test.wantErr) || (test.wantErr == nil && err != nil) {
+				t.Errorf("runCheck() = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestSoak(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	unhealthy := fmt.Errorf("%w: alarm in ALARM state", ErrUnhealthy)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		duration time.Duration
+		check    error
+		wantErr  error
+	}{
+		{name: "healthy once", ctx: context.Background(), check: nil},
+		{name: "unhealthy", ctx: context.Background(), duration: time.Hour, check: unhealthy, wantErr: ErrUnhealthy},
+		{name: "interrupted while healthy", ctx: canceled, duration: time.Hour, check: nil, wantErr: context.Canceled},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Soak(test.ctx, test.duration, func(ctx context.Context) error { return test.check })
+
+			if !errors.Is(err, test.wantErr) || (test.wantErr == nil && err != nil) {
+				t.Errorf("Soak() = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
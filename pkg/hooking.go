@@ -0,0 +1,116 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/audit"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/hooks"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+)
+
+// hookService names the failures of app and region hooks, which aren't tied to a resource
+const hookService = "hook"
+
+// appRegion is the region of failures that concern the whole app
+const appRegion = "global"
+
+// runHooks runs the hooks of a phase in order and stops at the first failure of a hook that aborts
+func runHooks(ctx context.Context, hooksConfig *config.HooksConfig, phase string, vars map[string]string) error {
+	if hooksConfig == nil {
+		return nil
+	}
+
+	phaseHooks := hooksConfig.Pre
+	if phase == hooks.PostPhase {
+		phaseHooks = hooksConfig.Post
+	}
+
+	for _, hook := range phaseHooks {
+		if ctx.Err() != nil {
+			return contextErr(ctx)
+		}
+		logger := logging.FromContext(ctx).With("hook", hook.String(), "phase", phase)
+		logger.Debug("running hook")
+
+		err := hooks.Run(ctx, hook, withVars(vars, hooks.PhaseVar, phase))
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return contextErr(ctx)
+		}
+		if hook.Aborts() {
+			return err
+		}
+		logger.Warn("hook failed, continuing", logging.Err(err))
+	}
+	return nil
+}
+
+func appHookVars(scalingPlan *ScalingPlan) map[string]string {
+	return map[string]string{
+		hooks.AppVar:     scalingPlan.ScalingConfig.Name,
+		hooks.ProfileVar: scalingPlan.Profile,
+		hooks.RunIdVar:   scalingPlan.RunId,
+		hooks.ScopeVar:   hooks.AppScope,
+	}
+}
+
+func serviceHookVars(vars map[string]string, resourcePlan *service.ResourcePlan) map[string]string {
+	vars = withVars(vars,
+		hooks.ScopeVar, hooks.ServiceScope,
+		hooks.ServiceVar, resourcePlan.ServiceName,
+		hooks.IdentifierVar, resourcePlan.IdentifierId)
+
+	if resourcePlan.Current != nil {
+		if current, err := json.Marshal(resourcePlan.Current); err == nil {
+			vars[hooks.CurrentVar] = string(current)
+		}
+	}
+	if target, err := json.Marshal(resourcePlan.Target); err == nil {
+		vars[hooks.TargetVar] = string(target)
+	}
+	return vars
+}
+
+// withVars copies the variables and sets the name value pairs
+func withVars(vars map[string]string, nameValues ...string) map[string]string {
+	copied := make(map[string]string, len(vars)+len(nameValues)/2)
+	for name, value := range vars {
+		copied[name] = value
+	}
+	for i := 0; i+1 < len(nameValues); i += 2 {
+		copied[nameValues[i]] = nameValues[i+1]
+	}
+	return copied
+}
+
+func runOutcome(failedServices []*service.ScalingError) string {
+	for _, failedService := range failedServices {
+		if errors.Is(failedService.Err, ErrCanceled) {
+			return audit.OutcomeCanceled
+		}
+	}
+	if len(failedServices) > 0 {
+		return audit.OutcomeFailed
+	}
+	return audit.OutcomeSucceeded
+}
+
+// skipped fails the resources whose scaling a pre hook or the cancellation of the run aborted
+func skipped(resourcePlans []*service.ResourcePlan, err error) []*service.ScalingError {
+	scalingErrors := make([]*service.ScalingError, 0, len(resourcePlans))
+	for _, resourcePlan := range resourcePlans {
+		scalingErrors = append(scalingErrors, &service.ScalingError{
+			Region:       resourcePlan.Region,
+			ServiceName:  resourcePlan.ServiceName,
+			IdentifierId: resourcePlan.IdentifierId,
+			Err:          fmt.Errorf("scaling skipped: %w", err),
+		})
+	}
+	return scalingErrors
+}
@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/hooks"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunHooks(t *testing.T) {
+	appendHook := func(file string, name string) config.Hook {
+		return config.Hook{Name: name, Command: fmt.Sprintf(`echo "%s $SCALER_PHASE" >> %s`, name, file)}
+	}
+	failingHook := func(onFailure string) config.Hook {
+		return config.Hook{Name: "failing", Command: "exit 1", OnFailure: onFailure}
+	}
+	slowHook := config.Hook{Name: "slow", Command: "sleep 5", Timeout: 50 * time.Millisecond}
+
+	tests := []struct {
+		name    string
+		phase   string
+		hooks   func(file string) *config.HooksConfig
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "pre hooks in order",
+			phase: hooks.PrePhase,
+			hooks: func(file string) *config.HooksConfig {
+				return &config.HooksConfig{
+					Pre:  []config.Hook{appendHook(file, "first"), appendHook(file, "second"), appendHook(file, "third")},
+					Post: []config.Hook{appendHook(file, "post")},
+				}
+			},
+			want: []string{"first pre", "second pre", "third pre"},
+		},
+		{
+			name:  "post hooks",
+			phase: hooks.PostPhase,
+			hooks: func(file string) *config.HooksConfig {
+				return &config.HooksConfig{
+					Pre:  []config.Hook{appendHook(file, "pre")},
+					Post: []config.Hook{appendHook(file, "first"), appendHook(file, "second")},
+				}
+			},
+			want: []string{"first post", "second post"},
+		},
+		{
+			name:  "failing pre hook aborts",
+			phase: hooks.PrePhase,
+			hooks: func(file string) *config.HooksConfig {
+				return &config.HooksConfig{Pre: []config.Hook{appendHook(file, "first"), failingHook(""), appendHook(file, "second")}}
+			},
+			want:    []string{"first pre"},
+			wantErr: "hook failing failed",
+		},
+		{
+			name:  "failing hook continues",
+			phase: hooks.PrePhase,
+			hooks: func(file string) *config.HooksConfig {
+				return &config.HooksConfig{Pre: []config.Hook{appendHook(file, "first"), failingHook(config.ContinueOnFailure), appendHook(file, "second")}}
+			},
+			want: []string{"first pre", "second pre"},
+		},
+		{
+			name:  "timed out hook aborts",
+			phase: hooks.PrePhase,
+			hooks: func(file string) *config.HooksConfig {
+				return &config.HooksConfig{Pre: []config.Hook{slowHook, appendHook(file, "second")}}
+			},
+			wantErr: "hook slow timed out",
+		},
+		{
+			name:  "no hooks",
+			phase: hooks.PrePhase,
+			hooks: func(file string) *config.HooksConfig {
+				return nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "hooks.log")
+			err := runHooks(context.Background(), test.hooks(file), test.phase, map[string]string{})
+			if test.wantErr == "" && err != nil {
+				t.Fatalf("runHooks() error = %v", err)
+			}
+			if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("runHooks() error = %v, want %s", err, test.wantErr)
+			}
+
+			var got []string
+			if output, err := os.ReadFile(file); err == nil {
+				got = strings.Split(strings.TrimSpace(string(output)), "\n")
+			}
+			if strings.Join(got, ",") != strings.Join(test.want, ",") {
+				t.Errorf("runHooks() ran %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRunHooksCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	hooksConfig := &config.HooksConfig{Pre: []config.Hook{{Command: "true"}}}
+	if err := runHooks(ctx, hooksConfig, hooks.PrePhase, nil); err == nil {
+		t.Error("runHooks() error = nil, want the run to be canceled")
+	}
+}
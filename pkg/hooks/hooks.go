@@ -0,0 +1,140 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout = time.Minute
+
+	// wait for the output of processes started by a killed command before giving up on them
+	waitDelay = time.Second
+
+	// output of failed hooks included in the error
+	maxOutputLength = 512
+)
+
+const (
+	PrePhase  = "pre"
+	PostPhase = "post"
+)
+
+const (
+	AppScope     = "app"
+	RegionScope  = "region"
+	ServiceScope = "service"
+)
+
+// Variables names the scaling context passed to hooks, as environment variables of commands
+// and as the JSON body of HTTP hooks
+const (
+	AppVar        = "SCALER_APP"
+	ProfileVar    = "SCALER_PROFILE"
+	RunIdVar      = "SCALER_RUN_ID"
+	PhaseVar      = "SCALER_PHASE"
+	ScopeVar      = "SCALER_SCOPE"
+	RegionVar     = "SCALER_REGION"
+	ServiceVar    = "SCALER_SERVICE"
+	IdentifierVar = "SCALER_IDENTIFIER"
+	CurrentVar    = "SCALER_CURRENT"
+	TargetVar     = "SCALER_TARGET"
+	OutcomeVar    = "SCALER_OUTCOME"
+)
+
+func Run(ctx context.Context, hook config.Hook, vars map[string]string) error {
+	timeout := hook.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if hook.Command != "" {
+		return runCommand(ctx, hook, vars)
+	}
+	return call(ctx, hook, vars)
+}
+
+func runCommand(ctx context.Context, hook config.Hook, vars map[string]string) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	cmd.WaitDelay = waitDelay
+	cmd.Env = os.Environ()
+	for _, name := range sortedNames(vars) {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", name, vars[name]))
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+			return fmt.Errorf("hook %s timed out", hook)
+		}
+		if text := truncate(output); text != "" {
+			return fmt.Errorf("hook %s failed: %w: %s", hook, err, text)
+		}
+		return fmt.Errorf("hook %s failed: %w", hook, err)
+	}
+	return nil
+}
+
+func call(ctx context.Context, hook config.Hook, vars map[string]string) error {
+	method := hook.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	body, err := json.Marshal(vars)
+	if err != nil {
+		return fmt.Errorf("error encoding context of hook %s: %w", hook, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request of hook %s: %w", hook, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range hook.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("hook %s failed: %w", hook, err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("hook %s failed: unexpected status %s", hook, resp.Status)
+	}
+	return nil
+}
+
+func sortedNames(vars map[string]string) []string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func truncate(output []byte) string {
+	text := strings.TrimSpace(string(output))
+	if len(text) > maxOutputLength {
+		text = text[len(text)-maxOutputLength:]
+	}
+	return text
+}
@@ -0,0 +1,83 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    config.Hook
+		wantErr string
+	}{
+		{name: "succeeds", hook: config.Hook{Command: `test "$SCALER_APP" = my-app`}},
+		{name: "fails with output", hook: config.Hook{Name: "check", Command: "echo not ready; exit 3"}, wantErr: "hook check failed: exit status 3: not ready"},
+		{name: "times out", hook: config.Hook{Name: "slow", Command: "sleep 5", Timeout: 50 * time.Millisecond}, wantErr: "hook slow timed out"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Run(context.Background(), test.hook, map[string]string{AppVar: "my-app"})
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Run() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.wantErr {
+				t.Errorf("Run() error = %v, want %s", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunURL(t *testing.T) {
+	var body map[string]string
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Token")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	hook := config.Hook{URL: server.URL, Headers: map[string]string{"X-Token": "secret"}}
+	if err := Run(context.Background(), hook, map[string]string{PhaseVar: PrePhase}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if body[PhaseVar] != PrePhase || header != "secret" {
+		t.Errorf("Run() sent %v with token %q, want %s=%s with token secret", body, header, PhaseVar, PrePhase)
+	}
+
+	hook.Method = http.MethodPut
+	if err := Run(context.Background(), hook, nil); err == nil || !strings.Contains(err.Error(), "unexpected status 503") {
+		t.Errorf("Run() error = %v, want unexpected status 503", err)
+	}
+}
+
+func TestRunURLTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	start := time.Now()
+	err := Run(context.Background(), config.Hook{URL: server.URL, Timeout: 50 * time.Millisecond}, nil)
+	if err == nil {
+		t.Fatal("Run() error = nil, want timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Run() took %s, want it to stop at the hook timeout", elapsed)
+	}
+}
@@ -0,0 +1,159 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"strconv"
+	"time"
+)
+
+const (
+	lockKeyAttribute    = "LockKey"
+	ownerAttribute      = "Owner"
+	acquiredAtAttribute = "AcquiredAt"
+	expiresAtAttribute  = "ExpiresAt"
+)
+
+type DynamoDBBackend struct {
+	Client    *dynamodb.Client
+	TableName string
+}
+
+func NewDynamoDBBackend(client *dynamodb.Client, tableName string) *DynamoDBBackend {
+	return &DynamoDBBackend{
+		Client:    client,
+		TableName: tableName,
+	}
+}
+
+func (d *DynamoDBBackend) TryAcquire(ctx context.Context, lock Lock) (*Lock, error) {
+	_, err := d.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           &d.TableName,
+		Item:                lockItem(lock),
+		ConditionExpression: aws.String("attribute_not_exists(#key) OR #expiresAt < :now OR #owner = :owner"),
+		ExpressionAttributeNames: map[string]string{
+			"#key":       lockKeyAttribute,
+			"#owner":     ownerAttribute,
+			"#expiresAt": expiresAtAttribute,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now":   unixTimeValue(time.Now()),
+			":owner": &types.AttributeValueMemberS{Value: lock.Owner},
+		},
+	})
+	if err == nil {
+		return nil, nil
+	}
+
+	var conditionalCheckFailed *types.ConditionalCheckFailedException
+	if !errors.As(err, &conditionalCheckFailed) {
+		return nil, err
+	}
+
+	return d.holder(ctx, lock.Key)
+}
+
+func (d *DynamoDBBackend) Renew(ctx context.Context, lock Lock) error {
+	_, err := d.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           &d.TableName,
+		Key:                 lockKey(lock.Key),
+		UpdateExpression:    aws.String("SET #expiresAt = :expiresAt"),
+		ConditionExpression: aws.String("#owner = :owner"),
+		ExpressionAttributeNames: map[string]string{
+			"#owner":     ownerAttribute,
+			"#expiresAt": expiresAtAttribute,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":expiresAt": unixTimeValue(lock.ExpiresAt),
+			":owner":     &types.AttributeValueMemberS{Value: lock.Owner},
+		},
+	})
+
+	var conditionalCheckFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionalCheckFailed) {
+		return fmt.Errorf("%w: %s is no longer owned by %s", ErrLockLost, lock.Key, lock.Owner)
+	}
+	return err
+}
+
+func (d *DynamoDBBackend) Release(ctx context.Context, lock Lock) error {
+	_, err := d.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           &d.TableName,
+		Key:                 lockKey(lock.Key),
+		ConditionExpression: aws.String("#owner = :owner"),
+		ExpressionAttributeNames: map[string]string{
+			"#owner": ownerAttribute,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":owner": &types.AttributeValueMemberS{Value: lock.Owner},
+		},
+	})
+	return err
+}
+
+func (d *DynamoDBBackend) ForceUnlock(ctx context.Context, key string) error {
+	_, err := d.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: &d.TableName,
+		Key:       lockKey(key),
+	})
+	return err
+}
+
+func (d *DynamoDBBackend) holder(ctx context.Context, key string) (*Lock, error) {
+	output, err := d.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &d.TableName,
+		Key:            lockKey(key),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if output.Item == nil {
+		return &Lock{Key: key, Owner: "unknown"}, nil
+	}
+
+	lock := &Lock{Key: key}
+	if owner, ok := output.Item[ownerAttribute].(*types.AttributeValueMemberS); ok {
+		lock.Owner = owner.Value
+	}
+	lock.AcquiredAt = unixTime(output.Item[acquiredAtAttribute])
+	lock.ExpiresAt = unixTime(output.Item[expiresAtAttribute])
+	return lock, nil
+}
+
+func lockKey(key string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		lockKeyAttribute: &types.AttributeValueMemberS{Value: key},
+	}
+}
+
+func lockItem(lock Lock) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		lockKeyAttribute:    &types.AttributeValueMemberS{Value: lock.Key},
+		ownerAttribute:      &types.AttributeValueMemberS{Value: lock.Owner},
+		acquiredAtAttribute: unixTimeValue(lock.AcquiredAt),
+		expiresAtAttribute:  unixTimeValue(lock.ExpiresAt),
+	}
+}
+
+func unixTimeValue(t time.Time) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.Unix(), 10)}
+}
+
+func unixTime(value types.AttributeValue) time.Time {
+	n, ok := value.(*types.AttributeValueMemberN)
+	if !ok {
+		return time.Time{}
+	}
+
+	seconds, err := strconv.ParseInt(n.Value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
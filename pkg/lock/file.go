@@ -0,0 +1,192 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// guardTimeout is how long to wait for the guard of a lock file, a guard older than that was left behind by a
+	// crashed run and is removed
+	guardTimeout = 10 * time.Second
+
+	guardRetryInterval = 50 * time.Millisecond
+)
+
+// FileBackend keeps locks in files of a local directory, it only locks runs on the same host
+type FileBackend struct {
+	Dir string
+}
+
+func NewFileBackend(dir string) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating lock directory: %w", err)
+	}
+	return &FileBackend{Dir: dir}, nil
+}
+
+func (f *FileBackend) TryAcquire(_ context.Context, lock Lock) (*Lock, error) {
+	for {
+		err := f.create(lock)
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		holder, err := f.read(lock.Key)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if holder.Owner != lock.Owner && !holder.expired(time.Now()) {
+			return holder, nil
+		}
+
+		// the holder is re-read under the guard, another waiter may have taken the expired lock over or its
+		// owner renewed it since it was read
+		err = f.guarded(lock.Key, func() error {
+			current, err := f.read(lock.Key)
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if current.Owner != holder.Owner || !current.ExpiresAt.Equal(holder.ExpiresAt) {
+				return nil
+			}
+			return f.remove(lock.Key)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (f *FileBackend) Renew(_ context.Context, lock Lock) error {
+	return f.guarded(lock.Key, func() error {
+		holder, err := f.read(lock.Key)
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s was removed", ErrLockLost, lock.Key)
+		}
+		if err != nil {
+			return err
+		}
+		if holder.Owner != lock.Owner {
+			return fmt.Errorf("%w: %s is no longer owned by %s", ErrLockLost, lock.Key, lock.Owner)
+		}
+
+		temp, err := f.writeTemp(lock)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(temp)
+		return os.Rename(temp, f.path(lock.Key))
+	})
+}
+
+func (f *FileBackend) Release(_ context.Context, lock Lock) error {
+	return f.guarded(lock.Key, func() error {
+		holder, err := f.read(lock.Key)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if holder.Owner != lock.Owner {
+			return fmt.Errorf("lock %s is no longer owned by %s", lock.Key, lock.Owner)
+		}
+		return f.remove(lock.Key)
+	})
+}
+
+func (f *FileBackend) ForceUnlock(_ context.Context, key string) error {
+	return f.remove(key)
+}
+
+// create writes the lock to a file of its own and links it to the lock file, the link fails when the lock file
+// exists, so the lock file is created atomically with its content
+func (f *FileBackend) create(lock Lock) error {
+	temp, err := f.writeTemp(lock)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp)
+	return os.Link(temp, f.path(lock.Key))
+}
+
+func (f *FileBackend) writeTemp(lock Lock) (string, error) {
+	file, err := os.CreateTemp(f.Dir, filepath.Base(lock.Key)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := json.NewEncoder(file).Encode(lock); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// guarded runs fn holding the guard file of the lock, created with O_EXCL, so replacing an expired lock, renewing
+// and releasing a lock don't interleave
+func (f *FileBackend) guarded(key string, fn func() error) error {
+	guardPath := f.path(key) + ".guard"
+	deadline := time.Now().Add(guardTimeout)
+	for {
+		file, err := os.OpenFile(guardPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			file.Close()
+			defer os.Remove(guardPath)
+			return fn()
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return err
+		}
+
+		if info, err := os.Stat(guardPath); err == nil && time.Since(info.ModTime()) > guardTimeout {
+			_ = os.Remove(guardPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for the guard of lock %s", key)
+		}
+		time.Sleep(guardRetryInterval)
+	}
+}
+
+func (f *FileBackend) remove(key string) error {
+	if err := os.Remove(f.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (f *FileBackend) read(key string) (*Lock, error) {
+	data, err := os.ReadFile(f.path(key))
+	if err != nil {
+		return nil, err
+	}
+
+	var lock Lock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("error decoding lock file: %w", err)
+	}
+	return &lock, nil
+}
+
+func (f *FileBackend) path(key string) string {
+	return filepath.Join(f.Dir, filepath.Base(key)+".lock")
+}
@@ -0,0 +1,185 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultLeaseDuration = 15 * time.Minute
+
+	retryInterval = 5 * time.Second
+)
+
+var ErrLockHeld = errors.New("lock is held by another run")
+
+// ErrLockLost fails the renewal of a lock another run took over
+var ErrLockLost = errors.New("lock is no longer owned")
+
+type Lock struct {
+	Key        string    `json:"key"`
+	Owner      string    `json:"owner"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+func (l Lock) expired(now time.Time) bool {
+	return now.After(l.ExpiresAt)
+}
+
+type Backend interface {
+	// TryAcquire takes the lock if it is free, expired or already owned by lock.Owner,
+	// otherwise it returns the lock of the current holder.
+	TryAcquire(ctx context.Context, lock Lock) (*Lock, error)
+	Renew(ctx context.Context, lock Lock) error
+	Release(ctx context.Context, lock Lock) error
+	ForceUnlock(ctx context.Context, key string) error
+}
+
+type Lease struct {
+	backend       Backend
+	leaseDuration time.Duration
+	logger        *slog.Logger
+
+	mu   sync.Mutex
+	lock Lock
+	stop chan struct{}
+	done chan struct{}
+	lost chan struct{}
+	err  error
+}
+
+func Acquire(ctx context.Context, backend Backend, key string, leaseDuration time.Duration, timeout time.Duration) (*Lease, error) {
+	if leaseDuration <= 0 {
+		leaseDuration = DefaultLeaseDuration
+	}
+
+	owner := newOwner()
+	deadline := time.Now().Add(timeout)
+	for {
+		now := time.Now()
+		lock := Lock{
+			Key:        key,
+			Owner:      owner,
+			AcquiredAt: now,
+			ExpiresAt:  now.Add(leaseDuration),
+		}
+
+		holder, err := backend.TryAcquire(ctx, lock)
+		if err != nil {
+			return nil, fmt.Errorf("error acquiring lock %s: %w", key, err)
+		}
+
+		if holder == nil {
+			lease := &Lease{
+				backend:       backend,
+				leaseDuration: leaseDuration,
+				logger:        logging.FromContext(ctx),
+				lock:          lock,
+				stop:          make(chan struct{}),
+				done:          make(chan struct{}),
+				lost:          make(chan struct{}),
+			}
+			go lease.renew()
+			return lease, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("%w: %s is held by %s until %s", ErrLockHeld, key, holder.Owner, holder.ExpiresAt.Format(time.RFC3339))
+		}
+
+		wait := retryInterval
+		if remaining < wait {
+			wait = remaining
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (l *Lease) Release(ctx context.Context) error {
+	close(l.stop)
+	<-l.done
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.backend.Release(ctx, l.lock); err != nil {
+		return fmt.Errorf("error releasing lock %s: %w", l.lock.Key, err)
+	}
+	return nil
+}
+
+// Lost is closed when the lease can't be renewed anymore, another run may hold the lock from then on
+func (l *Lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Err tells why the lease was lost
+func (l *Lease) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// renew extends the lease every third of its duration. A failed renewal is retried until the lease expires, the
+// lease is lost once it expired or another run took the lock over.
+func (l *Lease) renew() {
+	defer close(l.done)
+
+	timer := time.NewTimer(l.leaseDuration / 3)
+	defer timer.Stop()
+
+	l.mu.Lock()
+	expiresAt := l.lock.ExpiresAt
+	l.mu.Unlock()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-timer.C:
+		}
+
+		l.mu.Lock()
+		l.lock.ExpiresAt = time.Now().Add(l.leaseDuration)
+		err := l.backend.Renew(context.Background(), l.lock)
+		if err == nil {
+			expiresAt = l.lock.ExpiresAt
+		}
+		key := l.lock.Key
+		l.mu.Unlock()
+
+		if err == nil {
+			timer.Reset(l.leaseDuration / 3)
+			continue
+		}
+
+		l.logger.Error("error renewing lock", "key", key, "expiresAt", expiresAt, logging.Err(err))
+		if errors.Is(err, ErrLockLost) || !time.Now().Add(retryInterval).Before(expiresAt) {
+			l.mu.Lock()
+			l.err = fmt.Errorf("error renewing lock %s: %w", key, err)
+			l.mu.Unlock()
+			close(l.lost)
+			return
+		}
+		timer.Reset(retryInterval)
+	}
+}
+
+func newOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s:%d:%d", hostname, os.Getpid(), time.Now().UnixNano())
+}
@@ -0,0 +1,222 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestBackend(t *testing.T) *FileBackend {
+	t.Helper()
+	backend, err := NewFileBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileBackend() error = %v", err)
+	}
+	return backend
+}
+
+func release(t *testing.T, lease *Lease) {
+	t.Helper()
+	if err := lease.Release(context.Background()); err != nil {
+		t.Errorf("Release() error = %v", err)
+	}
+}
+
+func TestTryAcquire(t *testing.T) {
+	now := time.Now()
+	held := Lock{Key: "my-app", Owner: "holder", AcquiredAt: now, ExpiresAt: now.Add(time.Hour)}
+	expired := Lock{Key: "my-app", Owner: "holder", AcquiredAt: now.Add(-time.Hour), ExpiresAt: now.Add(-time.Minute)}
+
+	tests := []struct {
+		name     string
+		existing *Lock
+		owner    string
+		wantHeld bool
+	}{
+		{name: "free", owner: "run"},
+		{name: "held", existing: &held, owner: "run", wantHeld: true},
+		{name: "held by the same owner", existing: &held, owner: "holder"},
+		{name: "expired", existing: &expired, owner: "run"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			backend := newTestBackend(t)
+			if test.existing != nil {
+				if holder, err := backend.TryAcquire(ctx, *test.existing); holder != nil || err != nil {
+					t.Fatalf("TryAcquire() of the existing lock = %v, %v", holder, err)
+				}
+			}
+
+			lock := Lock{Key: "my-app", Owner: test.owner, AcquiredAt: now, ExpiresAt: now.Add(time.Hour)}
+			holder, err := backend.TryAcquire(ctx, lock)
+			if err != nil {
+				t.Fatalf("TryAcquire() error = %v", err)
+			}
+			if got := holder != nil; got != test.wantHeld {
+				t.Fatalf("TryAcquire() held = %v, want %v", got, test.wantHeld)
+			}
+			if test.wantHeld && holder.Owner != test.existing.Owner {
+				t.Errorf("TryAcquire() holder = %s, want %s", holder.Owner, test.existing.Owner)
+			}
+		})
+	}
+}
+
+func TestAcquire(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestBackend(t)
+
+	lease, err := Acquire(ctx, backend, "my-app", time.Hour, 0)
+	if err != nil {
+		t.Fatalf("Acquire() of a free lock error = %v", err)
+	}
+	defer release(t, lease)
+
+	start := time.Now()
+	_, err = Acquire(ctx, backend, "my-app", time.Hour, 200*time.Millisecond)
+	if !errors.Is(err, ErrLockHeld) {
+		t.Errorf("Acquire() of a held lock error = %v, want %v", err, ErrLockHeld)
+	}
+	if waited := time.Since(start); waited < 200*time.Millisecond {
+		t.Errorf("Acquire() of a held lock gave up after %v, want at least the lock timeout", waited)
+	}
+}
+
+func TestAcquireExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestBackend(t)
+	now := time.Now()
+	stale := Lock{Key: "my-app", Owner: "crashed", AcquiredAt: now.Add(-time.Hour), ExpiresAt: now.Add(-time.Minute)}
+	if holder, err := backend.TryAcquire(ctx, stale); holder != nil || err != nil {
+		t.Fatalf("TryAcquire() of the stale lock = %v, %v", holder, err)
+	}
+
+	lease, err := Acquire(ctx, backend, "my-app", time.Hour, 0)
+	if err != nil {
+		t.Fatalf("Acquire() of an expired lock error = %v", err)
+	}
+	defer release(t, lease)
+
+	holder, err := backend.read("my-app")
+	if err != nil {
+		t.Fatalf("read() error = %v", err)
+	}
+	if holder.Owner == stale.Owner {
+		t.Error("expired lock wasn't taken over")
+	}
+}
+
+func TestLeaseRenew(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestBackend(t)
+
+	lease, err := Acquire(ctx, backend, "my-app", 300*time.Millisecond, 0)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer release(t, lease)
+	acquired, err := backend.read("my-app")
+	if err != nil {
+		t.Fatalf("read() error = %v", err)
+	}
+
+	// the lease is renewed every third of its duration, so it outlives the duration it was acquired with
+	time.Sleep(400 * time.Millisecond)
+	renewed, err := backend.read("my-app")
+	if err != nil {
+		t.Fatalf("read() error = %v", err)
+	}
+	if !renewed.ExpiresAt.After(acquired.ExpiresAt) {
+		t.Errorf("lease expires at %v after renewing, want after %v", renewed.ExpiresAt, acquired.ExpiresAt)
+	}
+	select {
+	case <-lease.Lost():
+		t.Fatalf("lease lost: %v", lease.Err())
+	default:
+	}
+}
+
+func TestLeaseLost(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestBackend(t)
+
+	lease, err := Acquire(ctx, backend, "my-app", 300*time.Millisecond, 0)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if err := backend.ForceUnlock(ctx, "my-app"); err != nil {
+		t.Fatalf("ForceUnlock() error = %v", err)
+	}
+
+	select {
+	case <-lease.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lease of a removed lock wasn't lost")
+	}
+	if !errors.Is(lease.Err(), ErrLockLost) {
+		t.Errorf("Err() = %v, want %v", lease.Err(), ErrLockLost)
+	}
+	release(t, lease)
+}
+
+func TestRelease(t *testing.T) {
+	now := time.Now()
+	owned := Lock{Key: "my-app", Owner: "run", AcquiredAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	tests := []struct {
+		name       string
+		owner      string
+		wantErr    bool
+		wantLocked bool
+	}{
+		{name: "by the owner", owner: "run", wantErr: false, wantLocked: false},
+		{name: "by another run", owner: "other", wantErr: true, wantLocked: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			backend := newTestBackend(t)
+			if holder, err := backend.TryAcquire(ctx, owned); holder != nil || err != nil {
+				t.Fatalf("TryAcquire() = %v, %v", holder, err)
+			}
+
+			releasing := owned
+			releasing.Owner = test.owner
+			if err := backend.Release(ctx, releasing); (err != nil) != test.wantErr {
+				t.Errorf("Release() error = %v, want error %v", err, test.wantErr)
+			}
+
+			_, err := backend.read("my-app")
+			if locked := err == nil; locked != test.wantLocked {
+				t.Errorf("locked after Release() = %v, want %v", locked, test.wantLocked)
+			}
+		})
+	}
+}
+
+func TestForceUnlock(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestBackend(t)
+	now := time.Now()
+	held := Lock{Key: "my-app", Owner: "stuck", AcquiredAt: now, ExpiresAt: now.Add(time.Hour)}
+	if holder, err := backend.TryAcquire(ctx, held); holder != nil || err != nil {
+		t.Fatalf("TryAcquire() = %v, %v", holder, err)
+	}
+
+	if err := backend.ForceUnlock(ctx, "my-app"); err != nil {
+		t.Fatalf("ForceUnlock() error = %v", err)
+	}
+	if err := backend.ForceUnlock(ctx, "my-app"); err != nil {
+		t.Errorf("ForceUnlock() of a free lock error = %v", err)
+	}
+
+	lease, err := Acquire(ctx, backend, "my-app", time.Hour, 0)
+	if err != nil {
+		t.Fatalf("Acquire() after ForceUnlock() error = %v", err)
+	}
+	release(t, lease)
+}
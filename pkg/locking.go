@@ -0,0 +1,90 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/lock"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func newLockBackend(ctx context.Context, scalingConfig *config.ScalingConfig, awsConfig AWSConfigProvider) (lock.Backend, error) {
+	lockConfig := scalingConfig.Lock
+	if lockConfig == nil {
+		lockConfig = &config.LockConfig{}
+	}
+
+	if lockConfig.Backend != config.DynamoDBLockBackend {
+		path := lockConfig.Path
+		if path == "" {
+			path = filepath.Join(os.TempDir(), "aws-infra-scaler-locks")
+		}
+		return lock.NewFileBackend(path)
+	}
+
+	region := lockConfig.Region
+	if region == "" {
+		region = scalingConfig.DefaultRegion()
+	}
+
+	awsCreds, err := awsConfig(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	return lock.NewDynamoDBBackend(service.NewDynamoDBClient(awsCreds), lockConfig.TableName), nil
+}
+
+func acquireAppLock(ctx context.Context, scalingConfig *config.ScalingConfig, awsConfig AWSConfigProvider, timeout time.Duration) (*lock.Lease, error) {
+	if scalingConfig.Name == "" {
+		return nil, errors.New("no app name provided, it is required to lock the app")
+	}
+
+	backend, err := newLockBackend(ctx, scalingConfig, awsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var leaseDuration time.Duration
+	if scalingConfig.Lock != nil {
+		leaseDuration = scalingConfig.Lock.LeaseDuration
+	}
+	return lock.Acquire(ctx, backend, scalingConfig.Name, leaseDuration, timeout)
+}
+
+// cancelOnLockLost cancels the run once the lease of the app lock is lost, as another run may scale the app from then on
+func cancelOnLockLost(ctx context.Context, lease *lock.Lease, cancel context.CancelFunc) {
+	if lease == nil {
+		return
+	}
+
+	go func() {
+		select {
+		case <-lease.Lost():
+			logging.FromContext(ctx).Error("lost the app lock, canceling the run", logging.Err(lease.Err()))
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+}
+
+func ForceUnlock(configPath string) error {
+	scalingConfig, err := config.ReadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if scalingConfig.Name == "" {
+		return errors.New("no app name provided")
+	}
+
+	ctx := context.Background()
+	backend, err := newLockBackend(ctx, scalingConfig, AssumeRoleConfigProvider(scalingConfig.AssumedRoleArn))
+	if err != nil {
+		return err
+	}
+	return backend.ForceUnlock(ctx, scalingConfig.Name)
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+const (
+	TextFormat = "text"
+	JSONFormat = "json"
+)
+
+const (
+	AppKey        = "app"
+	ProfileKey    = "profile"
+	RunIdKey      = "run_id"
+	RegionKey     = "region"
+	ServiceKey    = "service"
+	IdentifierKey = "identifier"
+	ErrorKey      = "error"
+)
+
+type loggerKey struct{}
+
+// Init replaces the default logger, which the standard log package also writes through
+func Init(w io.Writer, level string, format string) error {
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %s, expected debug, info, warn or error", level)
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: logLevel}
+	var handler slog.Handler
+	switch format {
+	case TextFormat:
+		handler = slog.NewTextHandler(w, handlerOptions)
+	case JSONFormat:
+		handler = slog.NewJSONHandler(w, handlerOptions)
+	default:
+		return fmt.Errorf("invalid log format %s, expected %s or %s", format, TextFormat, JSONFormat)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// With returns a context whose logger adds the attributes to every line
+func With(ctx context.Context, args ...any) context.Context {
+	return context.WithValue(ctx, loggerKey{}, FromContext(ctx).With(args...))
+}
+
+// NewContext returns a context whose logger is the given logger
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger of the context, or the default logger when it has none
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+func Err(err error) slog.Attr {
+	return slog.Any(ErrorKey, err)
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"net/http"
+	"time"
+)
+
+const (
+	namespace = "aws_infra_scaler"
+
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeTimeout = "timeout"
+)
+
+var (
+	Registry = prometheus.NewRegistry()
+
+	scalingOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scaling_operations_total",
+		Help:      "Number of resources scaled by service, region and outcome.",
+	}, []string{"app", "region", "service", "outcome"})
+
+	scalingDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scaling_operation_duration_seconds",
+		Help:      "Duration of scaling a resource.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"app", "region", "service"})
+
+	apiRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "aws_api_retries_total",
+		Help:      "Number of retried AWS API calls by service and region.",
+	}, []string{"region", "service", "operation"})
+
+	currentCapacity = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "capacity_current",
+		Help:      "Live capacity of a resource when it was last planned.",
+	}, []string{"app", "region", "service", "identifier", "dimension"})
+
+	targetCapacity = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "capacity_target",
+		Help:      "Target capacity of a resource when it was last planned.",
+	}, []string{"app", "region", "service", "identifier", "dimension"})
+
+	lastRun = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_run_timestamp_seconds",
+		Help:      "Time the last scaling run of an app finished by profile and outcome.",
+	}, []string{"app", "profile", "outcome"})
+)
+
+func init() {
+	Registry.MustRegister(scalingOperations, scalingDuration, apiRetries, currentCapacity, targetCapacity, lastRun)
+}
+
+func ObserveScaling(app string, region string, service string, duration time.Duration, outcome string) {
+	scalingOperations.WithLabelValues(app, region, service, outcome).Inc()
+	scalingDuration.WithLabelValues(app, region, service).Observe(duration.Seconds())
+}
+
+func SetCapacity(app string, region string, service string, identifier string, current map[string]int, target map[string]int) {
+	for dimension, value := range current {
+		currentCapacity.WithLabelValues(app, region, service, identifier, dimension).Set(float64(value))
+	}
+	for dimension, value := range target {
+		targetCapacity.WithLabelValues(app, region, service, identifier, dimension).Set(float64(value))
+	}
+}
+
+func ObserveRun(app string, profile string, outcome string) {
+	lastRun.WithLabelValues(app, profile, outcome).SetToCurrentTime()
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Push replaces the metrics of the app on a Pushgateway compatible endpoint
+func Push(url string, app string) error {
+	err := push.New(url, "aws-infra-scaler").
+		Gatherer(Registry).
+		Grouping("instance", app).
+		Push()
+	if err != nil {
+		return fmt.Errorf("error pushing metrics: %w", err)
+	}
+	return nil
+}
+
+// InstrumentAPI counts the retries of the AWS API calls made with the config
+func InstrumentAPI(cfg *aws.Config) {
+	cfg.APIOptions = append(cfg.APIOptions, func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("MetricsRetries", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			if results, ok := retry.GetAttemptResults(metadata); ok && len(results.Results) > 1 {
+				apiRetries.WithLabelValues(awsmiddleware.GetRegion(ctx), awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx)).Add(float64(len(results.Results) - 1))
+			}
+			return out, metadata, err
+		}), middleware.After)
+	})
+}
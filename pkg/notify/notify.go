@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/audit"
+	"text/template"
+)
+
+// Message is the data of the message templates, the record lists the planned capacity of every resource
+// on start and the capacity it was scaled to, or the error scaling it, once the run finished
+type Message struct {
+	Event string `json:"event"`
+	audit.Record
+}
+
+type Sink interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// DefaultTemplate renders the message of sinks without a template of their own
+const DefaultTemplate = `{{if eq .Event "start"}}Scaling app {{.AppName}} to profile {{.Profile}} started{{else}}Scaling app {{.AppName}} to profile {{.Profile}} {{.Outcome}}{{end}} (run {{.RunId}})
+{{range .Resources}}{{.Region}} {{.ServiceName}} {{.IdentifierId}}{{if .Error}}: error: {{.Error}}{{else}}{{range $name, $value := .After}} {{$name}}={{$value}}{{end}}{{end}}
+{{end}}{{range .Errors}}{{.Region}} {{.ServiceName}} {{.IdentifierId}}: error: {{.Error}}
+{{end}}`
+
+var defaultTemplate = template.Must(template.New("default").Parse(DefaultTemplate))
+
+// ParseTemplate parses a message template, an empty text returns nil
+func ParseTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New("notification").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing notification template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func render(tmpl *template.Template, message Message) (string, error) {
+	if tmpl == nil {
+		tmpl = defaultTemplate
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, message); err != nil {
+		return "", fmt.Errorf("error rendering notification: %w", err)
+	}
+	return buf.String(), nil
+}
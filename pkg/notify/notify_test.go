@@ -0,0 +1,201 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/audit"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func testMessage(event string, outcome string) Message {
+	record := audit.Record{
+		RunId:   "run-1",
+		AppName: "orders",
+		Profile: "peak",
+		Outcome: outcome,
+		Resources: []audit.ResourceRecord{
+			{Region: "us-east-1", ServiceName: "ec2", IdentifierId: "orders-asg", Before: map[string]int{"desiredCount": 2}, After: map[string]int{"desiredCount": 4}},
+		},
+	}
+	if outcome == audit.OutcomeFailed {
+		record.Resources[0].After = nil
+		record.Resources[0].Error = "throttled"
+		record.Errors = []audit.ErrorRecord{{Region: "eu-west-1", ServiceName: "kinesis", IdentifierId: "orders-stream", Error: "not planned"}}
+	}
+	return Message{Event: event, Record: record}
+}
+
+func TestSNSSubject(t *testing.T) {
+	tests := []struct {
+		name    string
+		appName string
+		want    string
+	}{
+		{name: "short", appName: "orders", want: "aws-infra-scaler: orders peak finish"},
+		{
+			name:    "cut at the limit",
+			appName: strings.Repeat("a", 100),
+			want:    "aws-infra-scaler: " + strings.Repeat("a", 82),
+		},
+		{
+			name:    "multi-byte characters are kept whole",
+			appName: strings.Repeat("é", 80) + "注文",
+			want:    "aws-infra-scaler: " + strings.Repeat("é", 80) + "注文",
+		},
+		{
+			name:    "multi-byte characters cut at the limit",
+			appName: strings.Repeat("注", 100),
+			want:    "aws-infra-scaler: " + strings.Repeat("注", 82),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message := testMessage("finish", audit.OutcomeSucceeded)
+			message.AppName = test.appName
+
+			got := snsSubject(message)
+			if got != test.want {
+				t.Errorf("snsSubject() = %q, want %q", got, test.want)
+			}
+			if !utf8.ValidString(got) || utf8.RuneCountInString(got) > maxSubjectLength {
+				t.Errorf("snsSubject() = %q has %d characters, want a valid subject of at most %d", got, utf8.RuneCountInString(got), maxSubjectLength)
+			}
+		})
+	}
+}
+
+func TestRenderDefaultTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		message Message
+		want    string
+	}{
+		{
+			name:    "start",
+			message: testMessage("start", ""),
+			want:    "Scaling app orders to profile peak started (run run-1)\nus-east-1 ec2 orders-asg desiredCount=4\n",
+		},
+		{
+			name:    "finish",
+			message: testMessage("finish", audit.OutcomeSucceeded),
+			want:    "Scaling app orders to profile peak succeeded (run run-1)\nus-east-1 ec2 orders-asg desiredCount=4\n",
+		},
+		{
+			name:    "failure",
+			message: testMessage("failure", audit.OutcomeFailed),
+			want:    "Scaling app orders to profile peak failed (run run-1)\nus-east-1 ec2 orders-asg: error: throttled\neu-west-1 kinesis orders-stream: error: not planned\n",
+		},
+		{
+			name:    "aborted",
+			message: testMessage("failure", audit.OutcomeAborted),
+			want:    "Scaling app orders to profile peak aborted (run run-1)\nus-east-1 ec2 orders-asg desiredCount=4\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := render(nil, test.message)
+			if err != nil {
+				t.Fatalf("render() error = %v", err)
+			}
+			if got != test.want {
+				t.Errorf("render() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("{{.AppName}} {{.Event}} {{.Outcome}}{{range .Resources}} {{.IdentifierId}} {{.Before}} -> {{.After}}{{end}}")
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+
+	got, err := render(tmpl, testMessage("finish", audit.OutcomeSucceeded))
+	if err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	if want := "orders finish succeeded orders-asg map[desiredCount:2] -> map[desiredCount:4]"; got != want {
+		t.Errorf("render() = %q, want %q", got, want)
+	}
+
+	if _, err := ParseTemplate("{{.AppName"); err == nil {
+		t.Error("ParseTemplate() of an invalid template succeeded")
+	}
+	if tmpl, err := ParseTemplate(""); tmpl != nil || err != nil {
+		t.Errorf("ParseTemplate(\"\") = %v, %v, want nil", tmpl, err)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	tests := []struct {
+		name            string
+		template        string
+		status          int
+		wantContentType string
+		wantErr         bool
+	}{
+		{name: "json", wantContentType: "application/json", status: http.StatusOK},
+		{name: "template", template: "{{.AppName}} {{.Outcome}}", wantContentType: "text/plain; charset=utf-8", status: http.StatusNoContent},
+		{name: "error status", status: http.StatusInternalServerError, wantContentType: "application/json", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var contentType, authorization string
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
+				authorization = r.Header.Get("Authorization")
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			tmpl, err := ParseTemplate(test.template)
+			if err != nil {
+				t.Fatalf("ParseTemplate() error = %v", err)
+			}
+			sink := NewWebhookSink(server.URL, map[string]string{"Authorization": "Bearer token"}, tmpl)
+			err = sink.Send(context.Background(), testMessage("finish", audit.OutcomeSucceeded))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Send() error = %v, want error %v", err, test.wantErr)
+			}
+
+			if contentType != test.wantContentType || authorization != "Bearer token" {
+				t.Errorf("request headers = %q, %q, want %q and the configured headers", contentType, authorization, test.wantContentType)
+			}
+			if test.template != "" {
+				if string(body) != "orders succeeded" {
+					t.Errorf("request body = %q, want the rendered template", body)
+				}
+				return
+			}
+			var message Message
+			if err := json.Unmarshal(body, &message); err != nil || message.Event != "finish" || message.AppName != "orders" {
+				t.Errorf("request body = %s, %v, want the message as JSON", body, err)
+			}
+		})
+	}
+}
+
+func TestSlackSink(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	if err := NewSlackSink(server.URL, nil).Send(context.Background(), testMessage("start", "")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if want := "Scaling app orders to profile peak started (run run-1)\nus-east-1 ec2 orders-asg desiredCount=4\n"; payload["text"] != want {
+		t.Errorf("slack text = %q, want %q", payload["text"], want)
+	}
+}
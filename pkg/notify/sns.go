@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"text/template"
+	"unicode/utf8"
+)
+
+// subjects of SNS messages are limited to 100 characters
+const maxSubjectLength = 100
+
+type SNSSink struct {
+	Client   *sns.Client
+	TopicArn string
+	Template *template.Template
+}
+
+func NewSNSSink(client *sns.Client, topicArn string, tmpl *template.Template) *SNSSink {
+	return &SNSSink{
+		Client:   client,
+		TopicArn: topicArn,
+		Template: tmpl,
+	}
+}
+
+func (s *SNSSink) Send(ctx context.Context, message Message) error {
+	text, err := render(s.Template, message)
+	if err != nil {
+		return err
+	}
+
+	subject := snsSubject(message)
+	_, err = s.Client.Publish(ctx, &sns.PublishInput{
+		TopicArn: &s.TopicArn,
+		Subject:  &subject,
+		Message:  &text,
+	})
+	if err != nil {
+		return fmt.Errorf("error publishing notification: %w", err)
+	}
+	return nil
+}
+
+// snsSubject names the app, profile and event of the message, cut to the subject limit without splitting a character
+func snsSubject(message Message) string {
+	subject := fmt.Sprintf("aws-infra-scaler: %s %s %s", message.AppName, message.Profile, message.Event)
+	if utf8.RuneCountInString(subject) > maxSubjectLength {
+		subject = string([]rune(subject)[:maxSubjectLength])
+	}
+	return subject
+}
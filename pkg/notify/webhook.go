@@ -0,0 +1,108 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+	"time"
+)
+
+const requestTimeout = 10 * time.Second
+
+// WebhookSink posts the message as JSON, or the rendered template when it has one
+type WebhookSink struct {
+	URL      string
+	Headers  map[string]string
+	Template *template.Template
+
+	client *http.Client
+}
+
+func NewWebhookSink(url string, headers map[string]string, tmpl *template.Template) *WebhookSink {
+	return &WebhookSink{
+		URL:      url,
+		Headers:  headers,
+		Template: tmpl,
+		client:   &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (w *WebhookSink) Send(ctx context.Context, message Message) error {
+	var body []byte
+	contentType := "application/json"
+	if w.Template != nil {
+		text, err := render(w.Template, message)
+		if err != nil {
+			return err
+		}
+		body = []byte(text)
+		contentType = "text/plain; charset=utf-8"
+	} else {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return fmt.Errorf("error encoding notification: %w", err)
+		}
+		body = data
+	}
+
+	headers := map[string]string{"Content-Type": contentType}
+	for name, value := range w.Headers {
+		headers[name] = value
+	}
+	return post(ctx, w.client, w.URL, headers, body)
+}
+
+// SlackSink posts the rendered message to a Slack compatible incoming webhook
+type SlackSink struct {
+	URL      string
+	Template *template.Template
+
+	client *http.Client
+}
+
+func NewSlackSink(url string, tmpl *template.Template) *SlackSink {
+	return &SlackSink{
+		URL:      url,
+		Template: tmpl,
+		client:   &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (s *SlackSink) Send(ctx context.Context, message Message) error {
+	text, err := render(s.Template, message)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("error encoding notification: %w", err)
+	}
+	return post(ctx, s.client, s.URL, map[string]string{"Content-Type": "application/json"}, body)
+}
+
+func post(ctx context.Context, client *http.Client, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating notification request: %w", err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending notification: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("error sending notification: unexpected status %s", resp.Status)
+	}
+	return nil
+}
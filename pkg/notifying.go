@@ -0,0 +1,91 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/audit"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/notify"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"time"
+)
+
+func newNotificationSink(ctx context.Context, scalingConfig *config.ScalingConfig, awsConfig AWSConfigProvider, notificationConfig config.NotificationConfig) (notify.Sink, error) {
+	tmpl, err := notify.ParseTemplate(notificationConfig.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	switch notificationConfig.Sink {
+	case config.WebhookNotificationSink:
+		return notify.NewWebhookSink(notificationConfig.URL, notificationConfig.Headers, tmpl), nil
+	case config.SlackNotificationSink:
+		return notify.NewSlackSink(notificationConfig.URL, tmpl), nil
+	case config.SNSNotificationSink:
+		region := notificationConfig.Region
+		if region == "" {
+			region = scalingConfig.DefaultRegion()
+		}
+
+		awsCreds, err := awsConfig(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+		return notify.NewSNSSink(service.NewSNSClient(awsCreds), notificationConfig.TopicArn, tmpl), nil
+	default:
+		return nil, fmt.Errorf("notification sink %s is not supported", notificationConfig.Sink)
+	}
+}
+
+// notificationSink is a sink built for a notification config of the app
+type notificationSink struct {
+	config config.NotificationConfig
+	sink   notify.Sink
+}
+
+// notificationSinks builds the sinks of the app once per run, SNS sinks need aws credentials of the role of the app
+func (s *ScalingPlan) notificationSinks(ctx context.Context) []notificationSink {
+	s.sinksOnce.Do(func() {
+		for _, notificationConfig := range s.ScalingConfig.Notifications {
+			sink, err := newNotificationSink(ctx, s.ScalingConfig, s.scaler.awsConfig, notificationConfig)
+			if err != nil {
+				logging.FromContext(ctx).Error("error creating notification sink", "sink", notificationConfig.Sink, logging.Err(err))
+				continue
+			}
+			s.sinks = append(s.sinks, notificationSink{config: notificationConfig, sink: sink})
+		}
+	})
+	return s.sinks
+}
+
+func notifyStart(ctx context.Context, scalingPlan *ScalingPlan) {
+	record := newAuditRecord(scalingPlan, scalingPlan.FailedServices, "")
+	record.FinishedAt = time.Time{}
+	sendNotifications(ctx, scalingPlan, notify.Message{Event: config.StartEvent, Record: record})
+}
+
+// notifyFinish notifies the outcome of the run, a run without changes didn't notify its start and only notifies failures
+func notifyFinish(ctx context.Context, scalingPlan *ScalingPlan, failedServices []*service.ScalingError, outcome string) {
+	if !scalingPlan.HasChanges() && outcome == audit.OutcomeSucceeded {
+		return
+	}
+
+	event := config.FinishEvent
+	if outcome != audit.OutcomeSucceeded {
+		event = config.FailureEvent
+	}
+	sendNotifications(ctx, scalingPlan, notify.Message{Event: event, Record: newAuditRecord(scalingPlan, failedServices, outcome)})
+}
+
+func sendNotifications(ctx context.Context, scalingPlan *ScalingPlan, message notify.Message) {
+	for _, sink := range scalingPlan.notificationSinks(ctx) {
+		if !sink.config.Notifies(message.Event) {
+			continue
+		}
+
+		if err := sink.sink.Send(ctx, message); err != nil {
+			logging.FromContext(ctx).Error("error sending notification", "sink", sink.config.Sink, "event", message.Event, logging.Err(err))
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/audit"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/notify"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"reflect"
+	"testing"
+)
+
+type recordingSink struct {
+	events []string
+}
+
+func (r *recordingSink) Send(ctx context.Context, message notify.Message) error {
+	r.events = append(r.events, message.Event)
+	return nil
+}
+
+func TestNotifyFinish(t *testing.T) {
+	failed := []*service.ScalingError{{Region: "us-east-1", ServiceName: string(service.EC2), IdentifierId: "my-asg", Err: errors.New("throttled")}}
+
+	tests := []struct {
+		name           string
+		target         int
+		failedServices []*service.ScalingError
+		outcome        string
+		want           []string
+	}{
+		{name: "scaled", target: 2, outcome: audit.OutcomeSucceeded, want: []string{config.FinishEvent}},
+		{name: "scaling failed", target: 2, failedServices: failed, outcome: audit.OutcomeFailed, want: []string{config.FailureEvent}},
+		{name: "no changes", target: 1, outcome: audit.OutcomeSucceeded, want: nil},
+		{name: "no changes but planning failed", target: 1, failedServices: failed, outcome: audit.OutcomeFailed, want: []string{config.FailureEvent}},
+		{name: "no changes but aborted", target: 1, outcome: audit.OutcomeAborted, want: []string{config.FailureEvent}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sink := &recordingSink{}
+			scalingPlan := &ScalingPlan{
+				RunId:         "run",
+				ScalingConfig: &config.ScalingConfig{Name: "my-app"},
+				Resources: []*service.ResourcePlan{{
+					Region:       "us-east-1",
+					ServiceName:  string(service.EC2),
+					IdentifierId: "my-asg",
+					Current:      service.Capacity{"desiredCount": 1},
+					Target:       service.Capacity{"desiredCount": test.target},
+				}},
+			}
+			scalingPlan.sinksOnce.Do(func() {
+				scalingPlan.sinks = []notificationSink{{config: config.NotificationConfig{Sink: config.WebhookNotificationSink}, sink: sink}}
+			})
+
+			notifyFinish(context.Background(), scalingPlan, test.failedServices, test.outcome)
+			if !reflect.DeepEqual(sink.events, test.want) {
+				t.Errorf("notifyFinish() sent %v, want %v", sink.events, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"log/slog"
+	"time"
+)
+
+// AWSConfigProvider returns the AWS config to call the services of a region with
+type AWSConfigProvider func(ctx context.Context, region string) (*aws.Config, error)
+
+// AssumeRoleConfigProvider assumes the role in every region, it is the provider of scalers built without one
+func AssumeRoleConfigProvider(assumeRoleArn string) AWSConfigProvider {
+	return func(ctx context.Context, region string) (*aws.Config, error) {
+		return service.NewConfig(ctx, region, assumeRoleArn)
+	}
+}
+
+// Hooks are called around the scaling of every resource, after the pre and before the post hooks of the config.
+// An error of BeforeScale skips the resource and fails it with the error.
+type Hooks struct {
+	BeforeScale func(ctx context.Context, resourcePlan *service.ResourcePlan) error
+	AfterScale  func(ctx context.Context, resourcePlan *service.ResourcePlan, errs []*service.ScalingError)
+}
+
+type Option func(s *Scaler) error
+
+func WithConfig(scalingConfig *config.ScalingConfig) Option {
+	return func(s *Scaler) error {
+		s.config = scalingConfig
+		return nil
+	}
+}
+
+func WithConfigFile(configPath string) Option {
+	return func(s *Scaler) error {
+		scalingConfig, err := config.ReadConfig(configPath)
+		if err != nil {
+			return err
+		}
+		s.config = scalingConfig
+		return nil
+	}
+}
+
+func WithAWSConfigProvider(provider AWSConfigProvider) Option {
+	return func(s *Scaler) error {
+		s.awsConfig = provider
+		return nil
+	}
+}
+
+// WithLogger sets the logger of the runs, the default logger is used without it
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Scaler) error {
+		s.logger = logger
+		return nil
+	}
+}
+
+func WithHooks(hooks Hooks) Option {
+	return func(s *Scaler) error {
+		s.hooks = hooks
+		return nil
+	}
+}
+
+// WithConcurrency limits how many resources are described or scaled at once, 0 doesn't limit them
+func WithConcurrency(concurrency int) Option {
+	return func(s *Scaler) error {
+		if concurrency < 0 {
+			return errors.New("concurrency must not be negative")
+		}
+		s.concurrency = concurrency
+		return nil
+	}
+}
+
+// WithForce scales even if guardrails are violated
+func WithForce(force bool) Option {
+	return func(s *Scaler) error {
+		s.options.Force = force
+		return nil
+	}
+}
+
+// WithLockTimeout sets how long a run waits for the app lock held by another run
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(s *Scaler) error {
+		s.options.LockTimeout = timeout
+		return nil
+	}
+}
+
+// WithTTL reverts scale-ups after the duration
+func WithTTL(ttl time.Duration) Option {
+	return func(s *Scaler) error {
+		s.options.TTL = ttl
+		return nil
+	}
+}
+
+// WithTimeout bounds a run from the start of planning to the end of scaling, overriding the timeout of the config
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Scaler) error {
+		if timeout < 0 {
+			return errors.New("timeout must not be negative")
+		}
+		s.options.Timeout = timeout
+		return nil
+	}
+}
+
+// WithResume resumes the run, skipping the resources it completed
+func WithResume(runId string) Option {
+	return func(s *Scaler) error {
+		s.options.Resume = runId
+		return nil
+	}
+}
+
+func withScaleOptions(options ScaleOptions) Option {
+	return func(s *Scaler) error {
+		s.options = options
+		return nil
+	}
+}
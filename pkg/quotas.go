@@ -0,0 +1,202 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/quotas"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// errInstanceTypeUnknown leaves auto scaling groups out of the vCPU quota, as their vCPUs can't be told
+var errInstanceTypeUnknown = errors.New("instance type of auto scaling group unknown")
+
+// checkQuotas flags the scale-ups that would exceed the service quotas of their region before anything is scaled,
+// and returns the scale-ups whose quotas couldn't be queried apart, so a missing permission doesn't block the run.
+func (s *Scaler) checkQuotas(ctx context.Context, resourcePlans []*service.ResourcePlan) ([]*service.ScalingError, []*service.ScalingError) {
+	var regionOrder []string
+	increasing := make(map[string][]*service.ResourcePlan)
+	for _, resourcePlan := range resourcePlans {
+		if !isIncrease(resourcePlan) {
+			continue
+		}
+		if _, ok := increasing[resourcePlan.Region]; !ok {
+			regionOrder = append(regionOrder, resourcePlan.Region)
+		}
+		increasing[resourcePlan.Region] = append(increasing[resourcePlan.Region], resourcePlan)
+	}
+
+	var violations, unchecked []*service.ScalingError
+	for _, region := range regionOrder {
+		ctx := logging.With(ctx, logging.RegionKey, region)
+		awsCreds, err := s.awsConfig(ctx, region)
+		if err != nil {
+			unchecked = append(unchecked, quotasNotChecked(ctx, increasing[region], err)...)
+			continue
+		}
+
+		byService := make(map[service.Service][]*service.ResourcePlan)
+		for _, resourcePlan := range increasing[region] {
+			byService[service.Service(resourcePlan.ServiceName)] = append(byService[service.Service(resourcePlan.ServiceName)], resourcePlan)
+		}
+
+		if resourcePlans := byService[service.Kinesis]; len(resourcePlans) > 0 {
+			found, err := checkKinesisQuotas(ctx, awsCreds, resourcePlans)
+			violations, unchecked = append(violations, found...), append(unchecked, quotasNotChecked(ctx, resourcePlans, err)...)
+		}
+		if resourcePlans := byService[service.DynamoDB]; len(resourcePlans) > 0 {
+			found, err := checkDynamoDBQuotas(ctx, awsCreds, resourcePlans)
+			violations, unchecked = append(violations, found...), append(unchecked, quotasNotChecked(ctx, resourcePlans, err)...)
+		}
+		if resourcePlans := byService[service.EC2]; len(resourcePlans) > 0 {
+			found, uncounted, err := checkEC2Quotas(ctx, awsCreds, resourcePlans)
+			violations, unchecked = append(violations, found...), append(unchecked, quotasNotChecked(ctx, resourcePlans, err)...)
+			unchecked = append(unchecked, quotasNotChecked(ctx, uncounted, errInstanceTypeUnknown)...)
+		}
+	}
+	return violations, unchecked
+}
+
+// quotasNotChecked reports the resources whose quotas the error kept from being checked
+func quotasNotChecked(ctx context.Context, resourcePlans []*service.ResourcePlan, err error) []*service.ScalingError {
+	if err == nil || len(resourcePlans) == 0 {
+		return nil
+	}
+
+	logging.FromContext(ctx).Warn("error checking service quotas, not checking them", "resources", len(resourcePlans), logging.Err(err))
+	unchecked := make([]*service.ScalingError, 0, len(resourcePlans))
+	for _, resourcePlan := range resourcePlans {
+		unchecked = append(unchecked, &service.ScalingError{
+			Region:       resourcePlan.Region,
+			ServiceName:  resourcePlan.ServiceName,
+			IdentifierId: resourcePlan.IdentifierId,
+			Err:          fmt.Errorf("quotas not checked: %w", err),
+		})
+	}
+	return unchecked
+}
+
+func checkKinesisQuotas(ctx context.Context, awsCreds *aws.Config, resourcePlans []*service.ResourcePlan) ([]*service.ScalingError, error) {
+	limit, err := quotas.KinesisShards(ctx, service.NewKinesisClient(awsCreds))
+	if err != nil {
+		return nil, err
+	}
+	return checkLimit(limit, resourcePlans, func(resourcePlan *service.ResourcePlan) int {
+		return increase(resourcePlan, "desiredShardCount")
+	}), nil
+}
+
+func checkDynamoDBQuotas(ctx context.Context, awsCreds *aws.Config, resourcePlans []*service.ResourcePlan) ([]*service.ScalingError, error) {
+	limits, err := quotas.DynamoDB(ctx, service.NewDynamoDBClient(awsCreds))
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []*service.ScalingError
+	for _, resourcePlan := range resourcePlans {
+		var errs []error
+		if target, ok := resourcePlan.Target["rcu.maxProvisionedCapacity"]; ok && limits.TableMaxRead > 0 && target > limits.TableMaxRead {
+			errs = append(errs, fmt.Errorf("%w: rcu.maxProvisionedCapacity %d exceeds the dynamodb table read capacity quota %d", quotas.ErrExceeded, target, limits.TableMaxRead))
+		}
+		if target, ok := resourcePlan.Target["wcu.maxProvisionedCapacity"]; ok && limits.TableMaxWrite > 0 && target > limits.TableMaxWrite {
+			errs = append(errs, fmt.Errorf("%w: wcu.maxProvisionedCapacity %d exceeds the dynamodb table write capacity quota %d", quotas.ErrExceeded, target, limits.TableMaxWrite))
+		}
+		for _, err := range errs {
+			violations = append(violations, quotaViolation(resourcePlan, err))
+		}
+	}
+
+	// auto scaling provisions at least the min capacity right away, the capacity it may scale up to later isn't counted
+	violations = append(violations, checkLimit(limits.AccountRead, resourcePlans, func(resourcePlan *service.ResourcePlan) int {
+		return increase(resourcePlan, "rcu.minProvisionedCapacity")
+	})...)
+	violations = append(violations, checkLimit(limits.AccountWrite, resourcePlans, func(resourcePlan *service.ResourcePlan) int {
+		return increase(resourcePlan, "wcu.minProvisionedCapacity")
+	})...)
+	return violations, nil
+}
+
+// checkEC2Quotas checks the vCPU quota of the groups it can tell the instance type of, and returns the others apart
+func checkEC2Quotas(ctx context.Context, awsCreds *aws.Config, resourcePlans []*service.ResourcePlan) ([]*service.ScalingError, []*service.ResourcePlan, error) {
+	asgNames := make([]string, 0, len(resourcePlans))
+	for _, resourcePlan := range resourcePlans {
+		asgNames = append(asgNames, resourcePlan.IdentifierId)
+	}
+	vCPUs, err := quotas.InstanceVCPUs(ctx, service.NewAutoScalingClient(awsCreds), service.NewEC2Client(awsCreds), asgNames)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var counted, uncounted []*service.ResourcePlan
+	for _, resourcePlan := range resourcePlans {
+		if _, ok := vCPUs[resourcePlan.IdentifierId]; !ok {
+			uncounted = append(uncounted, resourcePlan)
+			continue
+		}
+		counted = append(counted, resourcePlan)
+	}
+	if len(counted) == 0 {
+		return nil, uncounted, nil
+	}
+
+	limit, err := quotas.StandardVCPUs(ctx, service.NewServiceQuotasClient(awsCreds), service.NewCloudWatchClient(awsCreds))
+	if err != nil {
+		return nil, nil, err
+	}
+	return checkLimit(limit, counted, func(resourcePlan *service.ResourcePlan) int {
+		return increase(resourcePlan, "desiredCount") * vCPUs[resourcePlan.IdentifierId]
+	}), uncounted, nil
+}
+
+// checkLimit adds up the increases of the resources in a region and flags every resource adding to an increase the
+// limit can't fit
+func checkLimit(limit quotas.Limit, resourcePlans []*service.ResourcePlan, increaseOf func(resourcePlan *service.ResourcePlan) int) []*service.ScalingError {
+	total := 0
+	var increasing []*service.ResourcePlan
+	for _, resourcePlan := range resourcePlans {
+		if amount := increaseOf(resourcePlan); amount > 0 {
+			total += amount
+			increasing = append(increasing, resourcePlan)
+		}
+	}
+
+	err := limit.Check(total)
+	if err == nil {
+		return nil
+	}
+	violations := make([]*service.ScalingError, 0, len(increasing))
+	for _, resourcePlan := range increasing {
+		violations = append(violations, quotaViolation(resourcePlan, err))
+	}
+	return violations
+}
+
+func quotaViolation(resourcePlan *service.ResourcePlan, err error) *service.ScalingError {
+	return &service.ScalingError{
+		Region:       resourcePlan.Region,
+		ServiceName:  resourcePlan.ServiceName,
+		IdentifierId: resourcePlan.IdentifierId,
+		Err:          fmt.Errorf("%w: %w", ErrGuardrailViolation, err),
+	}
+}
+
+func isIncrease(resourcePlan *service.ResourcePlan) bool {
+	for name := range resourcePlan.Target {
+		if increase(resourcePlan, name) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// increase is how much the target of the capacity is above the current capacity, a resource without current
+// capacity counts from 0
+func increase(resourcePlan *service.ResourcePlan, name string) int {
+	target, ok := resourcePlan.Target[name]
+	if !ok {
+		return 0
+	}
+	return max(0, target-resourcePlan.Current[name])
+}
@@ -0,0 +1,288 @@
+package quotas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatchtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// the Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances quota, counted in vCPUs
+	standardVCPUsQuotaCode = "L-1216C47A"
+
+	// DescribeInstanceTypes accepts up to 100 instance types
+	maxInstanceTypes = 100
+)
+
+// ErrExceeded marks the limits a scale-up doesn't fit in
+var ErrExceeded = errors.New("service quota exceeded")
+
+// Limit is a quota of a region and how much of it is in use
+type Limit struct {
+	Name  string
+	Quota int
+	Usage int
+}
+
+// Check fails when the increase doesn't fit in what is left of the quota
+func (l Limit) Check(increase int) error {
+	if increase > 0 && l.Usage+increase > l.Quota {
+		return fmt.Errorf("%w: %s quota is %d, %d in use and scaling needs %d more", ErrExceeded, l.Name, l.Quota, l.Usage, increase)
+	}
+	return nil
+}
+
+// DynamoDBLimits are the provisioned capacity quotas of the account and of a single table in a region
+type DynamoDBLimits struct {
+	AccountRead   Limit
+	AccountWrite  Limit
+	TableMaxRead  int
+	TableMaxWrite int
+}
+
+// KinesisShards is the shard quota of the account in the region and the open shards
+func KinesisShards(ctx context.Context, client *kinesis.Client) (Limit, error) {
+	output, err := client.DescribeLimits(ctx, &kinesis.DescribeLimitsInput{})
+	if err != nil {
+		return Limit{}, fmt.Errorf("error describing kinesis limits: %w", err)
+	}
+	return Limit{
+		Name:  "kinesis shards",
+		Quota: int(aws.ToInt32(output.ShardLimit)),
+		Usage: int(aws.ToInt32(output.OpenShardCount)),
+	}, nil
+}
+
+// DynamoDB is the provisioned capacity quotas of the region and the capacity provisioned by the tables and their
+// global secondary indexes
+func DynamoDB(ctx context.Context, client *dynamodb.Client) (*DynamoDBLimits, error) {
+	output, err := client.DescribeLimits(ctx, &dynamodb.DescribeLimitsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error describing dynamodb limits: %w", err)
+	}
+
+	var read, write int
+	paginator := dynamodb.NewListTablesPaginator(client, &dynamodb.ListTablesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing dynamodb tables: %w", err)
+		}
+		for _, tableName := range page.TableNames {
+			table, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+			if err != nil {
+				return nil, fmt.Errorf("error describing dynamodb table %s: %w", tableName, err)
+			}
+			if throughput := table.Table.ProvisionedThroughput; throughput != nil {
+				read += int(aws.ToInt64(throughput.ReadCapacityUnits))
+				write += int(aws.ToInt64(throughput.WriteCapacityUnits))
+			}
+			for _, index := range table.Table.GlobalSecondaryIndexes {
+				if throughput := index.ProvisionedThroughput; throughput != nil {
+					read += int(aws.ToInt64(throughput.ReadCapacityUnits))
+					write += int(aws.ToInt64(throughput.WriteCapacityUnits))
+				}
+			}
+		}
+	}
+
+	return &DynamoDBLimits{
+		AccountRead: Limit{
+			Name:  "dynamodb account read capacity",
+			Quota: int(aws.ToInt64(output.AccountMaxReadCapacityUnits)),
+			Usage: read,
+		},
+		AccountWrite: Limit{
+			Name:  "dynamodb account write capacity",
+			Quota: int(aws.ToInt64(output.AccountMaxWriteCapacityUnits)),
+			Usage: write,
+		},
+		TableMaxRead:  int(aws.ToInt64(output.TableMaxReadCapacityUnits)),
+		TableMaxWrite: int(aws.ToInt64(output.TableMaxWriteCapacityUnits)),
+	}, nil
+}
+
+// StandardVCPUs is the on-demand standard instances quota of the region and the vCPUs in use, as reported by the
+// AWS/Usage metric. A region without usage data uses no vCPUs.
+func StandardVCPUs(ctx context.Context, quotasClient *servicequotas.Client, cloudwatchClient *cloudwatch.Client) (Limit, error) {
+	output, err := quotasClient.GetServiceQuota(ctx, &servicequotas.GetServiceQuotaInput{
+		ServiceCode: aws.String("ec2"),
+		QuotaCode:   aws.String(standardVCPUsQuotaCode),
+	})
+	if err != nil {
+		return Limit{}, fmt.Errorf("error getting ec2 service quota %s: %w", standardVCPUsQuotaCode, err)
+	}
+
+	now := time.Now()
+	usage, err := cloudwatchClient.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String("AWS/Usage"),
+		MetricName: aws.String("ResourceCount"),
+		Dimensions: []cloudwatchtypes.Dimension{
+			{Name: aws.String("Service"), Value: aws.String("EC2")},
+			{Name: aws.String("Type"), Value: aws.String("Resource")},
+			{Name: aws.String("Resource"), Value: aws.String("vCPU")},
+			{Name: aws.String("Class"), Value: aws.String("Standard/OnDemand")},
+		},
+		StartTime:  aws.Time(now.Add(-5 * time.Minute)),
+		EndTime:    aws.Time(now),
+		Period:     aws.Int32(60),
+		Statistics: []cloudwatchtypes.Statistic{cloudwatchtypes.StatisticMaximum},
+	})
+	if err != nil {
+		return Limit{}, fmt.Errorf("error getting ec2 vCPU usage: %w", err)
+	}
+
+	var latest *cloudwatchtypes.Datapoint
+	for i := range usage.Datapoints {
+		datapoint := &usage.Datapoints[i]
+		if latest == nil || aws.ToTime(datapoint.Timestamp).After(aws.ToTime(latest.Timestamp)) {
+			latest = datapoint
+		}
+	}
+
+	limit := Limit{Name: "ec2 on-demand standard vCPUs"}
+	if output.Quota != nil {
+		limit.Quota = int(aws.ToFloat64(output.Quota.Value))
+	}
+	if latest != nil {
+		limit.Usage = int(math.Ceil(aws.ToFloat64(latest.Maximum)))
+	}
+	return limit, nil
+}
+
+// InstanceVCPUs is the vCPUs of an instance of each auto scaling group, taken from the type of its instances, or for
+// groups without instances from their launch template or launch configuration. Groups whose instances aren't standard
+// instances, or whose instance type can't be told, are left out.
+func InstanceVCPUs(ctx context.Context, autoScalingClient *autoscaling.Client, ec2Client *ec2.Client, asgNames []string) (map[string]int, error) {
+	instanceTypes := make(map[string]string)
+	paginator := autoscaling.NewDescribeAutoScalingGroupsPaginator(autoScalingClient, &autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: asgNames,
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error describing auto scaling groups: %w", err)
+		}
+		for _, group := range output.AutoScalingGroups {
+			instanceType := ""
+			if len(group.Instances) > 0 {
+				instanceType = aws.ToString(group.Instances[0].InstanceType)
+			} else {
+				instanceType, err = launchInstanceType(ctx, autoScalingClient, ec2Client, group)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if isStandard(instanceType) {
+				instanceTypes[aws.ToString(group.AutoScalingGroupName)] = instanceType
+			}
+		}
+	}
+
+	var distinctTypes []ec2types.InstanceType
+	seen := make(map[string]bool)
+	for _, instanceType := range instanceTypes {
+		if !seen[instanceType] {
+			seen[instanceType] = true
+			distinctTypes = append(distinctTypes, ec2types.InstanceType(instanceType))
+		}
+	}
+
+	vCPUs := make(map[string]int)
+	for start := 0; start < len(distinctTypes); start += maxInstanceTypes {
+		output, err := ec2Client.DescribeInstanceTypes(ctx, &ec2.DescribeInstanceTypesInput{
+			InstanceTypes: distinctTypes[start:min(start+maxInstanceTypes, len(distinctTypes))],
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error describing instance types: %w", err)
+		}
+		for _, info := range output.InstanceTypes {
+			if info.VCpuInfo != nil {
+				vCPUs[string(info.InstanceType)] = int(aws.ToInt32(info.VCpuInfo.DefaultVCpus))
+			}
+		}
+	}
+
+	asgVCPUs := make(map[string]int)
+	for asgName, instanceType := range instanceTypes {
+		if count, ok := vCPUs[instanceType]; ok {
+			asgVCPUs[asgName] = count
+		}
+	}
+	return asgVCPUs, nil
+}
+
+// launchInstanceType is the instance type the group launches, empty when it can't be told, e.g. for groups picking
+// instance types by their attributes
+func launchInstanceType(ctx context.Context, autoScalingClient *autoscaling.Client, ec2Client *ec2.Client, group autoscalingtypes.AutoScalingGroup) (string, error) {
+	if name := aws.ToString(group.LaunchConfigurationName); name != "" {
+		output, err := autoScalingClient.DescribeLaunchConfigurations(ctx, &autoscaling.DescribeLaunchConfigurationsInput{
+			LaunchConfigurationNames: []string{name},
+		})
+		if err != nil {
+			return "", fmt.Errorf("error describing launch configuration %s: %w", name, err)
+		}
+		if len(output.LaunchConfigurations) == 0 {
+			return "", nil
+		}
+		return aws.ToString(output.LaunchConfigurations[0].InstanceType), nil
+	}
+
+	template := group.LaunchTemplate
+	if policy := group.MixedInstancesPolicy; policy != nil && policy.LaunchTemplate != nil {
+		// the types of the overrides replace the type of the template, the first one is launched first
+		for _, override := range policy.LaunchTemplate.Overrides {
+			if instanceType := aws.ToString(override.InstanceType); instanceType != "" {
+				return instanceType, nil
+			}
+		}
+		template = policy.LaunchTemplate.LaunchTemplateSpecification
+	}
+	if template == nil {
+		return "", nil
+	}
+
+	version := aws.ToString(template.Version)
+	if version == "" {
+		version = "$Default"
+	}
+	input := &ec2.DescribeLaunchTemplateVersionsInput{Versions: []string{version}}
+	// the id and the name of a template can't be passed together
+	if template.LaunchTemplateId != nil {
+		input.LaunchTemplateId = template.LaunchTemplateId
+	} else {
+		input.LaunchTemplateName = template.LaunchTemplateName
+	}
+	output, err := ec2Client.DescribeLaunchTemplateVersions(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("error describing launch template of auto scaling group %s: %w", aws.ToString(group.AutoScalingGroupName), err)
+	}
+	if len(output.LaunchTemplateVersions) == 0 || output.LaunchTemplateVersions[0].LaunchTemplateData == nil {
+		return "", nil
+	}
+	return string(output.LaunchTemplateVersions[0].LaunchTemplateData.InstanceType), nil
+}
+
+// isStandard tells whether instances of the type count against the standard instances quota, DL, HPC and Inf
+// instances have quotas of their own
+func isStandard(instanceType string) bool {
+	for _, prefix := range []string{"dl", "hpc", "inf"} {
+		if strings.HasPrefix(instanceType, prefix) {
+			return false
+		}
+	}
+	return instanceType != "" && strings.ContainsRune("acdhimrtz", rune(instanceType[0]))
+}
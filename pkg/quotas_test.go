@@ -0,0 +1,120 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/quotas"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"reflect"
+	"testing"
+)
+
+func TestCheckLimit(t *testing.T) {
+	stream := func(identifierId string, current int, target int) *service.ResourcePlan {
+		return &service.ResourcePlan{
+			Region:       "us-east-1",
+			ServiceName:  string(service.Kinesis),
+			IdentifierId: identifierId,
+			Current:      service.Capacity{"desiredShardCount": current},
+			Target:       service.Capacity{"desiredShardCount": target},
+		}
+	}
+	shards := func(resourcePlan *service.ResourcePlan) int {
+		return increase(resourcePlan, "desiredShardCount")
+	}
+
+	tests := []struct {
+		name          string
+		limit         quotas.Limit
+		resourcePlans []*service.ResourcePlan
+		want          []string
+	}{
+		{
+			name:          "fits",
+			limit:         quotas.Limit{Name: "shards", Quota: 100, Usage: 50},
+			resourcePlans: []*service.ResourcePlan{stream("a", 10, 30), stream("b", 10, 40)},
+		},
+		{
+			name:          "fits exactly",
+			limit:         quotas.Limit{Name: "shards", Quota: 100, Usage: 50},
+			resourcePlans: []*service.ResourcePlan{stream("a", 10, 60)},
+		},
+		{
+			name:          "increases added up exceed the quota",
+			limit:         quotas.Limit{Name: "shards", Quota: 100, Usage: 50},
+			resourcePlans: []*service.ResourcePlan{stream("a", 10, 40), stream("b", 10, 40)},
+			want:          []string{"a", "b"},
+		},
+		{
+			name:          "decreases aren't flagged nor counted",
+			limit:         quotas.Limit{Name: "shards", Quota: 100, Usage: 95},
+			resourcePlans: []*service.ResourcePlan{stream("a", 10, 20), stream("b", 40, 10)},
+			want:          []string{"a"},
+		},
+		{
+			name:          "no increase over the quota",
+			limit:         quotas.Limit{Name: "shards", Quota: 100, Usage: 120},
+			resourcePlans: []*service.ResourcePlan{stream("a", 40, 10)},
+		},
+		{
+			name:          "no current capacity counts from 0",
+			limit:         quotas.Limit{Name: "shards", Quota: 100, Usage: 90},
+			resourcePlans: []*service.ResourcePlan{{Region: "us-east-1", ServiceName: string(service.Kinesis), IdentifierId: "a", Target: service.Capacity{"desiredShardCount": 20}}},
+			want:          []string{"a"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			violations := checkLimit(test.limit, test.resourcePlans, shards)
+
+			var got []string
+			for _, violation := range violations {
+				if !errors.Is(violation.Err, ErrGuardrailViolation) || !errors.Is(violation.Err, quotas.ErrExceeded) {
+					t.Errorf("checkLimit() flagged %s with %v, want a quota guardrail violation", violation.IdentifierId, violation.Err)
+				}
+				got = append(got, violation.IdentifierId)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("checkLimit() flagged %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestQuotasNotChecked(t *testing.T) {
+	resourcePlans := []*service.ResourcePlan{
+		{Region: "us-east-1", ServiceName: string(service.EC2), IdentifierId: "web"},
+		{Region: "us-east-1", ServiceName: string(service.EC2), IdentifierId: "worker"},
+	}
+	denied := errors.New("access denied")
+
+	tests := []struct {
+		name          string
+		resourcePlans []*service.ResourcePlan
+		err           error
+		want          int
+	}{
+		{name: "checked", resourcePlans: resourcePlans},
+		{name: "query failed", resourcePlans: resourcePlans, err: denied, want: 2},
+		{name: "no resources", err: errInstanceTypeUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			unchecked := quotasNotChecked(context.Background(), test.resourcePlans, test.err)
+
+			if len(unchecked) != test.want {
+				t.Fatalf("quotasNotChecked() returned %d resources, want %d", len(unchecked), test.want)
+			}
+			for i, scalingError := range unchecked {
+				if scalingError.IdentifierId != test.resourcePlans[i].IdentifierId || !errors.Is(scalingError.Err, test.err) {
+					t.Errorf("quotasNotChecked() = %s: %v, want %s: %v", scalingError.IdentifierId, scalingError.Err, test.resourcePlans[i].IdentifierId, test.err)
+				}
+				if errors.Is(scalingError.Err, ErrGuardrailViolation) {
+					t.Errorf("quotasNotChecked() reported %s as a guardrail violation", scalingError.IdentifierId)
+				}
+			}
+		})
+	}
+}
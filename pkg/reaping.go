@@ -0,0 +1,224 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/lock"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/ttl"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func newTTLStore(ctx context.Context, scalingConfig *config.ScalingConfig, awsConfig AWSConfigProvider) (ttl.Store, error) {
+	ttlConfig := scalingConfig.TTL
+	if ttlConfig == nil {
+		ttlConfig = &config.TTLConfig{}
+	}
+
+	if ttlConfig.Store != config.DynamoDBTTLStore {
+		path := ttlConfig.Path
+		if path == "" {
+			configDir, err := os.UserConfigDir()
+			if err != nil {
+				return nil, fmt.Errorf("error finding ttl directory: %w", err)
+			}
+			path = filepath.Join(configDir, "aws-infra-scaler", "ttl")
+		}
+		return ttl.NewFileStore(path)
+	}
+
+	region := ttlConfig.Region
+	if region == "" {
+		region = scalingConfig.DefaultRegion()
+	}
+
+	awsCreds, err := awsConfig(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	return ttl.NewDynamoDBStore(service.NewDynamoDBClient(awsCreds), ttlConfig.TableName), nil
+}
+
+// recordExpiry snapshots the resources scaled with a ttl and forgets the snapshot of resources
+// scaled without one, as the later run supersedes the time boxed scale-up
+func recordExpiry(ctx context.Context, scalingPlan *ScalingPlan, failedServices []*service.ScalingError) {
+	appName := scalingPlan.ScalingConfig.Name
+	scaleTTL := scalingPlan.options.TTL
+
+	logger := logging.FromContext(ctx)
+	store, err := newTTLStore(ctx, scalingPlan.ScalingConfig, scalingPlan.scaler.awsConfig)
+	if err != nil {
+		logger.Error("error creating ttl store", logging.Err(err))
+		return
+	}
+
+	snapshot, err := store.Get(ctx, appName)
+	if err != nil {
+		logger.Error("error reading ttl snapshot", logging.Err(err))
+		return
+	}
+	if snapshot == nil {
+		if scaleTTL <= 0 {
+			return
+		}
+		snapshot = &ttl.Snapshot{AppName: appName}
+	}
+
+	failed := make(map[string]bool)
+	for _, failedService := range failedServices {
+		failed[resourceKey(failedService.Region, failedService.ServiceName, failedService.IdentifierId)] = true
+	}
+
+	changed := false
+	expiresAt := scalingPlan.StartedAt.Add(scaleTTL)
+	for _, resourcePlan := range scalingPlan.Resources {
+		key := resourceKey(resourcePlan.Region, resourcePlan.ServiceName, resourcePlan.IdentifierId)
+		if failed[key] {
+			continue
+		}
+		resourceLogger := logger.With(logging.RegionKey, resourcePlan.Region, logging.ServiceKey, resourcePlan.ServiceName, logging.IdentifierKey, resourcePlan.IdentifierId)
+
+		if scaleTTL <= 0 {
+			if snapshot.Remove(key) {
+				changed = true
+				if err := resourcePlan.UntagExpiry(ctx); err != nil {
+					resourceLogger.Error("error removing expiry tag", logging.Err(err))
+				}
+			}
+			continue
+		}
+
+		if resourcePlan.Current == nil {
+			resourceLogger.Error("error recording ttl, current capacity unknown")
+			continue
+		}
+
+		snapshot.Put(ttl.ResourceSnapshot{
+			Region:       resourcePlan.Region,
+			ServiceName:  resourcePlan.ServiceName,
+			IdentifierId: resourcePlan.IdentifierId,
+			Capacity:     resourcePlan.Current,
+			RunId:        scalingPlan.RunId,
+			ExpiresAt:    expiresAt,
+		})
+		changed = true
+		if err := resourcePlan.TagExpiry(ctx, expiresAt); err != nil {
+			resourceLogger.Error("error tagging expiry", logging.Err(err))
+		}
+	}
+
+	if !changed {
+		return
+	}
+	if err := ttl.Save(ctx, store, *snapshot); err != nil {
+		logger.Error("error saving ttl snapshot", logging.Err(err))
+	}
+}
+
+// Reap reverts the resources of an app whose time boxed scale-up expired, it returns nil when nothing expired
+func Reap(ctx context.Context, configPath string, options ScaleOptions) (*ScalingResponse, error) {
+	options.TTL = 0
+	scaler, err := New(WithConfigFile(configPath), withScaleOptions(options))
+	if err != nil {
+		return nil, err
+	}
+	scalingConfig := scaler.Config()
+
+	store, err := newTTLStore(ctx, scalingConfig, scaler.awsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := store.Get(ctx, scalingConfig.Name)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		return nil, scaler.reapAlarms(ctx)
+	}
+
+	expired := snapshot.Expired(time.Now())
+	if len(expired) == 0 {
+		return nil, scaler.reapAlarms(ctx)
+	}
+
+	profile, missing := revertProfile(scalingConfig, expired)
+	if len(missing) > 0 {
+		for _, scalingError := range missing {
+			snapshot.Remove(resourceKey(scalingError.Region, scalingError.ServiceName, scalingError.IdentifierId))
+		}
+		if err := ttl.Save(ctx, store, *snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	scalingPlan, err := scaler.planProfile(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+	scalingPlan.FailedServices = append(scalingPlan.FailedServices, missing...)
+
+	return ApplyPlan(ctx, scalingPlan)
+}
+
+// reapAlarms enables the actions of the alarms left disabled by runs that died, when the reaper has nothing to revert.
+// A run scaling the app holds the lock and enables them itself.
+func (s *Scaler) reapAlarms(ctx context.Context) error {
+	ctx = s.context(ctx)
+	lease, err := acquireAppLock(ctx, s.config, s.awsConfig, 0)
+	if errors.Is(err, lock.ErrLockHeld) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := lease.Release(context.Background()); err != nil {
+			logging.FromContext(ctx).Error("error releasing app lock", logging.Err(err))
+		}
+	}()
+
+	s.restoreAlarms(ctx, nil)
+	return nil
+}
+
+func revertProfile(scalingConfig *config.ScalingConfig, resources []ttl.ResourceSnapshot) (*config.Profile, []*service.ScalingError) {
+	profile := &config.Profile{
+		Name:    config.RevertProfile,
+		ScaleUp: false,
+	}
+
+	var missing []*service.ScalingError
+	regions := make(map[string]int)
+	for _, resource := range resources {
+		serviceConfig, ok := scalingConfig.ResourceConfig(resource.Region, resource.ServiceName, resource.IdentifierId)
+		if !ok {
+			missing = append(missing, &service.ScalingError{
+				Region:       resource.Region,
+				ServiceName:  resource.ServiceName,
+				IdentifierId: resource.IdentifierId,
+				Err:          fmt.Errorf("resource is no longer in the config, it can't be reverted to %v", resource.Capacity),
+			})
+			continue
+		}
+
+		targets := make(map[string]config.CapacityTarget)
+		for name, value := range resource.Capacity {
+			targets[name] = config.AbsoluteTarget(value)
+		}
+
+		i, ok := regions[resource.Region]
+		if !ok {
+			i = len(profile.ScalingRegions)
+			regions[resource.Region] = i
+			profile.ScalingRegions = append(profile.ScalingRegions, config.ScalingRegion{Region: resource.Region})
+		}
+		profile.ScalingRegions[i].ServiceScaleConfigs = append(profile.ScalingRegions[i].ServiceScaleConfigs, serviceConfig.WithTargets(targets))
+	}
+	return profile, missing
+}
@@ -0,0 +1,150 @@
+package pkg
+
+import (
+	"context"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/ttl"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ttlConfig(t *testing.T, cacheConfigs ...config.ElasticCacheServiceScalingConfig) *config.ScalingConfig {
+	serviceScaleConfigs := []interface{}{
+		config.EC2ServiceScalingConfig{
+			Service:      string(service.EC2),
+			AsgName:      "my-asg",
+			MinCount:     config.AbsoluteTarget(4),
+			DesiredCount: config.AbsoluteTarget(8),
+			MaxCount:     config.AbsoluteTarget(16),
+		},
+	}
+	for _, cacheConfig := range cacheConfigs {
+		serviceScaleConfigs = append(serviceScaleConfigs, cacheConfig)
+	}
+
+	return &config.ScalingConfig{
+		Name:           "my-app",
+		TTL:            &config.TTLConfig{Path: t.TempDir()},
+		ScalingRegions: []config.ScalingRegion{{Region: "us-east-1", ServiceScaleConfigs: serviceScaleConfigs}},
+	}
+}
+
+func TestRevertProfile(t *testing.T) {
+	scalingConfig := ttlConfig(t)
+	expired := []ttl.ResourceSnapshot{
+		{Region: "us-east-1", ServiceName: string(service.EC2), IdentifierId: "my-asg", Capacity: map[string]int{"minCount": 1, "desiredCount": 2, "maxCount": 4}},
+		{Region: "us-east-1", ServiceName: string(service.EC2), IdentifierId: "removed-asg", Capacity: map[string]int{"desiredCount": 1}},
+	}
+
+	profile, missing := revertProfile(scalingConfig, expired)
+	if profile.Name != config.RevertProfile || profile.ScaleUp {
+		t.Errorf("revertProfile() = %s scaling up %v, want %s scaling down", profile.Name, profile.ScaleUp, config.RevertProfile)
+	}
+	if len(profile.ScalingRegions) != 1 || len(profile.ScalingRegions[0].ServiceScaleConfigs) != 1 {
+		t.Fatalf("revertProfile() regions = %+v, want the asg in us-east-1", profile.ScalingRegions)
+	}
+	got := profile.ScalingRegions[0].ServiceScaleConfigs[0].(config.ServiceScalingConfig).Targets()
+	want := map[string]config.CapacityTarget{"minCount": config.AbsoluteTarget(1), "desiredCount": config.AbsoluteTarget(2), "maxCount": config.AbsoluteTarget(4)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("revertProfile() targets = %v, want the recorded capacity %v", got, want)
+	}
+
+	if len(missing) != 1 || missing[0].IdentifierId != "removed-asg" {
+		t.Errorf("revertProfile() missing = %v, want removed-asg", missing)
+	}
+}
+
+func TestRecordExpiry(t *testing.T) {
+	ctx := context.Background()
+	startedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	scalingConfig := ttlConfig(t)
+	resourcePlan := func(identifierId string) *service.ResourcePlan {
+		return &service.ResourcePlan{
+			Region:       "us-east-1",
+			ServiceName:  string(service.EC2),
+			IdentifierId: identifierId,
+			Current:      service.Capacity{"desiredCount": 2},
+			Target:       service.Capacity{"desiredCount": 8},
+		}
+	}
+	plan := func(runId string, scaleTTL time.Duration) *ScalingPlan {
+		return &ScalingPlan{
+			RunId:         runId,
+			StartedAt:     startedAt,
+			ScalingConfig: scalingConfig,
+			Resources:     []*service.ResourcePlan{resourcePlan("my-asg"), resourcePlan("failed-asg")},
+			options:       ScaleOptions{TTL: scaleTTL},
+			scaler:        &Scaler{},
+		}
+	}
+	failed := []*service.ScalingError{{Region: "us-east-1", ServiceName: string(service.EC2), IdentifierId: "failed-asg"}}
+	store, err := newTTLStore(ctx, scalingConfig, nil)
+	if err != nil {
+		t.Fatalf("newTTLStore() error = %v", err)
+	}
+
+	recordExpiry(ctx, plan("run-1", time.Hour), failed)
+	snapshot, err := store.Get(ctx, "my-app")
+	if err != nil || snapshot == nil {
+		t.Fatalf("Get() = %v, %v, want the snapshot", snapshot, err)
+	}
+	if len(snapshot.Resources) != 1 {
+		t.Fatalf("snapshot has %d resources, want only the scaled one", len(snapshot.Resources))
+	}
+	recorded := snapshot.Resources[0]
+	if recorded.IdentifierId != "my-asg" || !reflect.DeepEqual(recorded.Capacity, map[string]int{"desiredCount": 2}) {
+		t.Errorf("snapshot = %+v, want my-asg at its capacity before scaling", recorded)
+	}
+	if !recorded.ExpiresAt.Equal(startedAt.Add(time.Hour)) {
+		t.Errorf("snapshot expires at %v, want %v", recorded.ExpiresAt, startedAt.Add(time.Hour))
+	}
+	if expired := snapshot.Expired(startedAt.Add(30 * time.Minute)); len(expired) != 0 {
+		t.Errorf("Expired() before the ttl = %v, want none", expired)
+	}
+
+	// a later run without a ttl supersedes the time boxed scale-up
+	recordExpiry(ctx, plan("run-2", 0), nil)
+	if snapshot, err := store.Get(ctx, "my-app"); err != nil || snapshot != nil {
+		t.Errorf("Get() = %v, %v, want the snapshot deleted", snapshot, err)
+	}
+}
+
+func TestPlanTTLUnrevertibleCluster(t *testing.T) {
+	cacheConfig := config.ElasticCacheServiceScalingConfig{
+		Service:   string(service.ElasticCache),
+		ClusterId: "my-cache",
+		Engine:    string(service.Redis),
+		NodeCount: config.AbsoluteTarget(4),
+	}
+
+	tests := []struct {
+		name          string
+		nodesToDelete []string
+		wantErr       bool
+	}{
+		{name: "without nodes to delete", wantErr: true},
+		{name: "with nodes to delete", nodesToDelete: []string{"0003", "0004"}, wantErr: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cacheConfig.NodesToDelete = test.nodesToDelete
+			scalingConfig := ttlConfig(t, cacheConfig)
+			if clusters := scalingConfig.UnrevertibleClusters(scalingConfig.ScalingRegions); (len(clusters) > 0) != test.wantErr {
+				t.Errorf("UnrevertibleClusters() = %v, want unrevertible %v", clusters, test.wantErr)
+			}
+			if !test.wantErr {
+				return
+			}
+
+			scaler := &Scaler{config: scalingConfig, options: ScaleOptions{TTL: time.Hour}}
+			_, err := scaler.Plan(context.Background(), config.ScaleUpProfile)
+			if err == nil || !strings.Contains(err.Error(), "my-cache (us-east-1)") {
+				t.Errorf("Plan() error = %v, want my-cache reported as unrevertible", err)
+			}
+		})
+	}
+}
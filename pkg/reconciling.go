@@ -0,0 +1,82 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+)
+
+// DetectDrift plans the resources of a profile whose live capacity differs from the profile,
+// applying the plan corrects the drift
+func DetectDrift(ctx context.Context, configPath string, options ScaleOptions) (*ScalingPlan, error) {
+	options.TTL = 0
+	scaler, err := New(WithConfigFile(configPath), withScaleOptions(options))
+	if err != nil {
+		return nil, err
+	}
+	scalingConfig := scaler.Config()
+
+	profile, err := scalingConfig.Profile(options.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, skipped := absoluteProfile(profile)
+
+	scalingPlan, err := scaler.planProfile(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifted []*service.ResourcePlan
+	for _, resourcePlan := range scalingPlan.Resources {
+		if resourcePlan.IsChange() {
+			drifted = append(drifted, resourcePlan)
+		}
+	}
+
+	scalingPlan.Resources = drifted
+	scalingPlan.Skipped = skipped
+	scaler.checkPlanGuardrails(ctx, scalingPlan, drifted)
+	return scalingPlan, nil
+}
+
+// absoluteProfile drops the resources with relative targets, which never match the live capacity, and returns them
+// as skipped
+func absoluteProfile(profile *config.Profile) (*config.Profile, []*service.ScalingError) {
+	absolute := &config.Profile{
+		Name:    profile.Name,
+		ScaleUp: profile.ScaleUp,
+		Rollout: profile.Rollout,
+	}
+
+	var skipped []*service.ScalingError
+	for _, scalingRegion := range profile.ScalingRegions {
+		region := scalingRegion
+		region.ServiceScaleConfigs = nil
+		for _, serviceScaleConfig := range scalingRegion.ServiceScaleConfigs {
+			if serviceConfig, ok := serviceScaleConfig.(config.ServiceScalingConfig); ok && hasRelativeTarget(serviceConfig) {
+				skipped = append(skipped, &service.ScalingError{
+					Region:       scalingRegion.Region,
+					ServiceName:  serviceConfig.GetService(),
+					IdentifierId: serviceConfig.GetIdentifier(),
+					Err:          fmt.Errorf("resources with relative targets can't be reconciled"),
+				})
+				continue
+			}
+			region.ServiceScaleConfigs = append(region.ServiceScaleConfigs, serviceScaleConfig)
+		}
+		absolute.ScalingRegions = append(absolute.ScalingRegions, region)
+	}
+	return absolute, skipped
+}
+
+func hasRelativeTarget(serviceConfig config.ServiceScalingConfig) bool {
+	for _, target := range serviceConfig.Targets() {
+		if target.IsRelative() {
+			return true
+		}
+	}
+	return false
+}
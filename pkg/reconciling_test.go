@@ -0,0 +1,64 @@
+package pkg
+
+import (
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"testing"
+	"time"
+)
+
+func TestAbsoluteProfile(t *testing.T) {
+	ec2Config := func(asgName string, desiredCount string) config.EC2ServiceScalingConfig {
+		return config.EC2ServiceScalingConfig{
+			Service:      string(service.EC2),
+			AsgName:      asgName,
+			MinCount:     mustTarget(t, "1"),
+			DesiredCount: mustTarget(t, desiredCount),
+			MaxCount:     mustTarget(t, "10"),
+		}
+	}
+	region := config.ScalingRegion{
+		Region:         "us-east-1",
+		Hooks:          &config.HooksConfig{},
+		Timeout:        10 * time.Minute,
+		HealthGate:     &config.HealthGate{},
+		SuppressAlarms: &config.SuppressAlarmsConfig{},
+		ServiceScaleConfigs: []interface{}{
+			ec2Config("absolute", "5"),
+			ec2Config("relative", "x2"),
+		},
+	}
+	profile := &config.Profile{Name: "peak", ScaleUp: true, ScalingRegions: []config.ScalingRegion{region}}
+
+	absolute, skipped := absoluteProfile(profile)
+
+	if len(absolute.ScalingRegions) != 1 {
+		t.Fatalf("absoluteProfile() returned %d regions, want 1", len(absolute.ScalingRegions))
+	}
+	got := absolute.ScalingRegions[0]
+	if got.Timeout != region.Timeout || got.Hooks != region.Hooks || got.HealthGate != region.HealthGate || got.SuppressAlarms != region.SuppressAlarms {
+		t.Errorf("absoluteProfile() dropped settings of the region: %+v", got)
+	}
+
+	tests := []struct {
+		asgName string
+		planned bool
+	}{
+		{asgName: "absolute", planned: true},
+		{asgName: "relative", planned: false},
+	}
+	for _, test := range tests {
+		planned := false
+		for _, serviceScaleConfig := range got.ServiceScaleConfigs {
+			if serviceScaleConfig.(config.EC2ServiceScalingConfig).AsgName == test.asgName {
+				planned = true
+			}
+		}
+		if planned != test.planned {
+			t.Errorf("absoluteProfile() planned %s: %v, want %v", test.asgName, planned, test.planned)
+		}
+	}
+	if len(skipped) != 1 || skipped[0].IdentifierId != "relative" {
+		t.Errorf("absoluteProfile() skipped %v, want relative", skipped)
+	}
+}
@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"errors"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/audit"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"time"
+)
+
+type ResourceResult struct {
+	Region       string
+	ServiceName  string
+	IdentifierId string
+	// Before is the capacity when the resource was planned
+	Before service.Capacity
+	// After is the capacity the resource was scaled to, nil when it failed or the run was aborted
+	After service.Capacity
+	// Unchanged is set when the resource was already at its target and wasn't scaled
+	Unchanged bool
+	// TimedOut is set when scaling the resource took longer than a timeout, or the run timed out before scaling it
+	TimedOut bool
+	Errors   []*service.ScalingError
+}
+
+type Result struct {
+	RunId              string
+	AppName            string
+	Profile            string
+	Outcome            string
+	StartedAt          time.Time
+	FinishedAt         time.Time
+	Resources          []ResourceResult
+	FailedServices     []*service.ScalingError
+	GuardrailsViolated bool
+}
+
+func (r *Result) Succeeded() bool {
+	return r.Outcome == audit.OutcomeSucceeded
+}
+
+func (r *Result) Canceled() bool {
+	return r.Outcome == audit.OutcomeCanceled
+}
+
+func (r *Result) Response() *ScalingResponse {
+	scalingResponse := newScalingResponse(r.FailedServices)
+	scalingResponse.RunId = r.RunId
+	scalingResponse.GuardrailsViolated = r.GuardrailsViolated
+	scalingResponse.Canceled = r.Canceled()
+	for _, resource := range r.Resources {
+		if resource.After != nil && !resource.Unchanged {
+			scalingResponse.ScaledResources = append(scalingResponse.ScaledResources, resource)
+		}
+	}
+	return scalingResponse
+}
+
+func newResult(scalingPlan *ScalingPlan, failedServices []*service.ScalingError, outcome string) *Result {
+	resourceErrors := make(map[string][]*service.ScalingError)
+	for _, failedService := range failedServices {
+		key := resourceKey(failedService.Region, failedService.ServiceName, failedService.IdentifierId)
+		resourceErrors[key] = append(resourceErrors[key], failedService)
+	}
+
+	result := &Result{
+		RunId:          scalingPlan.RunId,
+		AppName:        scalingPlan.ScalingConfig.Name,
+		Profile:        scalingPlan.Profile,
+		Outcome:        outcome,
+		StartedAt:      scalingPlan.StartedAt,
+		FinishedAt:     time.Now(),
+		FailedServices: failedServices,
+	}
+
+	for _, resourcePlan := range scalingPlan.Resources {
+		resourceResult := ResourceResult{
+			Region:       resourcePlan.Region,
+			ServiceName:  resourcePlan.ServiceName,
+			IdentifierId: resourcePlan.IdentifierId,
+			Before:       resourcePlan.Current,
+			Unchanged:    !resourcePlan.IsChange(),
+			Errors:       resourceErrors[resourceKey(resourcePlan.Region, resourcePlan.ServiceName, resourcePlan.IdentifierId)],
+		}
+		for _, err := range resourceResult.Errors {
+			if errors.Is(err.Err, ErrTimeout) {
+				resourceResult.TimedOut = true
+			}
+		}
+		if len(resourceResult.Errors) == 0 && outcome != audit.OutcomeAborted {
+			resourceResult.After = resourcePlan.Target
+		}
+		result.Resources = append(result.Resources, resourceResult)
+	}
+	return result
+}
@@ -0,0 +1,249 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/audit"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/state"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/tracing"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+func newStateStore(scalingConfig *config.ScalingConfig) (state.Store, error) {
+	path := ""
+	if scalingConfig.State != nil {
+		path = scalingConfig.State.Path
+	}
+	if path == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("error finding state directory: %w", err)
+		}
+		path = filepath.Join(configDir, "aws-infra-scaler", "runs")
+	}
+	return state.NewFileStore(path)
+}
+
+// resumableRun returns the state of a run that didn't succeed, to resume it
+func resumableRun(ctx context.Context, scalingConfig *config.ScalingConfig, runId string) (*state.Run, error) {
+	store, err := newStateStore(scalingConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := store.Get(ctx, scalingConfig.Name, runId)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, fmt.Errorf("no state found for run %s of app %s, runs that succeeded don't keep their state", runId, scalingConfig.Name)
+	}
+	return run, nil
+}
+
+// pinnedProfile sets the targets of the resources the run planned to the targets it stored, relative targets resolved
+// again from the live capacity would scale the resources the run already scaled once more
+func pinnedProfile(profile *config.Profile, run *state.Run) *config.Profile {
+	pinned := *profile
+	pinned.ScalingRegions = nil
+	for _, scalingRegion := range profile.ScalingRegions {
+		region := scalingRegion
+		region.ServiceScaleConfigs = nil
+		for _, serviceScaleConfig := range scalingRegion.ServiceScaleConfigs {
+			serviceConfig, ok := serviceScaleConfig.(config.ServiceScalingConfig)
+			if ok {
+				resource, ok := run.Resource(resourceKey(region.Region, serviceConfig.GetService(), serviceConfig.GetIdentifier()))
+				if ok && len(resource.Target) > 0 {
+					targets := make(map[string]config.CapacityTarget)
+					for name, value := range resource.Target {
+						targets[name] = config.AbsoluteTarget(value)
+					}
+					serviceScaleConfig = serviceConfig.WithTargets(targets)
+				}
+			}
+			region.ServiceScaleConfigs = append(region.ServiceScaleConfigs, serviceScaleConfig)
+		}
+		pinned.ScalingRegions = append(pinned.ScalingRegions, region)
+	}
+	return &pinned
+}
+
+// resume continues the run in the plan, skipping the resources the run completed
+func resume(ctx context.Context, scalingPlan *ScalingPlan, run *state.Run) {
+	scalingPlan.RunId = run.RunId
+	scalingPlan.run = run
+	if scalingPlan.span != nil {
+		scalingPlan.span.SetAttributes(tracing.RunIdKey.String(run.RunId))
+	}
+
+	var resources []*service.ResourcePlan
+	for _, resourcePlan := range scalingPlan.Resources {
+		key := resourceKey(resourcePlan.Region, resourcePlan.ServiceName, resourcePlan.IdentifierId)
+		if run.Done(key) {
+			continue
+		}
+		if resource, _ := run.Resource(key); resource.Step > 0 {
+			logging.FromContext(ctx).Info("continuing resource from its last step", logging.RegionKey, resourcePlan.Region, logging.ServiceKey, resourcePlan.ServiceName, logging.IdentifierKey, resourcePlan.IdentifierId, "step", resource.Step, "reached", resource.Reached)
+		}
+		resources = append(resources, resourcePlan)
+	}
+	logging.FromContext(ctx).Info("resuming run", logging.RunIdKey, run.RunId, "completed", len(scalingPlan.Resources)-len(resources), "remaining", len(resources))
+	scalingPlan.Resources = resources
+	scalingPlan.scaler.checkPlanGuardrails(ctx, scalingPlan, resources)
+}
+
+// checkpoint saves the progress of the resources of a run, failing to save it doesn't fail the run
+type checkpoint struct {
+	mu    sync.Mutex
+	store state.Store
+	run   *state.Run
+}
+
+func newCheckpoint(ctx context.Context, scalingPlan *ScalingPlan) *checkpoint {
+	store, err := newStateStore(scalingPlan.ScalingConfig)
+	if err != nil {
+		logging.FromContext(ctx).Error("error creating state store, the run can't be resumed", logging.Err(err))
+		return nil
+	}
+
+	run := scalingPlan.run
+	if run == nil {
+		run = &state.Run{
+			RunId:     scalingPlan.RunId,
+			AppName:   scalingPlan.ScalingConfig.Name,
+			Profile:   scalingPlan.Profile,
+			StartedAt: scalingPlan.StartedAt,
+		}
+	}
+	run.Outcome = ""
+
+	now := time.Now()
+	for _, resourcePlan := range scalingPlan.Resources {
+		resource := state.Resource{
+			Region:       resourcePlan.Region,
+			ServiceName:  resourcePlan.ServiceName,
+			IdentifierId: resourcePlan.IdentifierId,
+			Target:       resourcePlan.Target,
+			Status:       state.StatusPending,
+			UpdatedAt:    now,
+		}
+		if previous, ok := run.Resource(resource.Key()); ok {
+			resource.Step = previous.Step
+			resource.Reached = previous.Reached
+		}
+		run.Set(resource)
+	}
+
+	c := &checkpoint{store: store, run: run}
+	c.save(ctx)
+	return c
+}
+
+func (c *checkpoint) update(ctx context.Context, resourcePlan *service.ResourcePlan, status string, errs []*service.ScalingError) {
+	if c == nil {
+		return
+	}
+
+	resource := state.Resource{
+		Region:       resourcePlan.Region,
+		ServiceName:  resourcePlan.ServiceName,
+		IdentifierId: resourcePlan.IdentifierId,
+		Target:       resourcePlan.Target,
+		Status:       status,
+		UpdatedAt:    time.Now(),
+	}
+	if len(errs) > 0 {
+		resource.Error = errs[0].Err.Error()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// a resource keeps the last step it completed until it's done
+	if previous, ok := c.run.Resource(resource.Key()); ok && status != state.StatusCompleted && status != state.StatusUnchanged {
+		resource.Step = previous.Step
+		resource.Reached = previous.Reached
+	}
+	c.run.Set(resource)
+	c.save(ctx)
+}
+
+// step saves the capacity a resource scaled in steps reached, a resumed run continues the resource from there
+func (c *checkpoint) step(ctx context.Context, resourcePlan *service.ResourcePlan, step int, reached service.Capacity) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.run.Set(state.Resource{
+		Region:       resourcePlan.Region,
+		ServiceName:  resourcePlan.ServiceName,
+		IdentifierId: resourcePlan.IdentifierId,
+		Target:       resourcePlan.Target,
+		Status:       state.StatusInProgress,
+		UpdatedAt:    time.Now(),
+		Step:         step,
+		Reached:      reached,
+	})
+	c.save(ctx)
+}
+
+// suppress saves the alarms of the region before the run disables their actions, so the next run of the app enables
+// them again when this one dies before doing it
+func (c *checkpoint) suppress(ctx context.Context, region string, alarmNames []string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.run.SuppressedAlarms == nil {
+		c.run.SuppressedAlarms = make(map[string][]string)
+	}
+	c.run.SuppressedAlarms[region] = alarmNames
+	c.save(ctx)
+}
+
+// unsuppress forgets the alarms of the region once their actions are enabled again
+func (c *checkpoint) unsuppress(ctx context.Context, region string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.run.SuppressedAlarms, region)
+	c.save(ctx)
+}
+
+// finish forgets the state of a run that succeeded and keeps the state of other runs to resume them
+func (c *checkpoint) finish(ctx context.Context, outcome string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if outcome == audit.OutcomeSucceeded {
+		if err := c.store.Delete(ctx, c.run.AppName, c.run.RunId); err != nil {
+			logging.FromContext(ctx).Error("error deleting run state", logging.Err(err))
+		}
+		return
+	}
+
+	c.run.Outcome = outcome
+	c.save(ctx)
+}
+
+func (c *checkpoint) save(ctx context.Context) {
+	c.run.UpdatedAt = time.Now()
+	if err := c.store.Put(ctx, *c.run); err != nil {
+		logging.FromContext(ctx).Error("error saving run state", logging.Err(err))
+	}
+}
@@ -0,0 +1,195 @@
+package pkg
+
+import (
+	"context"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/state"
+	"reflect"
+	"testing"
+)
+
+func mustTarget(t *testing.T, value string) config.CapacityTarget {
+	t.Helper()
+	target, err := config.ParseCapacityTarget(value)
+	if err != nil {
+		t.Fatalf("ParseCapacityTarget(%q) failed: %v", value, err)
+	}
+	return target
+}
+
+func TestPinnedProfile(t *testing.T) {
+	ec2Config := func(asgName string, desiredCount string) config.EC2ServiceScalingConfig {
+		return config.EC2ServiceScalingConfig{
+			Service:      string(service.EC2),
+			AsgName:      asgName,
+			MinCount:     mustTarget(t, "1"),
+			DesiredCount: mustTarget(t, desiredCount),
+			MaxCount:     mustTarget(t, "+50%"),
+		}
+	}
+	run := &state.Run{
+		Resources: []state.Resource{
+			{Region: "us-east-1", ServiceName: string(service.EC2), IdentifierId: "planned", Target: map[string]int{"minCount": 1, "desiredCount": 6, "maxCount": 12}},
+			{Region: "us-east-1", ServiceName: string(service.EC2), IdentifierId: "no-target"},
+			{Region: "eu-west-1", ServiceName: string(service.EC2), IdentifierId: "other-region", Target: map[string]int{"minCount": 1, "desiredCount": 1, "maxCount": 1}},
+		},
+	}
+	profile := &config.Profile{
+		Name:    "peak",
+		ScaleUp: true,
+		ScalingRegions: []config.ScalingRegion{{
+			Region: "us-east-1",
+			ServiceScaleConfigs: []interface{}{
+				ec2Config("planned", "x2"),
+				ec2Config("no-target", "x2"),
+				ec2Config("unplanned", "x2"),
+				ec2Config("other-region", "x2"),
+			},
+		}},
+	}
+
+	pinned := pinnedProfile(profile, run)
+
+	if pinned.Name != profile.Name || pinned.ScaleUp != profile.ScaleUp {
+		t.Errorf("pinnedProfile() = %s, scale up %v, want %s, scale up %v", pinned.Name, pinned.ScaleUp, profile.Name, profile.ScaleUp)
+	}
+	tests := []struct {
+		identifierId string
+		want         map[string]string
+	}{
+		{identifierId: "planned", want: map[string]string{"minCount": "1", "desiredCount": "6", "maxCount": "12"}},
+		{identifierId: "no-target", want: map[string]string{"minCount": "1", "desiredCount": "x2", "maxCount": "+50%"}},
+		{identifierId: "unplanned", want: map[string]string{"minCount": "1", "desiredCount": "x2", "maxCount": "+50%"}},
+		{identifierId: "other-region", want: map[string]string{"minCount": "1", "desiredCount": "x2", "maxCount": "+50%"}},
+	}
+	for i, test := range tests {
+		t.Run(test.identifierId, func(t *testing.T) {
+			serviceConfig := pinned.ScalingRegions[0].ServiceScaleConfigs[i].(config.ServiceScalingConfig)
+			if serviceConfig.GetIdentifier() != test.identifierId {
+				t.Fatalf("resource %d is %s, want %s", i, serviceConfig.GetIdentifier(), test.identifierId)
+			}
+			for name, target := range serviceConfig.Targets() {
+				if target.Value != test.want[name] {
+					t.Errorf("%s target = %s, want %s", name, target.Value, test.want[name])
+				}
+			}
+		})
+	}
+
+	if original := profile.ScalingRegions[0].ServiceScaleConfigs[0].(config.EC2ServiceScalingConfig); original.DesiredCount.Value != "x2" {
+		t.Errorf("pinnedProfile() changed the profile, desired count is %s", original.DesiredCount.Value)
+	}
+}
+
+func TestResume(t *testing.T) {
+	resourcePlan := func(identifierId string) *service.ResourcePlan {
+		return &service.ResourcePlan{
+			Region:       "us-east-1",
+			ServiceName:  string(service.EC2),
+			IdentifierId: identifierId,
+			Current:      service.Capacity{"desiredCount": 1},
+			Target:       service.Capacity{"desiredCount": 2},
+		}
+	}
+	runResource := func(identifierId string, status string) state.Resource {
+		return state.Resource{Region: "us-east-1", ServiceName: string(service.EC2), IdentifierId: identifierId, Status: status}
+	}
+
+	run := &state.Run{
+		RunId: "run-1",
+		Resources: []state.Resource{
+			runResource("completed", state.StatusCompleted),
+			runResource("unchanged", state.StatusUnchanged),
+			runResource("in-progress", state.StatusInProgress),
+			runResource("failed", state.StatusFailed),
+			runResource("pending", state.StatusPending),
+		},
+	}
+	scalingPlan := &ScalingPlan{
+		RunId:         "new-run",
+		ScalingConfig: &config.ScalingConfig{},
+		Resources: []*service.ResourcePlan{
+			resourcePlan("completed"),
+			resourcePlan("unchanged"),
+			resourcePlan("in-progress"),
+			resourcePlan("failed"),
+			resourcePlan("pending"),
+			resourcePlan("unknown"),
+		},
+		scaler: &Scaler{},
+	}
+
+	resume(context.Background(), scalingPlan, run)
+
+	if scalingPlan.RunId != run.RunId {
+		t.Errorf("resumed plan has run id %s, want %s", scalingPlan.RunId, run.RunId)
+	}
+	if scalingPlan.run != run {
+		t.Error("resumed plan doesn't keep the state of the run")
+	}
+	want := []string{"in-progress", "failed", "pending", "unknown"}
+	if len(scalingPlan.Resources) != len(want) {
+		t.Fatalf("resumed plan has %d resources, want %d", len(scalingPlan.Resources), len(want))
+	}
+	for i, identifierId := range want {
+		if scalingPlan.Resources[i].IdentifierId != identifierId {
+			t.Errorf("resource %d of the resumed plan is %s, want %s", i, scalingPlan.Resources[i].IdentifierId, identifierId)
+		}
+	}
+}
+
+func TestCheckpointSuppressedAlarms(t *testing.T) {
+	ctx := context.Background()
+	scalingPlan := &ScalingPlan{
+		RunId:         "run",
+		ScalingConfig: &config.ScalingConfig{Name: "my-app", State: &config.StateConfig{Path: t.TempDir()}},
+	}
+	checkpoint := newCheckpoint(ctx, scalingPlan)
+	store, err := newStateStore(scalingPlan.ScalingConfig)
+	if err != nil {
+		t.Fatalf("newStateStore() failed: %v", err)
+	}
+	suppressed := func() map[string][]string {
+		t.Helper()
+		runs, err := store.List(ctx, "my-app")
+		if err != nil || len(runs) != 1 {
+			t.Fatalf("List() = %v, %v, want the run", runs, err)
+		}
+		return runs[0].SuppressedAlarms
+	}
+
+	tests := []struct {
+		name   string
+		update func()
+		want   map[string][]string
+	}{
+		{
+			name:   "suppressed in a region",
+			update: func() { checkpoint.suppress(ctx, "us-east-1", []string{"my-app-5xx", "my-app-latency"}) },
+			want:   map[string][]string{"us-east-1": {"my-app-5xx", "my-app-latency"}},
+		},
+		{
+			name:   "suppressed in another region",
+			update: func() { checkpoint.suppress(ctx, "eu-west-1", []string{"my-app-5xx"}) },
+			want:   map[string][]string{"us-east-1": {"my-app-5xx", "my-app-latency"}, "eu-west-1": {"my-app-5xx"}},
+		},
+		{
+			name:   "enabled again in a region",
+			update: func() { checkpoint.unsuppress(ctx, "us-east-1") },
+			want:   map[string][]string{"eu-west-1": {"my-app-5xx"}},
+		},
+		{
+			name:   "enabled again everywhere",
+			update: func() { checkpoint.unsuppress(ctx, "eu-west-1") },
+			want:   nil,
+		},
+	}
+
+	for _, test := range tests {
+		test.update()
+		if got := suppressed(); !reflect.DeepEqual(got, test.want) && !(len(got) == 0 && len(test.want) == 0) {
+			t.Errorf("%s: saved suppressed alarms %v, want %v", test.name, got, test.want)
+		}
+	}
+}
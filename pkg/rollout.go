@@ -0,0 +1,227 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/health"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/hooks"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRolloutHalted fails the resources of the waves a halted rollout didn't scale
+var ErrRolloutHalted = errors.New("rollout halted")
+
+// healthCheckService names the failures of health checks, which aren't tied to a resource
+const healthCheckService = "health-check"
+
+// Waves returns the regions of the resources of the plan in the waves of its rollout
+func (s *ScalingPlan) Waves() [][]string {
+	planned := make(map[string]bool)
+	for _, resourcePlan := range s.Resources {
+		planned[resourcePlan.Region] = true
+	}
+
+	var regions []string
+	for _, region := range s.regionOrder {
+		if planned[region] {
+			regions = append(regions, region)
+			delete(planned, region)
+		}
+	}
+	var unordered []string
+	for region := range planned {
+		unordered = append(unordered, region)
+	}
+	sort.Strings(unordered)
+	return s.rollout.GroupRegions(append(regions, unordered...))
+}
+
+// rollOut scales the regions of the plan wave by wave, a wave that fails, whose resources aren't stable in time or whose
+// health check fails halts the rollout
+func (s *Scaler) rollOut(ctx context.Context, scalingPlan *ScalingPlan, regionalPlans map[string][]*service.ResourcePlan, vars map[string]string, checkpoint *checkpoint) []*service.ScalingError {
+	waves := scalingPlan.Waves()
+
+	var failedServices []*service.ScalingError
+	var halted error
+	for i, wave := range waves {
+		logger := logging.FromContext(ctx).With("wave", i+1, "regions", wave)
+		if halted != nil {
+			for _, region := range wave {
+				failedServices = append(failedServices, skipped(regionalPlans[region], halted)...)
+			}
+			continue
+		}
+
+		if len(waves) > 1 {
+			logger.Info("scaling wave")
+		}
+		waveFailures := s.scaleWave(ctx, scalingPlan, wave, regionalPlans, vars, checkpoint)
+		failedServices = append(failedServices, waveFailures...)
+		if i == len(waves)-1 || ctx.Err() != nil {
+			continue
+		}
+
+		if len(waveFailures) > 0 {
+			logger.Error("wave failed, rollout halted")
+			halted = fmt.Errorf("%w: wave %d failed", ErrRolloutHalted, i+1)
+			continue
+		}
+
+		if unhealthy := s.checkWave(ctx, scalingPlan.rollout, wave, regionalPlans, vars); len(unhealthy) > 0 {
+			logger.Error("wave not stable or unhealthy, rollout halted")
+			failedServices = append(failedServices, unhealthy...)
+			halted = fmt.Errorf("%w: wave %d not stable or unhealthy", ErrRolloutHalted, i+1)
+		}
+	}
+	return failedServices
+}
+
+func (s *Scaler) scaleWave(ctx context.Context, scalingPlan *ScalingPlan, wave []string, regionalPlans map[string][]*service.ResourcePlan, vars map[string]string, checkpoint *checkpoint) []*service.ScalingError {
+	resultChan := make(chan *service.ScalingError)
+
+	go func() {
+		defer close(resultChan)
+		var wg sync.WaitGroup
+		for _, region := range wave {
+			wg.Add(1)
+			go s.scaleRegion(ctx, scalingPlan, region, regionalPlans[region], scalingPlan.regions[region], vars, checkpoint, &wg, resultChan)
+		}
+		wg.Wait()
+	}()
+
+	var failedServices []*service.ScalingError
+	for err := range resultChan {
+		failedServices = append(failedServices, err)
+	}
+	return failedServices
+}
+
+// checkWave waits until the resources scaled in the wave are stable, for at most the wait of the rollout, and then
+// checks the health of the regions of the wave. A resource that isn't stable in time fails the wave like a failing
+// health check.
+func (s *Scaler) checkWave(ctx context.Context, rollout *config.RolloutConfig, wave []string, regionalPlans map[string][]*service.ResourcePlan, vars map[string]string) []*service.ScalingError {
+	if rollout == nil {
+		return nil
+	}
+
+	var mu sync.Mutex
+	var failed []*service.ScalingError
+	var wg sync.WaitGroup
+	report := func(errs ...*service.ScalingError) {
+		mu.Lock()
+		failed = append(failed, errs...)
+		mu.Unlock()
+	}
+
+	logging.FromContext(ctx).Info("waiting for the resources of the wave to be stable", "wait", rollout.StableWait())
+	for _, region := range wave {
+		for _, resourcePlan := range regionalPlans[region] {
+			if !resourcePlan.IsChange() {
+				continue
+			}
+
+			wg.Add(1)
+			go func(resourcePlan *service.ResourcePlan) {
+				defer wg.Done()
+				if err := waitStable(ctx, rollout.StableWait(), resourcePlan); err != nil {
+					report(err)
+				}
+			}(resourcePlan)
+		}
+	}
+	wg.Wait()
+
+	if len(failed) == 0 && rollout.HealthCheck != nil {
+		for _, region := range wave {
+			wg.Add(1)
+			go func(region string) {
+				defer wg.Done()
+				report(s.checkHealth(logging.With(ctx, logging.RegionKey, region), rollout.HealthCheck, region, vars)...)
+			}(region)
+		}
+		wg.Wait()
+	}
+
+	// a canceled run skips the remaining waves, the checks it interrupted didn't fail
+	if ctx.Err() != nil {
+		return nil
+	}
+	return failed
+}
+
+// waitStable polls the scaled resource until it's stable or the wait expires
+func waitStable(ctx context.Context, wait time.Duration, resourcePlan *service.ResourcePlan) *service.ScalingError {
+	ctx = logging.With(ctx,
+		logging.RegionKey, resourcePlan.Region,
+		logging.ServiceKey, resourcePlan.ServiceName,
+		logging.IdentifierKey, resourcePlan.IdentifierId)
+
+	err := health.Poll(ctx, wait, resourcePlan.Stable)
+	if err == nil {
+		return nil
+	}
+
+	logging.FromContext(ctx).Error("resource not stable", logging.Err(err))
+	return &service.ScalingError{
+		Region:       resourcePlan.Region,
+		ServiceName:  resourcePlan.ServiceName,
+		IdentifierId: resourcePlan.IdentifierId,
+		Err:          fmt.Errorf("not stable after %s: %w", wait, err),
+	}
+}
+
+// checkHealth checks the alarms and probes the url of the health check in the region until they pass or the check times out
+func (s *Scaler) checkHealth(ctx context.Context, healthCheck *config.HealthCheck, region string, vars map[string]string) []*service.ScalingError {
+	var unhealthy []*service.ScalingError
+	fail := func(identifier string, err error) {
+		logging.FromContext(ctx).Error("health check failed", logging.Err(err))
+		unhealthy = append(unhealthy, &service.ScalingError{
+			Region:       region,
+			ServiceName:  healthCheckService,
+			IdentifierId: identifier,
+			Err:          err,
+		})
+	}
+
+	logging.FromContext(ctx).Info("checking health")
+	if len(healthCheck.Alarms) > 0 {
+		awsCreds, err := s.awsConfig(ctx, region)
+		if err != nil {
+			fail("alarms", err)
+		} else {
+			client := service.NewCloudWatchClient(awsCreds)
+			err := health.Poll(ctx, healthCheck.Timeout, func(ctx context.Context) error {
+				return health.CheckAlarms(ctx, client, healthCheck.Alarms)
+			})
+			if err != nil {
+				fail("alarms", err)
+			}
+		}
+	}
+
+	if healthCheck.URL != "" {
+		url := expandVars(healthCheck.URL, withVars(vars, hooks.RegionVar, region))
+		err := health.Poll(ctx, healthCheck.Timeout, func(ctx context.Context) error {
+			return health.Probe(ctx, url, healthCheck.Headers)
+		})
+		if err != nil {
+			fail(url, err)
+		}
+	}
+	return unhealthy
+}
+
+// expandVars replaces the ${NAME} references to hook variables in s
+func expandVars(s string, vars map[string]string) string {
+	for name, value := range vars {
+		s = strings.ReplaceAll(s, "${"+name+"}", value)
+	}
+	return s
+}
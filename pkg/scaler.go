@@ -4,106 +4,521 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/alarms"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/audit"
 	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/hooks"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/lock"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/metrics"
 	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/state"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/tracing"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/smithy-go"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 type ScalingResponse struct {
+	RunId                  string
 	ContainsFailedServices bool
+	GuardrailsViolated     bool
+	// Canceled is set when the run was canceled before every resource was scaled
+	Canceled               bool
 	RegionalFailedServices map[string][]*service.ScalingError
+	// ScaledResources are the resources the run scaled, they tell how far a canceled run got
+	ScaledResources []ResourceResult
 }
 
-var assumeRoleArn string
+type ScaleOptions struct {
+	Profile     string
+	Force       bool
+	LockTimeout time.Duration
+	TTL         time.Duration
+	// Resume is the id of a run to resume, skipping the resources it completed
+	Resume string
+	// Timeout bounds the run from the start of planning to the end of scaling, overriding the timeout of the config
+	Timeout time.Duration
+}
+
+type ScalingPlan struct {
+	RunId               string
+	StartedAt           time.Time
+	Profile             string
+	ScalingConfig       *config.ScalingConfig
+	Resources           []*service.ResourcePlan
+	FailedServices      []*service.ScalingError
+	GuardrailViolations []*service.ScalingError
+	GuardrailErr        error
+	// Skipped lists the resources left out of the plan without failing it, like the ones with relative targets when
+	// detecting drift
+	Skipped []*service.ScalingError
+	// QuotasNotChecked lists the scale-ups whose service quotas couldn't be queried, they don't block the run
+	QuotasNotChecked []*service.ScalingError
+
+	options ScaleOptions
+	scaler  *Scaler
+	lease   *lock.Lease
+	span    trace.Span
+	run     *state.Run
+	// deadline is when the run times out, planning and scaling share it, zero when the run has no timeout
+	deadline time.Time
+
+	regions     map[string]config.ScalingRegion
+	regionOrder []string
+	rollout     *config.RolloutConfig
+	scaleUp     bool
+
+	sinks     []notificationSink
+	sinksOnce sync.Once
+}
+
+// HasChanges tells whether a resource of the plan isn't at its target yet
+func (s *ScalingPlan) HasChanges() bool {
+	return hasChanges(s.Resources)
+}
+
+func hasChanges(resourcePlans []*service.ResourcePlan) bool {
+	for _, resourcePlan := range resourcePlans {
+		if resourcePlan.IsChange() {
+			return true
+		}
+	}
+	return false
+}
+
+// NotScaled is the resources that failed planning and the ones violating guardrails, in a slice of its own
+func (s *ScalingPlan) NotScaled() []*service.ScalingError {
+	notScaled := make([]*service.ScalingError, 0, len(s.FailedServices)+len(s.GuardrailViolations))
+	notScaled = append(notScaled, s.FailedServices...)
+	return append(notScaled, s.GuardrailViolations...)
+}
+
+func (s *ScalingPlan) ViolatesGuardrails() bool {
+	return s.GuardrailErr != nil || len(s.GuardrailViolations) > 0
+}
+
+// Decline records the plan in the audit log as declined, for plans whose confirmation was declined, and discards it
+func (s *ScalingPlan) Decline(ctx context.Context) error {
+	if s.scaler != nil {
+		ctx = s.scaler.context(ctx)
+		writeAuditRecord(ctx, s, s.NotScaled(), audit.OutcomeDeclined)
+		metrics.ObserveRun(s.ScalingConfig.Name, s.Profile, audit.OutcomeDeclined)
+	}
+	return s.Discard()
+}
+
+func (s *ScalingPlan) Discard() error {
+	if s.span != nil {
+		s.span.End()
+		s.span = nil
+	}
+
+	if s.lease == nil {
+		return nil
+	}
+
+	err := s.lease.Release(context.Background())
+	s.lease = nil
+	return err
+}
+
+type planResult struct {
+	resourcePlan *service.ResourcePlan
+	err          *service.ScalingError
+}
+
+// Scaler plans and scales the resources of an app to its profiles
+type Scaler struct {
+	config      *config.ScalingConfig
+	awsConfig   AWSConfigProvider
+	logger      *slog.Logger
+	hooks       Hooks
+	concurrency int
+	options     ScaleOptions
+
+	slots chan struct{}
+	// cloudWatchClient overrides the client the actions of alarms are suppressed with
+	cloudWatchClient func(cfg *aws.Config) alarms.Client
+}
+
+func New(opts ...Option) (*Scaler, error) {
+	s := &Scaler{}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.config == nil {
+		return nil, errors.New("no config provided")
+	}
+	if s.awsConfig == nil {
+		if s.config.AssumedRoleArn == "" {
+			return nil, errors.New("no assumed role ARN provided")
+		}
+		s.awsConfig = AssumeRoleConfigProvider(s.config.AssumedRoleArn)
+	}
+	if s.concurrency > 0 {
+		s.slots = make(chan struct{}, s.concurrency)
+	}
+	return s, nil
+}
+
+func (s *Scaler) Config() *config.ScalingConfig {
+	return s.config
+}
+
+// Run plans the profile and applies the plan, the error is only set when the run couldn't start or was aborted
+// before scaling, failures of resources are reported in the result
+func (s *Scaler) Run(ctx context.Context, profile string) (*Result, error) {
+	scalingPlan, err := s.Plan(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+	return s.Apply(ctx, scalingPlan)
+}
+
+// Plan locks the app and plans the profile, the lock is held until the plan is applied or discarded.
+// When resuming a run, the profile may be empty to plan the profile of the run.
+func (s *Scaler) Plan(ctx context.Context, profileName string) (*ScalingPlan, error) {
+	var run *state.Run
+	if s.options.Resume != "" {
+		var err error
+		run, err = resumableRun(ctx, s.config, s.options.Resume)
+		if err != nil {
+			return nil, err
+		}
+		if profileName == "" {
+			profileName = run.Profile
+		}
+		if profileName != run.Profile {
+			return nil, fmt.Errorf("run %s scaled to profile %s, it can't be resumed with profile %s", run.RunId, run.Profile, profileName)
+		}
+	}
+
+	profile, err := s.config.Profile(profileName)
+	if err != nil {
+		return nil, err
+	}
 
-func ScaleApp(shouldScaleUp bool, configPath string) (*ScalingResponse, error) {
+	if s.options.TTL > 0 && !profile.ScaleUp {
+		return nil, fmt.Errorf("ttl is only supported when scaling up, profile %s scales down", profile.Name)
+	}
+	if clusters := s.config.UnrevertibleClusters(profile.ScalingRegions); s.options.TTL > 0 && len(clusters) > 0 {
+		return nil, fmt.Errorf("ttl can't revert elasticache clusters %s, no entry of them in the config lists nodesToDelete", strings.Join(clusters, ", "))
+	}
+
+	if run != nil {
+		profile = pinnedProfile(profile, run)
+	}
+	scalingPlan, err := s.planProfile(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+	if run != nil {
+		resume(ctx, scalingPlan, run)
+	}
+	return scalingPlan, nil
+}
+
+func ScaleApp(ctx context.Context, configPath string, options ScaleOptions) (*ScalingResponse, error) {
+	scalingPlan, err := PlanApp(ctx, configPath, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return ApplyPlan(ctx, scalingPlan)
+}
+
+// PlanApp plans the app like Scaler.Plan, canceling the context stops planning the resources not planned yet
+func PlanApp(ctx context.Context, configPath string, options ScaleOptions) (*ScalingPlan, error) {
+	scaler, err := New(WithConfigFile(configPath), withScaleOptions(options))
+	if err != nil {
+		return nil, err
+	}
+
+	return scaler.Plan(ctx, options.Profile)
+}
+
+func (s *Scaler) context(ctx context.Context) context.Context {
+	if s.logger != nil {
+		ctx = logging.NewContext(ctx, s.logger)
+	}
+	return ctx
+}
+
+func (s *Scaler) planProfile(ctx context.Context, profile *config.Profile) (*ScalingPlan, error) {
+	scalingConfig := s.config
+	ctx, span := tracing.Start(s.context(ctx), "ScaleApp", tracing.AppKey.String(scalingConfig.Name), tracing.ProfileKey.String(profile.Name))
+	ctx = logging.With(ctx, logging.AppKey, scalingConfig.Name, logging.ProfileKey, profile.Name)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lease, err := acquireAppLock(ctx, scalingConfig, s.awsConfig, s.options.LockTimeout)
+	if err != nil {
+		tracing.End(span, err)
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	var deadline time.Time
+	if timeout := s.timeout(); timeout > 0 {
+		deadline = startedAt.Add(timeout)
+	}
+	planCtx, cancelPlan := withDeadline(ctx, deadline)
+	defer cancelPlan()
+	scalingPlan := s.planApp(planCtx, profile)
+	scalingPlan.RunId = newRunId()
+	scalingPlan.StartedAt = startedAt
+	scalingPlan.deadline = deadline
+	scalingPlan.Profile = profile.Name
+	scalingPlan.scaleUp = profile.ScaleUp
+	scalingPlan.options = s.options
+	scalingPlan.scaler = s
+	scalingPlan.lease = lease
+	scalingPlan.span = span
+	scalingPlan.regions = make(map[string]config.ScalingRegion)
+	for _, scalingRegion := range profile.ScalingRegions {
+		if _, ok := scalingPlan.regions[scalingRegion.Region]; !ok {
+			scalingPlan.regionOrder = append(scalingPlan.regionOrder, scalingRegion.Region)
+		}
+		scalingPlan.regions[scalingRegion.Region] = scalingRegion
+	}
+	scalingPlan.rollout = scalingConfig.ProfileRollout(profile)
+	span.SetAttributes(tracing.RunIdKey.String(scalingPlan.RunId))
+	logging.FromContext(ctx).Debug("planned app", logging.RunIdKey, scalingPlan.RunId, "resources", len(scalingPlan.Resources), "failed", len(scalingPlan.FailedServices))
+	s.checkPlanGuardrails(planCtx, scalingPlan, scalingPlan.Resources)
+
+	return scalingPlan, nil
+}
 
-	scalingConfig, err := config.ReadConfig(configPath)
+// ApplyPlan scales the resources of the plan, canceling the context stops the resources not yet being scaled
+func ApplyPlan(ctx context.Context, scalingPlan *ScalingPlan) (*ScalingResponse, error) {
+	result, err := scalingPlan.scaler.Apply(ctx, scalingPlan)
 	if err != nil {
 		return nil, err
 	}
+	return result.Response(), nil
+}
 
-	assumeRoleArn = scalingConfig.AssumedRoleArn
-	if assumeRoleArn == "" {
-		return nil, errors.New("no assumed role ARN provided")
+// Apply scales the resources of the plan and releases the app lock, the plan can't be applied again.
+// Canceling the context skips the resources not yet being scaled, the result reports the resources scaled so far.
+func (s *Scaler) Apply(ctx context.Context, scalingPlan *ScalingPlan) (*Result, error) {
+	ctx = s.context(ctx)
+	if scalingPlan.span != nil {
+		ctx = trace.ContextWithSpan(ctx, scalingPlan.span)
 	}
+	ctx = logging.With(ctx,
+		logging.AppKey, scalingPlan.ScalingConfig.Name,
+		logging.ProfileKey, scalingPlan.Profile,
+		logging.RunIdKey, scalingPlan.RunId)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		if err := scalingPlan.Discard(); err != nil {
+			logging.FromContext(ctx).Error("error releasing app lock", logging.Err(err))
+		}
+	}()
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	cancelOnLockLost(ctx, scalingPlan.lease, cancel)
+	s.restoreAlarms(ctx, scalingPlan.run)
+
+	if !scalingPlan.options.Force {
+		if scalingPlan.GuardrailErr != nil {
+			finishRun(ctx, scalingPlan, scalingPlan.NotScaled(), audit.OutcomeAborted)
+			return nil, scalingPlan.GuardrailErr
+		}
+
+		if len(scalingPlan.GuardrailViolations) > 0 {
+			failedServices := scalingPlan.NotScaled()
+			finishRun(ctx, scalingPlan, failedServices, audit.OutcomeAborted)
 
-	resultChan := make(chan *service.ScalingError)
+			result := newResult(scalingPlan, failedServices, audit.OutcomeAborted)
+			result.GuardrailsViolated = true
+			return result, nil
+		}
+	}
+
+	checkpoint := newCheckpoint(ctx, scalingPlan)
+	if scalingPlan.HasChanges() {
+		notifyStart(ctx, scalingPlan)
+	}
+	scaleCtx, cancelScale := withDeadline(ctx, scalingPlan.deadline)
+	defer cancelScale()
+	scalingErrors := s.applyPlan(scaleCtx, scalingPlan, checkpoint)
+	failedServices := make([]*service.ScalingError, 0, len(scalingPlan.FailedServices)+len(scalingErrors))
+	failedServices = append(append(failedServices, scalingPlan.FailedServices...), scalingErrors...)
+
+	outcome := runOutcome(failedServices)
+	if outcome == audit.OutcomeCanceled {
+		logging.FromContext(ctx).Warn("run canceled, recording the partial result")
+	}
+	finishCtx, cancelFinish := detach(ctx)
+	defer cancelFinish()
+	finishRun(finishCtx, scalingPlan, failedServices, outcome)
+	recordExpiry(finishCtx, scalingPlan, failedServices)
+	checkpoint.finish(finishCtx, outcome)
+
+	return newResult(scalingPlan, failedServices, outcome), nil
+}
+
+// timeout is how long a run of the app may take from the start of planning to the end of scaling, including the
+// confirmation, 0 when runs don't time out
+func (s *Scaler) timeout() time.Duration {
+	if s.options.Timeout > 0 {
+		return s.options.Timeout
+	}
+	return s.config.Timeout
+}
+
+// acquireSlot blocks while the scaler works on as many resources as its concurrency allows,
+// the returned func releases the slot and may be called more than once
+func (s *Scaler) acquireSlot() func() {
+	if s.slots == nil {
+		return func() {}
+	}
+	s.slots <- struct{}{}
+	var once sync.Once
+	return func() { once.Do(func() { <-s.slots }) }
+}
+
+func finishRun(ctx context.Context, scalingPlan *ScalingPlan, failedServices []*service.ScalingError, outcome string) {
+	writeAuditRecord(ctx, scalingPlan, failedServices, outcome)
+	notifyFinish(ctx, scalingPlan, failedServices, outcome)
+	metrics.ObserveRun(scalingPlan.ScalingConfig.Name, scalingPlan.Profile, outcome)
+
+	if scalingPlan.span != nil {
+		scalingPlan.span.SetAttributes(tracing.OutcomeKey.String(outcome))
+		if outcome != audit.OutcomeSucceeded {
+			tracing.Fail(scalingPlan.span, fmt.Sprintf("scaling %s with %d failed services", outcome, len(failedServices)))
+		}
+	}
+}
+
+func newScalingResponse(failedServices []*service.ScalingError) *ScalingResponse {
+	if len(failedServices) == 0 {
+		return &ScalingResponse{
+			ContainsFailedServices: false,
+			RegionalFailedServices: nil,
+		}
+	}
+
+	regionalFailedServices := make(map[string][]*service.ScalingError)
+	for _, failedService := range failedServices {
+		regionalFailedServices[failedService.Region] = append(regionalFailedServices[failedService.Region], failedService)
+	}
+
+	return &ScalingResponse{
+		ContainsFailedServices: true,
+		RegionalFailedServices: regionalFailedServices,
+	}
+}
+
+func (s *Scaler) planApp(ctx context.Context, profile *config.Profile) *ScalingPlan {
+	scalingConfig := s.config
+	resultChan := make(chan *planResult)
 
 	go func() {
 		defer close(resultChan)
 		var wg sync.WaitGroup
-		fmt.Println("Scaling services...")
-		for _, scalingRegion := range scalingConfig.ScalingRegions {
+		logging.FromContext(ctx).Info("planning services")
+		for _, scalingRegion := range profile.ScalingRegions {
 			wg.Add(1)
-			go scaleRegion(ctx, scalingRegion, shouldScaleUp, &wg, resultChan)
+			go s.planRegion(ctx, scalingRegion, profile.ScaleUp, &wg, resultChan)
 		}
 		wg.Wait()
 	}()
 
-	regionalFailedServices := make(map[string][]*service.ScalingError)
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, fmt.Errorf("context cancelled")
-		case result, open := <-resultChan:
-
-			if !open {
-				if len(regionalFailedServices) > 0 {
-					return &ScalingResponse{
-						ContainsFailedServices: true,
-						RegionalFailedServices: regionalFailedServices,
-					}, nil
-				} else {
-					return &ScalingResponse{
-						ContainsFailedServices: false,
-						RegionalFailedServices: nil,
-					}, nil
-				}
-			}
-
-			if result != nil {
-				if regionalFailedServices[result.Region] == nil {
-					regionalFailedServices[result.Region] = make([]*service.ScalingError, 0)
-				}
-				regionalFailedServices[result.Region] = append(regionalFailedServices[result.Region], result)
-			}
+	scalingPlan := &ScalingPlan{
+		ScalingConfig: scalingConfig,
+	}
+	for result := range resultChan {
+		if result.err != nil {
+			scalingPlan.FailedServices = append(scalingPlan.FailedServices, result.err)
+			continue
 		}
+		scalingPlan.Resources = append(scalingPlan.Resources, result.resourcePlan)
+		metrics.SetCapacity(scalingConfig.Name, result.resourcePlan.Region, result.resourcePlan.ServiceName, result.resourcePlan.IdentifierId, result.resourcePlan.Current, result.resourcePlan.Target)
 	}
+
+	sort.Slice(scalingPlan.Resources, func(i, j int) bool {
+		a, b := scalingPlan.Resources[i], scalingPlan.Resources[j]
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.ServiceName != b.ServiceName {
+			return a.ServiceName < b.ServiceName
+		}
+		return a.IdentifierId < b.IdentifierId
+	})
+
+	return scalingPlan
 }
 
-func scaleRegion(ctx context.Context, scalingRegion config.ScalingRegion, shouldScaleUp bool, wg *sync.WaitGroup, resultChan chan *service.ScalingError) {
+func (s *Scaler) planRegion(ctx context.Context, scalingRegion config.ScalingRegion, shouldScaleUp bool, wg *sync.WaitGroup, resultChan chan *planResult) {
 	defer wg.Done()
 
+	ctx, span := tracing.Start(ctx, "PlanRegion", tracing.RegionKey.String(scalingRegion.Region))
+	defer span.End()
+	ctx = logging.With(ctx, logging.RegionKey, scalingRegion.Region)
+	ctx, cancel := withTimeout(ctx, scalingRegion.Timeout)
+	defer cancel()
+
 	var serviceWg sync.WaitGroup
-	awsCreds, err := service.NewConfig(ctx, scalingRegion.Region, assumeRoleArn)
+	awsCreds, err := s.awsConfig(ctx, scalingRegion.Region)
 	if err != nil {
+		span.RecordError(err)
+		tracing.Fail(span, "error creating aws config")
+		logging.FromContext(ctx).Error("error creating aws config", logging.Err(err))
+
+		scalingError := &service.ScalingError{
+			Region:       scalingRegion.Region,
+			ServiceName:  "sts",
+			IdentifierId: s.config.AssumedRoleArn,
+			Err:          err,
+		}
+
 		var oe *smithy.OperationError
 		if errors.As(err, &oe) {
-			resultChan <- &service.ScalingError{
-				ServiceName:  oe.Service(),
-				IdentifierId: oe.ServiceID,
-				Err:          oe,
-			}
+			scalingError.ServiceName = oe.Service()
+			scalingError.IdentifierId = oe.ServiceID
 		}
+		timedOut(ctx, []*service.ScalingError{scalingError})
+		resultChan <- &planResult{err: scalingError}
 		return
 	}
 
 	for _, serviceScaleConfig := range scalingRegion.ServiceScaleConfigs {
 		serviceWg.Add(1)
-		go scaleService(ctx, awsCreds, serviceScaleConfig, shouldScaleUp, scalingRegion.Region, &serviceWg, resultChan)
+		go s.planService(ctx, awsCreds, serviceScaleConfig, shouldScaleUp, scalingRegion.Region, &serviceWg, resultChan)
 	}
 
 	serviceWg.Wait()
 }
 
-func scaleService(ctx context.Context, awsCreds *aws.Config, serviceScaleConfig interface{}, shouldScaleUp bool, region string, wg *sync.WaitGroup, resultChan chan *service.ScalingError) {
+func (s *Scaler) planService(ctx context.Context, awsCreds *aws.Config, serviceScaleConfig interface{}, shouldScaleUp bool, region string, wg *sync.WaitGroup, resultChan chan *planResult) {
 	defer wg.Done()
+	defer s.acquireSlot()()
+
+	ctx, span := tracing.Start(ctx, "PlanService", tracing.RegionKey.String(region))
+	defer span.End()
+	if serviceConfig, ok := serviceScaleConfig.(config.ServiceScalingConfig); ok {
+		span.SetAttributes(tracing.ServiceKey.String(serviceConfig.GetService()), tracing.IdentifierKey.String(serviceConfig.GetIdentifier()))
+		ctx = logging.With(ctx, logging.ServiceKey, serviceConfig.GetService(), logging.IdentifierKey, serviceConfig.GetIdentifier())
+
+		var cancel context.CancelFunc
+		ctx, cancel = withTimeout(ctx, serviceConfig.GetTimeout())
+		defer cancel()
+	}
+
+	var resourcePlan *service.ResourcePlan
+	var err *service.ScalingError
 
 	switch serviceScaleConfig.(type) {
 	case config.KinesisServiceScalingConfig:
@@ -114,11 +529,7 @@ func scaleService(ctx context.Context, awsCreds *aws.Config, serviceScaleConfig
 			Region: region,
 			Client: kinesisClient,
 		}
-		err := ks.ScaleService(ctx, kinesisClientConfig)
-		if err != nil {
-			err.Region = region
-			resultChan <- err
-		}
+		resourcePlan, err = ks.Plan(ctx, kinesisClientConfig)
 
 	case config.EC2ServiceScalingConfig:
 		autoScalingClient := service.NewAutoScalingClient(awsCreds)
@@ -128,12 +539,7 @@ func scaleService(ctx context.Context, awsCreds *aws.Config, serviceScaleConfig
 			Region: region,
 			Client: autoScalingClient,
 		}
-
-		err := ec2.ScaleService(ctx, ec2ClientConfig)
-		if err != nil {
-			err.Region = region
-			resultChan <- err
-		}
+		resourcePlan, err = ec2.Plan(ctx, ec2ClientConfig)
 
 	case config.ElasticCacheServiceScalingConfig:
 		elasticCacheClient := service.NewElasticCacheClient(awsCreds)
@@ -143,35 +549,247 @@ func scaleService(ctx context.Context, awsCreds *aws.Config, serviceScaleConfig
 			Region: region,
 			Client: elasticCacheClient,
 		}
-
-		err := es.ScaleService(ctx, elasticCacheClientConfig, shouldScaleUp)
-		if err != nil {
-			err.Region = region
-			resultChan <- err
-		}
+		resourcePlan, err = es.Plan(ctx, elasticCacheClientConfig, shouldScaleUp)
 
 	case config.DynamoDBServiceScalingConfig:
 		appAutoScalingClient := service.NewApplicationAutoScalingClient(awsCreds)
 		dynamoDBClientConfig := serviceScaleConfig.(config.DynamoDBServiceScalingConfig)
 
 		ds := service.DynamoDBService{
-			Region: region,
-			Client: appAutoScalingClient,
-		}
-
-		errs := ds.ScaleService(ctx, dynamoDBClientConfig)
-		if errs != nil {
-			for _, err := range errs {
-				err.Region = region
-				resultChan <- err
-			}
+			Region:      region,
+			Client:      appAutoScalingClient,
+			TableClient: service.NewDynamoDBClient(awsCreds),
 		}
+		resourcePlan, err = ds.Plan(ctx, dynamoDBClientConfig)
 
 	default:
-		resultChan <- &service.ScalingError{
+		err = &service.ScalingError{
 			ServiceName:  "Unknown",
 			IdentifierId: "Unknown",
 			Err:          fmt.Errorf("unknown service"),
 		}
 	}
+
+	if err != nil {
+		err.Region = region
+		timedOut(ctx, []*service.ScalingError{err})
+		span.RecordError(err.Err)
+		tracing.Fail(span, "error planning service")
+		logging.FromContext(ctx).Error("error planning service", logging.Err(err.Err))
+		resultChan <- &planResult{err: err}
+		return
+	}
+	logging.FromContext(ctx).Debug("planned service", "current", resourcePlan.Current, "target", resourcePlan.Target)
+	if serviceConfig, ok := serviceScaleConfig.(config.ServiceScalingConfig); ok {
+		resourcePlan.Hooks = serviceConfig.GetHooks()
+		resourcePlan.Timeout = serviceConfig.GetTimeout()
+		resourcePlan.HealthGate = serviceConfig.GetHealthGate()
+	}
+	resultChan <- &planResult{resourcePlan: resourcePlan}
+}
+
+func (s *Scaler) applyPlan(ctx context.Context, scalingPlan *ScalingPlan, checkpoint *checkpoint) []*service.ScalingError {
+	// the hooks of the app only run when it's scaled, not when every resource is already at its target
+	if !scalingPlan.HasChanges() {
+		for _, resourcePlan := range scalingPlan.Resources {
+			checkpoint.update(ctx, resourcePlan, state.StatusUnchanged, nil)
+		}
+		return nil
+	}
+
+	vars := appHookVars(scalingPlan)
+	if err := runHooks(ctx, scalingPlan.ScalingConfig.Hooks, hooks.PrePhase, vars); err != nil {
+		logging.FromContext(ctx).Error("pre hook failed, scaling aborted", logging.Err(err))
+		return skipped(scalingPlan.Resources, err)
+	}
+
+	regionalPlans := make(map[string][]*service.ResourcePlan)
+	for _, resourcePlan := range scalingPlan.Resources {
+		regionalPlans[resourcePlan.Region] = append(regionalPlans[resourcePlan.Region], resourcePlan)
+	}
+
+	logging.FromContext(ctx).Info("scaling services")
+	failedServices := s.rollOut(ctx, scalingPlan, regionalPlans, vars, checkpoint)
+
+	postCtx, cancelPost := detach(ctx)
+	defer cancelPost()
+	if err := runHooks(postCtx, scalingPlan.ScalingConfig.Hooks, hooks.PostPhase, withVars(vars, hooks.OutcomeVar, runOutcome(failedServices))); err != nil {
+		logging.FromContext(ctx).Error("post hook failed", logging.Err(err))
+		failedServices = append(failedServices, &service.ScalingError{
+			Region:       appRegion,
+			ServiceName:  hookService,
+			IdentifierId: hooks.PostPhase,
+			Err:          err,
+		})
+	}
+	return failedServices
+}
+
+func (s *Scaler) scaleRegion(ctx context.Context, scalingPlan *ScalingPlan, region string, resourcePlans []*service.ResourcePlan, scalingRegion config.ScalingRegion, vars map[string]string, checkpoint *checkpoint, wg *sync.WaitGroup, resultChan chan *service.ScalingError) {
+	defer wg.Done()
+
+	ctx, span := tracing.Start(ctx, "ScaleRegion", tracing.RegionKey.String(region))
+	defer span.End()
+	ctx = logging.With(ctx, logging.RegionKey, region)
+	ctx, cancel := withTimeout(ctx, scalingRegion.Timeout)
+	defer cancel()
+
+	vars = withVars(vars, hooks.RegionVar, region)
+	regionVars := withVars(vars, hooks.ScopeVar, hooks.RegionScope)
+	// the hooks of the region only run when it's scaled, not when every resource is already at its target
+	changed := hasChanges(resourcePlans)
+	if changed {
+		if err := runHooks(ctx, scalingRegion.Hooks, hooks.PrePhase, regionVars); err != nil {
+			tracing.Fail(span, "pre hook failed")
+			logging.FromContext(ctx).Error("pre hook failed, scaling of region aborted", logging.Err(err))
+			for _, scalingError := range skipped(resourcePlans, err) {
+				resultChan <- scalingError
+			}
+			return
+		}
+	}
+
+	restoreAlarms := func() *service.ScalingError { return nil }
+	if changed {
+		restore, err := s.suppressAlarms(ctx, region, checkpoint, scalingPlan.ScalingConfig.SuppressAlarms, scalingRegion.SuppressAlarms)
+		if err != nil {
+			tracing.Fail(span, "error suppressing alarm actions")
+			logging.FromContext(ctx).Error("error suppressing alarm actions, scaling of region aborted", logging.Err(err))
+			for _, scalingError := range skipped(resourcePlans, err) {
+				resultChan <- scalingError
+			}
+			return
+		}
+		restoreAlarms = restore
+		defer restoreAlarms()
+	}
+
+	var mu sync.Mutex
+	var failedServices []*service.ScalingError
+	var scaled []*service.ResourcePlan
+	var serviceWg sync.WaitGroup
+	for _, resourcePlan := range resourcePlans {
+		serviceWg.Add(1)
+		go func(resourcePlan *service.ResourcePlan) {
+			defer serviceWg.Done()
+			errs := s.scaleService(ctx, scalingPlan, resourcePlan, vars, checkpoint)
+
+			mu.Lock()
+			failedServices = append(failedServices, errs...)
+			if len(errs) == 0 && resourcePlan.IsChange() {
+				scaled = append(scaled, resourcePlan)
+			}
+			mu.Unlock()
+		}(resourcePlan)
+	}
+	serviceWg.Wait()
+
+	if scalingRegion.HealthGate != nil && ctx.Err() == nil {
+		failedServices = append(failedServices, s.gateRegion(ctx, scalingPlan, region, scalingRegion.HealthGate, scaled, checkpoint)...)
+	}
+	if err := restoreAlarms(); err != nil {
+		failedServices = append(failedServices, err)
+	}
+
+	if changed {
+		postCtx, cancelPost := detach(ctx)
+		defer cancelPost()
+		if err := runHooks(postCtx, scalingRegion.Hooks, hooks.PostPhase, withVars(regionVars, hooks.OutcomeVar, runOutcome(failedServices))); err != nil {
+			logging.FromContext(ctx).Error("post hook failed", logging.Err(err))
+			failedServices = append(failedServices, &service.ScalingError{
+				Region:       region,
+				ServiceName:  hookService,
+				IdentifierId: hooks.PostPhase,
+				Err:          err,
+			})
+		}
+	}
+
+	for _, scalingError := range failedServices {
+		resultChan <- scalingError
+	}
+}
+
+func (s *Scaler) scaleService(ctx context.Context, scalingPlan *ScalingPlan, resourcePlan *service.ResourcePlan, vars map[string]string, checkpoint *checkpoint) []*service.ScalingError {
+	release := s.acquireSlot()
+	defer release()
+	if ctx.Err() != nil {
+		return skipped([]*service.ResourcePlan{resourcePlan}, contextErr(ctx))
+	}
+
+	ctx, span := tracing.Start(ctx, "ScaleService",
+		tracing.RegionKey.String(resourcePlan.Region),
+		tracing.ServiceKey.String(resourcePlan.ServiceName),
+		tracing.IdentifierKey.String(resourcePlan.IdentifierId))
+	defer span.End()
+	ctx = logging.With(ctx, logging.ServiceKey, resourcePlan.ServiceName, logging.IdentifierKey, resourcePlan.IdentifierId)
+
+	// scaling a resource already at its target fails for some services, e.g. kinesis, or triggers pointless work
+	if !resourcePlan.IsChange() {
+		logging.FromContext(ctx).Info("service unchanged, already at target")
+		checkpoint.update(ctx, resourcePlan, state.StatusUnchanged, nil)
+		return nil
+	}
+
+	vars = serviceHookVars(vars, resourcePlan)
+	if err := runHooks(ctx, resourcePlan.Hooks, hooks.PrePhase, vars); err != nil {
+		tracing.Fail(span, "pre hook failed")
+		logging.FromContext(ctx).Error("pre hook failed, scaling of service aborted", logging.Err(err))
+		return skipped([]*service.ResourcePlan{resourcePlan}, err)
+	}
+	if s.hooks.BeforeScale != nil {
+		if err := s.hooks.BeforeScale(ctx, resourcePlan); err != nil {
+			tracing.Fail(span, "before scale hook failed")
+			logging.FromContext(ctx).Error("before scale hook failed, scaling of service aborted", logging.Err(err))
+			return skipped([]*service.ResourcePlan{resourcePlan}, err)
+		}
+	}
+
+	// the health gate stops watching once the run is canceled, only the scaling in progress gets the grace
+	gateCtx := ctx
+	ctx, cancel := detach(ctx)
+	defer cancel()
+	checkpoint.update(ctx, resourcePlan, state.StatusInProgress, nil)
+	startedAt := time.Now()
+	applyCtx, cancelApply := withTimeout(ctx, resourcePlan.Timeout)
+	applyCtx = service.WithStepFunc(applyCtx, func(step int, reached service.Capacity) {
+		checkpoint.step(ctx, resourcePlan, step, reached)
+	})
+	errs := timedOut(applyCtx, resourcePlan.Apply(applyCtx))
+	cancelApply()
+	metrics.ObserveScaling(scalingPlan.ScalingConfig.Name, resourcePlan.Region, resourcePlan.ServiceName, time.Since(startedAt), scalingOutcome(errs))
+	if len(errs) == 0 && resourcePlan.HealthGate != nil {
+		// soaking doesn't need the slot and rolling back plans the resource again, which takes a slot
+		release()
+		errs = s.gateResource(gateCtx, scalingPlan, resourcePlan)
+	}
+	if len(errs) > 0 {
+		checkpoint.update(ctx, resourcePlan, state.StatusFailed, errs)
+	} else {
+		checkpoint.update(ctx, resourcePlan, state.StatusCompleted, nil)
+	}
+	if s.hooks.AfterScale != nil {
+		s.hooks.AfterScale(ctx, resourcePlan, errs)
+	}
+
+	if len(errs) > 0 {
+		tracing.Fail(span, "error scaling service")
+	} else {
+		logging.FromContext(ctx).Info("scaled service", "duration", time.Since(startedAt))
+	}
+	for _, err := range errs {
+		span.RecordError(err.Err)
+		logging.FromContext(ctx).Error("error scaling service", logging.Err(err.Err))
+	}
+
+	if err := runHooks(ctx, resourcePlan.Hooks, hooks.PostPhase, withVars(vars, hooks.OutcomeVar, runOutcome(errs))); err != nil {
+		logging.FromContext(ctx).Error("post hook failed", logging.Err(err))
+		errs = append(errs, &service.ScalingError{
+			Region:       resourcePlan.Region,
+			ServiceName:  resourcePlan.ServiceName,
+			IdentifierId: resourcePlan.IdentifierId,
+			Err:          err,
+		})
+	}
+	return errs
 }
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+)
+
+// Capacity holds the scalable dimensions of a resource keyed by the config field that sets them.
+type Capacity map[string]int
+
+func resolveCapacity(targets map[string]config.CapacityTarget, current Capacity) (Capacity, error) {
+	resolved := make(Capacity, len(targets))
+	for name, target := range targets {
+		currentValue, ok := current[name]
+		if target.IsRelative() && !ok {
+			return nil, fmt.Errorf("current value of %s is unknown, cannot resolve relative target %s", name, target)
+		}
+		resolved[name] = target.Resolve(currentValue)
+	}
+	return resolved, nil
+}
+
+// resolveTargetCapacity describes the resource whatever its targets, so resources already at their target are planned
+// as unchanged and never scaled again
+func resolveTargetCapacity(ctx context.Context, targets map[string]config.CapacityTarget, currentCapacity func(ctx context.Context) (Capacity, *ScalingError), serviceName Service, identifierId string) (Capacity, Capacity, *ScalingError) {
+	current, scalingErr := currentCapacity(ctx)
+	if scalingErr != nil {
+		return nil, nil, scalingErr
+	}
+	logging.FromContext(ctx).Debug("described current capacity", "current", current)
+
+	target, err := resolveCapacity(targets, current)
+	if err != nil {
+		return nil, nil, &ScalingError{
+			ServiceName:  string(serviceName),
+			IdentifierId: identifierId,
+			Err:          err,
+		}
+	}
+	return current, target, nil
+}
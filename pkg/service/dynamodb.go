@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
 	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"sync"
 )
 
@@ -16,20 +19,44 @@ const (
 var applicationAutoscalingClient *applicationautoscaling.Client
 
 type DynamoDBService struct {
-	Region string
-	Client *applicationautoscaling.Client
+	Region      string
+	Client      *applicationautoscaling.Client
+	TableClient *dynamodb.Client
 }
 
-func (ds DynamoDBService) ScaleService(ctx context.Context, dynamodbClientConfig config.DynamoDBServiceScalingConfig) []*ScalingError {
-	err := validateDynamoDBScalingConfig(dynamodbClientConfig)
+func (ds DynamoDBService) Plan(ctx context.Context, dynamodbClientConfig config.DynamoDBServiceScalingConfig) (*ResourcePlan, *ScalingError) {
+	currentCapacity, targetCapacity, err := resolveTargetCapacity(ctx, dynamodbClientConfig.Targets(), func(ctx context.Context) (Capacity, *ScalingError) {
+		return ds.CurrentCapacity(ctx, dynamodbClientConfig)
+	}, DynamoDB, dynamodbClientConfig.TableName)
 	if err != nil {
-		return []*ScalingError{err}
+		return nil, err
 	}
+
+	err = validateDynamoDBScalingConfig(dynamodbClientConfig, targetCapacity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResourcePlan{
+		Region:       ds.Region,
+		ServiceName:  string(DynamoDB),
+		IdentifierId: dynamodbClientConfig.TableName,
+		Current:      currentCapacity,
+		Target:       targetCapacity,
+		apply: func(ctx context.Context) []*ScalingError {
+			return ds.scale(ctx, dynamodbClientConfig, targetCapacity)
+		},
+		tag:    tagDynamoDB(ds.TableClient, dynamodbClientConfig.TableName),
+		stable: stableDynamoDB(ds.Client, dynamodbClientConfig.TableName),
+	}, nil
+}
+
+func (ds DynamoDBService) scale(ctx context.Context, dynamodbClientConfig config.DynamoDBServiceScalingConfig, targetCapacity Capacity) []*ScalingError {
 	applicationAutoscalingClient = ds.Client
 
 	errChan := make(chan *ScalingError)
 
-	go scaleDynamoDB(&ctx, dynamodbClientConfig, errChan)
+	go scaleDynamoDB(&ctx, dynamodbClientConfig, targetCapacity, errChan)
 
 	var scalingErrors []*ScalingError
 	for err := range errChan {
@@ -45,33 +72,64 @@ func (ds DynamoDBService) ScaleService(ctx context.Context, dynamodbClientConfig
 	}
 }
 
-func scaleDynamoDB(ctx *context.Context, dynamodbClientConfig config.DynamoDBServiceScalingConfig, errChan chan<- *ScalingError) {
+func (ds DynamoDBService) CurrentCapacity(ctx context.Context, dynamodbClientConfig config.DynamoDBServiceScalingConfig) (Capacity, *ScalingError) {
+	output, err := ds.Client.DescribeScalableTargets(ctx, &applicationautoscaling.DescribeScalableTargetsInput{
+		ServiceNamespace: DynamodbServiceNamespace,
+		ResourceIds:      []string{dynamodbClientConfig.TableName},
+	})
+	if err != nil {
+		return nil, &ScalingError{
+			ServiceName:  string(DynamoDB),
+			IdentifierId: dynamodbClientConfig.TableName,
+			Err:          err,
+		}
+	}
+
+	capacity := make(Capacity)
+	for _, scalableTarget := range output.ScalableTargets {
+		var prefix string
+		switch scalableTarget.ScalableDimension {
+		case types.ScalableDimensionDynamoDBTableReadCapacityUnits, types.ScalableDimensionDynamoDBIndexReadCapacityUnits:
+			prefix = "rcu"
+		case types.ScalableDimensionDynamoDBTableWriteCapacityUnits, types.ScalableDimensionDynamoDBIndexWriteCapacityUnits:
+			prefix = "wcu"
+		default:
+			continue
+		}
+		capacity[prefix+".minProvisionedCapacity"] = int(aws.ToInt32(scalableTarget.MinCapacity))
+		capacity[prefix+".maxProvisionedCapacity"] = int(aws.ToInt32(scalableTarget.MaxCapacity))
+	}
+
+	return capacity, nil
+}
+
+func scaleDynamoDB(ctx *context.Context, dynamodbClientConfig config.DynamoDBServiceScalingConfig, targetCapacity Capacity, errChan chan<- *ScalingError) {
 	defer close(errChan)
 
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
-		err := scaleRCU(*ctx, dynamodbClientConfig.RCU, dynamodbClientConfig.IsIndex, dynamodbClientConfig.TableName, &wg)
+		err := scaleRCU(*ctx, targetCapacity["rcu.minProvisionedCapacity"], targetCapacity["rcu.maxProvisionedCapacity"], dynamodbClientConfig.IsIndex, dynamodbClientConfig.TableName, &wg)
 		errChan <- err
 	}()
 
 	wg.Add(1)
 	go func() {
-		err := scaleWCU(*ctx, dynamodbClientConfig.WCU, dynamodbClientConfig.IsIndex, dynamodbClientConfig.TableName, &wg)
+		err := scaleWCU(*ctx, targetCapacity["wcu.minProvisionedCapacity"], targetCapacity["wcu.maxProvisionedCapacity"], dynamodbClientConfig.IsIndex, dynamodbClientConfig.TableName, &wg)
 		errChan <- err
 	}()
 
 	wg.Wait()
 }
 
-func scaleRCU(ctx context.Context, rcu config.RCU, isIndex bool, tableName string, wg *sync.WaitGroup) *ScalingError {
+func scaleRCU(ctx context.Context, minCapacity int, maxCapacity int, isIndex bool, tableName string, wg *sync.WaitGroup) *ScalingError {
 	defer wg.Done()
 
 	scalableDimension := types.ScalableDimensionDynamoDBTableReadCapacityUnits
 	if isIndex {
 		scalableDimension = types.ScalableDimensionDynamoDBIndexReadCapacityUnits
 	}
-	err := scaleDB(ctx, scalableDimension, tableName, int32(rcu.MinProvisionedCapacity), int32(rcu.MaxProvisionedCapacity))
+	err := scaleDB(ctx, scalableDimension, tableName, int32(minCapacity), int32(maxCapacity))
 	if err != nil {
 		return err
 	}
@@ -79,21 +137,20 @@ func scaleRCU(ctx context.Context, rcu config.RCU, isIndex bool, tableName strin
 	return nil
 }
 
-func scaleWCU(ctx context.Context, wcu config.WCU, isIndex bool, tableName string, wg *sync.WaitGroup) *ScalingError {
+func scaleWCU(ctx context.Context, minCapacity int, maxCapacity int, isIndex bool, tableName string, wg *sync.WaitGroup) *ScalingError {
 	defer wg.Done()
 
 	scalableDimension := types.ScalableDimensionDynamoDBTableWriteCapacityUnits
 	if isIndex {
 		scalableDimension = types.ScalableDimensionDynamoDBIndexWriteCapacityUnits
 	}
-	err := scaleDB(ctx, scalableDimension, tableName, int32(wcu.MinProvisionedCapacity), int32(wcu.MaxProvisionedCapacity))
+	err := scaleDB(ctx, scalableDimension, tableName, int32(minCapacity), int32(maxCapacity))
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
-
 func scaleDB(ctx context.Context, scalableDimension types.ScalableDimension, tableName string, minCapacity int32, maxCapacity int32) *ScalingError {
 	request := applicationautoscaling.RegisterScalableTargetInput{
 		MinCapacity:       &minCapacity,
@@ -102,6 +159,7 @@ func scaleDB(ctx context.Context, scalableDimension types.ScalableDimension, tab
 		ServiceNamespace:  DynamodbServiceNamespace,
 		ScalableDimension: scalableDimension,
 	}
+	logging.FromContext(ctx).Debug("registering scalable target", "dimension", scalableDimension, "minCapacity", minCapacity, "maxCapacity", maxCapacity)
 	_, err := applicationAutoscalingClient.RegisterScalableTarget(ctx, &request)
 	if err != nil {
 		return &ScalingError{
@@ -112,8 +170,8 @@ func scaleDB(ctx context.Context, scalableDimension types.ScalableDimension, tab
 	}
 	return nil
 }
-func validateDynamoDBScalingConfig(clientConfig config.DynamoDBServiceScalingConfig) *ScalingError {
-	if clientConfig.TableName == "" || validateRCUConfig(clientConfig.RCU) || validateWCUConfig(clientConfig.WCU) {
+func validateDynamoDBScalingConfig(clientConfig config.DynamoDBServiceScalingConfig, targetCapacity Capacity) *ScalingError {
+	if clientConfig.TableName == "" || validateRCUConfig(targetCapacity) || validateWCUConfig(targetCapacity) {
 		return &ScalingError{
 			ServiceName:  string(DynamoDB),
 			IdentifierId: clientConfig.TableName,
@@ -123,10 +181,10 @@ func validateDynamoDBScalingConfig(clientConfig config.DynamoDBServiceScalingCon
 	return nil
 }
 
-func validateRCUConfig(rcu config.RCU) bool {
-	return rcu.MinProvisionedCapacity < 0 || rcu.MaxProvisionedCapacity < 0
+func validateRCUConfig(targetCapacity Capacity) bool {
+	return targetCapacity["rcu.minProvisionedCapacity"] < 0 || targetCapacity["rcu.maxProvisionedCapacity"] < 0
 }
 
-func validateWCUConfig(wcu config.WCU) bool {
-	return wcu.MinProvisionedCapacity < 0 || wcu.MaxProvisionedCapacity < 0
+func validateWCUConfig(targetCapacity Capacity) bool {
+	return targetCapacity["wcu.minProvisionedCapacity"] < 0 || targetCapacity["wcu.maxProvisionedCapacity"] < 0
 }
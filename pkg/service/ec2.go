@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 )
 
@@ -12,15 +14,37 @@ type EC2Service struct {
 	Client *autoscaling.Client
 }
 
-func (ec2 EC2Service) ScaleService(ctx context.Context, ec2ClientConfig config.EC2ServiceScalingConfig) *ScalingError {
-	err := validateEc2ScalingConfig(ec2ClientConfig)
+func (ec2 EC2Service) Plan(ctx context.Context, ec2ClientConfig config.EC2ServiceScalingConfig) (*ResourcePlan, *ScalingError) {
+	currentCapacity, targetCapacity, err := resolveTargetCapacity(ctx, ec2ClientConfig.Targets(), func(ctx context.Context) (Capacity, *ScalingError) {
+		return ec2.CurrentCapacity(ctx, ec2ClientConfig)
+	}, EC2, ec2ClientConfig.AsgName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	desiredCapacity := int32(ec2ClientConfig.DesiredCount)
-	minSize := int32(ec2ClientConfig.MinCount)
-	maxSize := int32(ec2ClientConfig.MaxCount)
+	err = validateEc2ScalingConfig(ec2ClientConfig, targetCapacity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResourcePlan{
+		Region:       ec2.Region,
+		ServiceName:  string(EC2),
+		IdentifierId: ec2ClientConfig.AsgName,
+		Current:      currentCapacity,
+		Target:       targetCapacity,
+		apply: func(ctx context.Context) []*ScalingError {
+			return scalingErrors(ec2.scale(ctx, ec2ClientConfig, targetCapacity))
+		},
+		tag:    tagEC2(ec2.Client, ec2ClientConfig.AsgName),
+		stable: stableEC2(ec2.Client, ec2ClientConfig.AsgName),
+	}, nil
+}
+
+func (ec2 EC2Service) scale(ctx context.Context, ec2ClientConfig config.EC2ServiceScalingConfig, targetCapacity Capacity) *ScalingError {
+	desiredCapacity := int32(targetCapacity["desiredCount"])
+	minSize := int32(targetCapacity["minCount"])
+	maxSize := int32(targetCapacity["maxCount"])
 
 	input := autoscaling.UpdateAutoScalingGroupInput{
 		AutoScalingGroupName: &ec2ClientConfig.AsgName,
@@ -29,6 +53,7 @@ func (ec2 EC2Service) ScaleService(ctx context.Context, ec2ClientConfig config.E
 		MinSize:              &minSize,
 	}
 
+	logging.FromContext(ctx).Debug("updating auto scaling group", "minSize", minSize, "desiredCapacity", desiredCapacity, "maxSize", maxSize)
 	_, scaleError := ec2.Client.UpdateAutoScalingGroup(ctx, &input)
 
 	if scaleError != nil {
@@ -42,8 +67,31 @@ func (ec2 EC2Service) ScaleService(ctx context.Context, ec2ClientConfig config.E
 	return nil
 }
 
-func validateEc2ScalingConfig(clientConfig config.EC2ServiceScalingConfig) *ScalingError {
-	if clientConfig.AsgName == "" || clientConfig.DesiredCount <= 0 || clientConfig.MaxCount <= 0 || clientConfig.MinCount <= 0 {
+func (ec2 EC2Service) CurrentCapacity(ctx context.Context, ec2ClientConfig config.EC2ServiceScalingConfig) (Capacity, *ScalingError) {
+	output, err := ec2.Client.DescribeAutoScalingGroups(ctx, &autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []string{ec2ClientConfig.AsgName},
+	})
+	if err == nil && len(output.AutoScalingGroups) == 0 {
+		err = fmt.Errorf("auto scaling group not found")
+	}
+	if err != nil {
+		return nil, &ScalingError{
+			ServiceName:  string(EC2),
+			IdentifierId: ec2ClientConfig.AsgName,
+			Err:          err,
+		}
+	}
+
+	group := output.AutoScalingGroups[0]
+	return Capacity{
+		"minCount":     int(aws.ToInt32(group.MinSize)),
+		"desiredCount": int(aws.ToInt32(group.DesiredCapacity)),
+		"maxCount":     int(aws.ToInt32(group.MaxSize)),
+	}, nil
+}
+
+func validateEc2ScalingConfig(clientConfig config.EC2ServiceScalingConfig, targetCapacity Capacity) *ScalingError {
+	if clientConfig.AsgName == "" || targetCapacity["desiredCount"] <= 0 || targetCapacity["maxCount"] <= 0 || targetCapacity["minCount"] <= 0 {
 		return &ScalingError{
 			ServiceName:  string(EC2),
 			IdentifierId: clientConfig.AsgName,
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/elasticache"
 )
 
@@ -21,25 +23,98 @@ type ElasticCacheService struct {
 	Client *elasticache.Client
 }
 
-func (e ElasticCacheService) ScaleService(ctx context.Context, c config.ElasticCacheServiceScalingConfig, isScalingUp bool) *ScalingError {
-	err := validateElasticCacheScalingConfig(c, isScalingUp, getElasticCacheEngine(c.Engine))
+func (e ElasticCacheService) Plan(ctx context.Context, c config.ElasticCacheServiceScalingConfig, isScalingUp bool) (*ResourcePlan, *ScalingError) {
+	currentCapacity, targetCapacity, err := resolveTargetCapacity(ctx, c.Targets(), func(ctx context.Context) (Capacity, *ScalingError) {
+		return e.CurrentCapacity(ctx, c)
+	}, ElasticCache, c.ClusterId)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	err = validateElasticCacheScalingConfig(c, targetCapacity, isScalingUp, getElasticCacheEngine(c.Engine))
+	if err != nil {
+		return nil, err
 	}
 
+	return &ResourcePlan{
+		Region:       e.Region,
+		ServiceName:  string(ElasticCache),
+		IdentifierId: c.ClusterId,
+		Current:      currentCapacity,
+		Target:       targetCapacity,
+		apply: func(ctx context.Context) []*ScalingError {
+			return scalingErrors(e.scale(ctx, c, targetCapacity, isScalingUp))
+		},
+		tag:    tagElasticCache(e.Client, c.ClusterId, getElasticCacheEngine(c.Engine)),
+		stable: stableElasticCache(e.Client, c.ClusterId, getElasticCacheEngine(c.Engine)),
+	}, nil
+}
+
+func (e ElasticCacheService) scale(ctx context.Context, c config.ElasticCacheServiceScalingConfig, targetCapacity Capacity, isScalingUp bool) *ScalingError {
 	switch c.Engine {
 	case "redis":
-		return scaleRedis(ctx, c, isScalingUp, e.Client)
+		return scaleRedis(ctx, c, targetCapacity, isScalingUp, e.Client)
 	case "memcached":
-		return scaleMemcached(ctx, c, isScalingUp, e.Client)
+		return scaleMemcached(ctx, c, targetCapacity, isScalingUp, e.Client)
 	}
 
 	return nil
 }
 
-func scaleRedis(ctx context.Context, clientConfig config.ElasticCacheServiceScalingConfig, up bool, client *elasticache.Client) *ScalingError {
+func (e ElasticCacheService) CurrentCapacity(ctx context.Context, c config.ElasticCacheServiceScalingConfig) (Capacity, *ScalingError) {
+	var nodeCount int
+	var err error
+	switch getElasticCacheEngine(c.Engine) {
+	case Redis:
+		nodeCount, err = currentRedisNodeCount(ctx, c.ClusterId, e.Client)
+	case Memcached:
+		nodeCount, err = currentMemcachedNodeCount(ctx, c.ClusterId, e.Client)
+	default:
+		err = fmt.Errorf("unsupported engine %s", c.Engine)
+	}
+
+	if err != nil {
+		return nil, &ScalingError{
+			ServiceName:  string(ElasticCache),
+			IdentifierId: c.ClusterId,
+			Err:          err,
+		}
+	}
+
+	return Capacity{
+		"nodeCount": nodeCount,
+	}, nil
+}
 
-	nodeCount := int32(clientConfig.NodeCount)
+func currentRedisNodeCount(ctx context.Context, clusterId string, client *elasticache.Client) (int, error) {
+	output, err := client.DescribeReplicationGroups(ctx, &elasticache.DescribeReplicationGroupsInput{
+		ReplicationGroupId: &clusterId,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(output.ReplicationGroups) == 0 {
+		return 0, fmt.Errorf("replication group not found")
+	}
+	return len(output.ReplicationGroups[0].NodeGroups), nil
+}
+
+func currentMemcachedNodeCount(ctx context.Context, clusterId string, client *elasticache.Client) (int, error) {
+	output, err := client.DescribeCacheClusters(ctx, &elasticache.DescribeCacheClustersInput{
+		CacheClusterId: &clusterId,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(output.CacheClusters) == 0 {
+		return 0, fmt.Errorf("cache cluster not found")
+	}
+	return int(aws.ToInt32(output.CacheClusters[0].NumCacheNodes)), nil
+}
+
+func scaleRedis(ctx context.Context, clientConfig config.ElasticCacheServiceScalingConfig, targetCapacity Capacity, up bool, client *elasticache.Client) *ScalingError {
+
+	nodeCount := int32(targetCapacity["nodeCount"])
 	applyImmediately := true
 	input := elasticache.ModifyReplicationGroupShardConfigurationInput{
 		ApplyImmediately:   &applyImmediately,
@@ -51,7 +126,11 @@ func scaleRedis(ctx context.Context, clientConfig config.ElasticCacheServiceScal
 		input.NodeGroupsToRemove = clientConfig.NodesToDelete
 	}
 
+	logging.FromContext(ctx).Debug("modifying replication group shard configuration", "nodeGroupCount", nodeCount, "nodeGroupsToRemove", input.NodeGroupsToRemove)
 	_, err := client.ModifyReplicationGroupShardConfiguration(ctx, &input)
+	if err == nil {
+		return nil
+	}
 	return &ScalingError{
 		ServiceName:  string(ElasticCache),
 		IdentifierId: clientConfig.ClusterId,
@@ -59,8 +138,8 @@ func scaleRedis(ctx context.Context, clientConfig config.ElasticCacheServiceScal
 	}
 }
 
-func scaleMemcached(ctx context.Context, clientConfig config.ElasticCacheServiceScalingConfig, up bool, client *elasticache.Client) *ScalingError {
-	nodeCount := int32(clientConfig.NodeCount)
+func scaleMemcached(ctx context.Context, clientConfig config.ElasticCacheServiceScalingConfig, targetCapacity Capacity, up bool, client *elasticache.Client) *ScalingError {
+	nodeCount := int32(targetCapacity["nodeCount"])
 	applyImmediately := true
 	input := elasticache.ModifyCacheClusterInput{
 		ApplyImmediately: &applyImmediately,
@@ -72,7 +151,11 @@ func scaleMemcached(ctx context.Context, clientConfig config.ElasticCacheService
 		input.CacheNodeIdsToRemove = clientConfig.NodesToDelete
 	}
 
+	logging.FromContext(ctx).Debug("modifying cache cluster", "numCacheNodes", nodeCount, "cacheNodeIdsToRemove", input.CacheNodeIdsToRemove)
 	_, err := client.ModifyCacheCluster(ctx, &input)
+	if err == nil {
+		return nil
+	}
 	return &ScalingError{
 		ServiceName:  string(ElasticCache),
 		IdentifierId: clientConfig.ClusterId,
@@ -91,13 +174,13 @@ func getElasticCacheEngine(s string) ElasticCacheEngine {
 	}
 }
 
-func validateElasticCacheScalingConfig(clientConfig config.ElasticCacheServiceScalingConfig, isScalingUp bool, engine ElasticCacheEngine) *ScalingError {
+func validateElasticCacheScalingConfig(clientConfig config.ElasticCacheServiceScalingConfig, targetCapacity Capacity, isScalingUp bool, engine ElasticCacheEngine) *ScalingError {
 	err := &ScalingError{
 		ServiceName:  string(ElasticCache),
 		IdentifierId: clientConfig.ClusterId,
 		Err:          fmt.Errorf("invalid scaling config"),
 	}
-	if engine == Other || clientConfig.ClusterId == "" || clientConfig.NodeCount <= 0 {
+	if engine == Other || clientConfig.ClusterId == "" || targetCapacity["nodeCount"] <= 0 {
 		return err
 	}
 
@@ -4,41 +4,153 @@ import (
 	"context"
 	"fmt"
 	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/kinesis"
 	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"time"
 )
 
+// streamPollInterval is how often the status of a stream is checked while it's resharded
+const streamPollInterval = 10 * time.Second
+
+// streamWaitTimeout bounds waiting for a stream to be active when the resource has no timeout of its own
+const streamWaitTimeout = 30 * time.Minute
+
 type KinesisService struct {
 	Region string
 	Client *kinesis.Client
 }
 
-func (k KinesisService) ScaleService(ctx context.Context, kinesisServiceScalingConfig config.KinesisServiceScalingConfig) *ScalingError {
-	err := validateKinesisScalingConfig(kinesisServiceScalingConfig)
+func (k KinesisService) Plan(ctx context.Context, kinesisServiceScalingConfig config.KinesisServiceScalingConfig) (*ResourcePlan, *ScalingError) {
+	currentCapacity, targetCapacity, err := resolveTargetCapacity(ctx, kinesisServiceScalingConfig.Targets(), func(ctx context.Context) (Capacity, *ScalingError) {
+		return k.CurrentCapacity(ctx, kinesisServiceScalingConfig)
+	}, Kinesis, kinesisServiceScalingConfig.StreamArn)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	targetShareCount := int32(kinesisServiceScalingConfig.DesiredShardCount)
-	input := kinesis.UpdateShardCountInput{
-		StreamName:       &kinesisServiceScalingConfig.StreamArn,
-		TargetShardCount: &targetShareCount,
-		ScalingType:      types.ScalingTypeUniformScaling,
+	err = validateKinesisScalingConfig(kinesisServiceScalingConfig, targetCapacity)
+	if err != nil {
+		return nil, err
 	}
 
-	_, scaleError := k.Client.UpdateShardCount(ctx, &input)
-	if scaleError != nil {
+	return &ResourcePlan{
+		Region:       k.Region,
+		ServiceName:  string(Kinesis),
+		IdentifierId: kinesisServiceScalingConfig.StreamArn,
+		Current:      currentCapacity,
+		Target:       targetCapacity,
+		apply: func(ctx context.Context) []*ScalingError {
+			return scalingErrors(k.scale(ctx, kinesisServiceScalingConfig, targetCapacity))
+		},
+		tag:    tagKinesis(k.Client, kinesisServiceScalingConfig.StreamArn),
+		stable: stableKinesis(k.Client, kinesisServiceScalingConfig.StreamArn),
+	}, nil
+}
+
+// scale reshards the stream in steps, as a reshard at most doubles or halves the open shards, and waits for the
+// stream to be active after each step. The steps start from the live shard count, so a resumed run continues from the
+// last step it completed.
+func (k KinesisService) scale(ctx context.Context, kinesisServiceScalingConfig config.KinesisServiceScalingConfig, targetCapacity Capacity) *ScalingError {
+	streamArn := kinesisServiceScalingConfig.StreamArn
+	fail := func(err error) *ScalingError {
 		return &ScalingError{
 			ServiceName:  string(Kinesis),
-			IdentifierId: kinesisServiceScalingConfig.StreamArn,
-			Err:          scaleError,
+			IdentifierId: streamArn,
+			Err:          err,
+		}
+	}
+
+	target := targetCapacity["desiredShardCount"]
+	current, err := k.waitForStream(ctx, streamArn)
+	if err != nil {
+		return fail(err)
+	}
+
+	for step := 1; current != target; step++ {
+		next := nextShardCount(current, target)
+		logging.FromContext(ctx).Debug("updating shard count", "step", step, "targetShardCount", next)
+		_, err := k.Client.UpdateShardCount(ctx, &kinesis.UpdateShardCountInput{
+			StreamARN:        &streamArn,
+			TargetShardCount: aws.Int32(int32(next)),
+			ScalingType:      types.ScalingTypeUniformScaling,
+		})
+		if err != nil {
+			return fail(err)
+		}
+
+		reached, err := k.waitForStream(ctx, streamArn)
+		if err != nil {
+			return fail(err)
 		}
+		if reached != next {
+			return fail(fmt.Errorf("stream has %d open shards after resharding to %d", reached, next))
+		}
+		current = reached
+		reportStep(ctx, step, Capacity{"desiredShardCount": current})
 	}
 	return nil
 }
 
-func validateKinesisScalingConfig(clientConfig config.KinesisServiceScalingConfig) *ScalingError {
-	if clientConfig.StreamArn == "" || clientConfig.DesiredShardCount <= 0 {
+// nextShardCount returns the shard count of the next step from current to target
+func nextShardCount(current int, target int) int {
+	if target > current {
+		return min(target, current*2)
+	}
+	return max(target, (current+1)/2)
+}
+
+// waitForStream waits until the stream is active, as it can only be resharded then, and returns its open shards.
+// The wait is bounded by the resource, region or app timeout, or by streamWaitTimeout when none applies.
+func (k KinesisService) waitForStream(ctx context.Context, streamArn string) (int, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, streamWaitTimeout)
+		defer cancel()
+	}
+
+	for {
+		output, err := k.Client.DescribeStreamSummary(ctx, &kinesis.DescribeStreamSummaryInput{
+			StreamARN: &streamArn,
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		summary := output.StreamDescriptionSummary
+		if summary.StreamStatus == types.StreamStatusActive {
+			return int(aws.ToInt32(summary.OpenShardCount)), nil
+		}
+
+		logging.FromContext(ctx).Debug("waiting for stream to be active", "status", summary.StreamStatus)
+		select {
+		case <-ctx.Done():
+			return 0, fmt.Errorf("error waiting for stream to be active: %w", context.Cause(ctx))
+		case <-time.After(streamPollInterval):
+		}
+	}
+}
+
+func (k KinesisService) CurrentCapacity(ctx context.Context, kinesisServiceScalingConfig config.KinesisServiceScalingConfig) (Capacity, *ScalingError) {
+	output, err := k.Client.DescribeStreamSummary(ctx, &kinesis.DescribeStreamSummaryInput{
+		StreamARN: &kinesisServiceScalingConfig.StreamArn,
+	})
+	if err != nil {
+		return nil, &ScalingError{
+			ServiceName:  string(Kinesis),
+			IdentifierId: kinesisServiceScalingConfig.StreamArn,
+			Err:          err,
+		}
+	}
+
+	return Capacity{
+		"desiredShardCount": int(aws.ToInt32(output.StreamDescriptionSummary.OpenShardCount)),
+	}, nil
+}
+
+func validateKinesisScalingConfig(clientConfig config.KinesisServiceScalingConfig, targetCapacity Capacity) *ScalingError {
+	if clientConfig.StreamArn == "" || targetCapacity["desiredShardCount"] <= 0 {
 		return &ScalingError{
 			ServiceName:  string(Kinesis),
 			IdentifierId: clientConfig.StreamArn,
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestNextShardCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int
+		target  int
+		want    int
+	}{
+		{name: "double", current: 4, target: 16, want: 8},
+		{name: "up to target", current: 4, target: 6, want: 6},
+		{name: "exactly double", current: 4, target: 8, want: 8},
+		{name: "halve", current: 16, target: 2, want: 8},
+		{name: "halve odd", current: 5, target: 1, want: 3},
+		{name: "down to target", current: 8, target: 6, want: 6},
+		{name: "from one", current: 1, target: 3, want: 2},
+		{name: "at target", current: 4, target: 4, want: 4},
+		{name: "target below half", current: 10, target: 1, want: 5},
+		{name: "target above double", current: 3, target: 100, want: 6},
+		{name: "from one to far above", current: 1, target: 64, want: 2},
+		{name: "one to one", current: 1, target: 1, want: 1},
+		{name: "down to one", current: 2, target: 1, want: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := nextShardCount(test.current, test.target); got != test.want {
+				t.Errorf("nextShardCount(%d, %d) = %d, want %d", test.current, test.target, got, test.want)
+			}
+		})
+	}
+}
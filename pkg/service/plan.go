@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"time"
+)
+
+type ResourcePlan struct {
+	Region       string
+	ServiceName  string
+	IdentifierId string
+	Current      Capacity
+	Target       Capacity
+	Hooks        *config.HooksConfig
+	Timeout      time.Duration
+	HealthGate   *config.HealthGate
+
+	apply  func(ctx context.Context) []*ScalingError
+	tag    tagFunc
+	stable stableFunc
+}
+
+func (p *ResourcePlan) Apply(ctx context.Context) []*ScalingError {
+	logging.FromContext(ctx).Debug("scaling resource", "current", p.Current, "target", p.Target)
+	errs := p.apply(ctx)
+	for _, err := range errs {
+		err.Region = p.Region
+	}
+	return errs
+}
+
+func (p *ResourcePlan) TagExpiry(ctx context.Context, expiresAt time.Time) error {
+	if p.tag == nil {
+		return nil
+	}
+	return p.tag(ctx, ExpiryTagKey, expiryTagValue(expiresAt))
+}
+
+func (p *ResourcePlan) UntagExpiry(ctx context.Context) error {
+	if p.tag == nil {
+		return nil
+	}
+	return p.tag(ctx, ExpiryTagKey, nil)
+}
+
+// Stable returns an error telling why the scaled resource hasn't settled at its new capacity yet, e.g. the instances
+// of an ASG still launching
+func (p *ResourcePlan) Stable(ctx context.Context) error {
+	if p.stable == nil {
+		return nil
+	}
+	return p.stable(ctx)
+}
+
+func (p *ResourcePlan) IsChange() bool {
+	if p.Current == nil {
+		return true
+	}
+
+	for name, value := range p.Target {
+		if current, ok := p.Current[name]; !ok || current != value {
+			return true
+		}
+	}
+	return false
+}
+
+type stepKey struct{}
+
+// StepFunc is called after each step of a resource scaled in steps, with the capacity the step reached
+type StepFunc func(step int, reached Capacity)
+
+// WithStepFunc returns a context whose resources scaled in steps report their steps to fn
+func WithStepFunc(ctx context.Context, fn StepFunc) context.Context {
+	return context.WithValue(ctx, stepKey{}, fn)
+}
+
+func reportStep(ctx context.Context, step int, reached Capacity) {
+	if fn, ok := ctx.Value(stepKey{}).(StepFunc); ok {
+		fn(step, reached)
+	}
+}
+
+func scalingErrors(err *ScalingError) []*ScalingError {
+	if err == nil {
+		return nil
+	}
+	return []*ScalingError{err}
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"strconv"
+	"strings"
+)
+
+var ErrScheduledActionUnsupported = errors.New("scheduled actions are not supported")
+
+var cronWeekdays = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT", "SUN"}
+
+// ScheduledAction scales a resource on a schedule enforced by AWS instead of a run of the CLI
+type ScheduledAction struct {
+	Region       string
+	ServiceName  string
+	IdentifierId string
+	Name         string
+	Schedule     string
+	Timezone     string
+	Target       Capacity
+
+	put func(ctx context.Context) []*ScalingError
+}
+
+func (a *ScheduledAction) Put(ctx context.Context) []*ScalingError {
+	errs := a.put(ctx)
+	for _, err := range errs {
+		err.Region = a.Region
+	}
+	return errs
+}
+
+func (ec2 EC2Service) ScheduledAction(ec2ClientConfig config.EC2ServiceScalingConfig, name string, schedule config.Schedule) (*ScheduledAction, *ScalingError) {
+	targetCapacity, err := scheduledCapacity(ec2ClientConfig.Targets(), EC2, ec2ClientConfig.AsgName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateEc2ScalingConfig(ec2ClientConfig, targetCapacity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScheduledAction{
+		Region:       ec2.Region,
+		ServiceName:  string(EC2),
+		IdentifierId: ec2ClientConfig.AsgName,
+		Name:         name,
+		Schedule:     schedule.Cron,
+		Timezone:     schedule.Timezone,
+		Target:       targetCapacity,
+		put: func(ctx context.Context) []*ScalingError {
+			input := autoscaling.PutScheduledUpdateGroupActionInput{
+				AutoScalingGroupName: &ec2ClientConfig.AsgName,
+				ScheduledActionName:  &name,
+				Recurrence:           &schedule.Cron,
+				MinSize:              aws.Int32(int32(targetCapacity["minCount"])),
+				DesiredCapacity:      aws.Int32(int32(targetCapacity["desiredCount"])),
+				MaxSize:              aws.Int32(int32(targetCapacity["maxCount"])),
+			}
+			if schedule.Timezone != "" {
+				input.TimeZone = &schedule.Timezone
+			}
+
+			if _, err := ec2.Client.PutScheduledUpdateGroupAction(ctx, &input); err != nil {
+				return scalingErrors(&ScalingError{
+					ServiceName:  string(EC2),
+					IdentifierId: ec2ClientConfig.AsgName,
+					Err:          err,
+				})
+			}
+			return nil
+		},
+	}, nil
+}
+
+func (ds DynamoDBService) ScheduledAction(dynamodbClientConfig config.DynamoDBServiceScalingConfig, name string, schedule config.Schedule) (*ScheduledAction, *ScalingError) {
+	targetCapacity, err := scheduledCapacity(dynamodbClientConfig.Targets(), DynamoDB, dynamodbClientConfig.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateDynamoDBScalingConfig(dynamodbClientConfig, targetCapacity)
+	if err != nil {
+		return nil, err
+	}
+
+	expression, cronErr := awsCronExpression(schedule.Cron)
+	if cronErr != nil {
+		return nil, &ScalingError{
+			ServiceName:  string(DynamoDB),
+			IdentifierId: dynamodbClientConfig.TableName,
+			Err:          cronErr,
+		}
+	}
+
+	readDimension := types.ScalableDimensionDynamoDBTableReadCapacityUnits
+	writeDimension := types.ScalableDimensionDynamoDBTableWriteCapacityUnits
+	if dynamodbClientConfig.IsIndex {
+		readDimension = types.ScalableDimensionDynamoDBIndexReadCapacityUnits
+		writeDimension = types.ScalableDimensionDynamoDBIndexWriteCapacityUnits
+	}
+
+	return &ScheduledAction{
+		Region:       ds.Region,
+		ServiceName:  string(DynamoDB),
+		IdentifierId: dynamodbClientConfig.TableName,
+		Name:         name,
+		Schedule:     expression,
+		Timezone:     schedule.Timezone,
+		Target:       targetCapacity,
+		put: func(ctx context.Context) []*ScalingError {
+			var errs []*ScalingError
+			actions := []struct {
+				suffix    string
+				dimension types.ScalableDimension
+				min       int
+				max       int
+			}{
+				{"rcu", readDimension, targetCapacity["rcu.minProvisionedCapacity"], targetCapacity["rcu.maxProvisionedCapacity"]},
+				{"wcu", writeDimension, targetCapacity["wcu.minProvisionedCapacity"], targetCapacity["wcu.maxProvisionedCapacity"]},
+			}
+
+			for _, action := range actions {
+				input := applicationautoscaling.PutScheduledActionInput{
+					ServiceNamespace:    DynamodbServiceNamespace,
+					ResourceId:          &dynamodbClientConfig.TableName,
+					ScalableDimension:   action.dimension,
+					ScheduledActionName: aws.String(fmt.Sprintf("%s-%s", name, action.suffix)),
+					Schedule:            &expression,
+					ScalableTargetAction: &types.ScalableTargetAction{
+						MinCapacity: aws.Int32(int32(action.min)),
+						MaxCapacity: aws.Int32(int32(action.max)),
+					},
+				}
+				if schedule.Timezone != "" {
+					input.Timezone = &schedule.Timezone
+				}
+
+				if _, err := ds.Client.PutScheduledAction(ctx, &input); err != nil {
+					errs = append(errs, &ScalingError{
+						ServiceName:  string(DynamoDB),
+						IdentifierId: dynamodbClientConfig.TableName,
+						Err:          fmt.Errorf("error putting %s scheduled action: %w", action.suffix, err),
+					})
+				}
+			}
+			return errs
+		},
+	}, nil
+}
+
+func scheduledCapacity(targets map[string]config.CapacityTarget, serviceName Service, identifierId string) (Capacity, *ScalingError) {
+	for name, target := range targets {
+		if target.IsRelative() {
+			return nil, &ScalingError{
+				ServiceName:  string(serviceName),
+				IdentifierId: identifierId,
+				Err:          fmt.Errorf("relative target %s of %s can't be exported as a scheduled action", target, name),
+			}
+		}
+	}
+
+	targetCapacity, err := resolveCapacity(targets, nil)
+	if err != nil {
+		return nil, &ScalingError{
+			ServiceName:  string(serviceName),
+			IdentifierId: identifierId,
+			Err:          err,
+		}
+	}
+	return targetCapacity, nil
+}
+
+// awsCronExpression converts a standard cron expression to the cron(...) format of Application Auto Scaling,
+// which needs a year field, ? in either day field and numbers weekdays from 1
+func awsCronExpression(spec string) (string, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return "", fmt.Errorf("cron expression %s must have 5 fields to be exported", spec)
+	}
+
+	dayOfMonth, dayOfWeek := fields[2], fields[4]
+	switch {
+	case dayOfWeek == "*":
+		dayOfWeek = "?"
+	case dayOfMonth == "*":
+		dayOfMonth = "?"
+		var err error
+		dayOfWeek, err = awsWeekdays(dayOfWeek)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("cron expression %s restricts both day of month and day of week, which can't be exported", spec)
+	}
+
+	return fmt.Sprintf("cron(%s %s %s %s %s *)", fields[0], fields[1], dayOfMonth, fields[3], dayOfWeek), nil
+}
+
+// awsWeekdays converts the day of week field to weekday names. Ranges ending on 7, which is Sunday again, and steps
+// are expanded to lists, as the ranges of Application Auto Scaling can't wrap around the end of the week.
+func awsWeekdays(field string) (string, error) {
+	var items []string
+	seen := make(map[string]bool)
+	add := func(item string) {
+		if !seen[item] {
+			seen[item] = true
+			items = append(items, item)
+		}
+	}
+
+	for _, item := range strings.Split(field, ",") {
+		days, step, hasStep := strings.Cut(item, "/")
+		if days == "*" {
+			add(item)
+			continue
+		}
+
+		first, last, isRange := strings.Cut(days, "-")
+		start, err := weekday(first)
+		if err != nil {
+			return "", err
+		}
+		if !isRange && !hasStep {
+			add(cronWeekdays[start])
+			continue
+		}
+
+		// a day with a step runs to the end of the week
+		end := len(cronWeekdays) - 2
+		if isRange {
+			if end, err = weekday(last); err != nil {
+				return "", err
+			}
+		}
+		if end < start {
+			return "", fmt.Errorf("invalid day of week range %s", days)
+		}
+
+		interval := 1
+		if hasStep {
+			if interval, err = strconv.Atoi(step); err != nil || interval <= 0 {
+				return "", fmt.Errorf("invalid day of week step %s", step)
+			}
+		}
+		if !hasStep && end < len(cronWeekdays)-1 {
+			add(cronWeekdays[start] + "-" + cronWeekdays[end])
+			continue
+		}
+
+		for day := start; day <= end; day += interval {
+			add(cronWeekdays[day])
+		}
+	}
+	return strings.Join(items, ","), nil
+}
+
+// weekday parses a day of week number from 0 to 7 or a day name
+func weekday(day string) (int, error) {
+	if n, err := strconv.Atoi(day); err == nil {
+		if n < 0 || n >= len(cronWeekdays) {
+			return 0, fmt.Errorf("invalid day of week %s", day)
+		}
+		return n, nil
+	}
+
+	for n, name := range cronWeekdays[:len(cronWeekdays)-1] {
+		if strings.EqualFold(name, day) {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day of week %s", day)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestAwsCronExpression(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "0 8 * * *", want: "cron(0 8 * * ? *)"},
+		{spec: "0 8 1 * *", want: "cron(0 8 1 * ? *)"},
+		{spec: "0 8 * * 1-5", want: "cron(0 8 ? * MON-FRI *)"},
+		{spec: "30 20 * 1-6 0", want: "cron(30 20 ? 1-6 SUN *)"},
+		{spec: "0 8 * * 5-7", want: "cron(0 8 ? * FRI,SAT,SUN *)"},
+		{spec: "0 8 1 * 1", wantErr: true},
+		{spec: "0 8 * *", wantErr: true},
+		{spec: "0 0 8 * * *", wantErr: true},
+		{spec: "0 8 * * 8", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			got, err := awsCronExpression(test.spec)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("awsCronExpression(%q) error = %v, want error %v", test.spec, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("awsCronExpression(%q) = %q, want %q", test.spec, got, test.want)
+			}
+		})
+	}
+}
+
+func TestAwsWeekdays(t *testing.T) {
+	tests := []struct {
+		field   string
+		want    string
+		wantErr bool
+	}{
+		{field: "0", want: "SUN"},
+		{field: "7", want: "SUN"},
+		{field: "1,3,5", want: "MON,WED,FRI"},
+		{field: "1-5", want: "MON-FRI"},
+		{field: "0-6", want: "SUN-SAT"},
+		{field: "5-7", want: "FRI,SAT,SUN"},
+		{field: "0-7", want: "SUN,MON,TUE,WED,THU,FRI,SAT"},
+		{field: "6-7,1", want: "SAT,SUN,MON"},
+		{field: "0,7", want: "SUN"},
+		{field: "1-5/2", want: "MON,WED,FRI"},
+		{field: "1/2", want: "MON,WED,FRI"},
+		{field: "*", want: "*"},
+		{field: "*/2", want: "*/2"},
+		{field: "mon-fri", want: "MON-FRI"},
+		{field: "FRI", want: "FRI"},
+		{field: "5-1", wantErr: true},
+		{field: "8", wantErr: true},
+		{field: "-1", wantErr: true},
+		{field: "1-5/0", wantErr: true},
+		{field: "funday", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.field, func(t *testing.T) {
+			got, err := awsWeekdays(test.field)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("awsWeekdays(%q) error = %v, want error %v", test.field, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("awsWeekdays(%q) = %q, want %q", test.field, got, test.want)
+			}
+		})
+	}
+}
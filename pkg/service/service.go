@@ -2,12 +2,20 @@ package service
 
 import (
 	"context"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/metrics"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/tracing"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/elasticache"
 	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
@@ -51,6 +59,8 @@ func NewConfig(ctx context.Context, region string, assumeRoleArn string) (*aws.C
 
 	cfg.Credentials = creds
 	cfg.Region = region
+	metrics.InstrumentAPI(&cfg)
+	tracing.InstrumentAPI(&cfg)
 
 	return &cfg, nil
 }
@@ -79,6 +89,15 @@ func assumeRoleCreds(ctx context.Context, cfg aws.Config, assumeRoleArn string)
 	return credsProvider, nil
 }
 
+// CallerIdentity returns the ARN of the identity the aws config acts as
+func CallerIdentity(ctx context.Context, cfg *aws.Config) (string, error) {
+	output, err := sts.NewFromConfig(*cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(output.Arn), nil
+}
+
 func NewKinesisClient(cfg *aws.Config) *kinesis.Client {
 	return kinesis.NewFromConfig(*cfg)
 }
@@ -94,3 +113,27 @@ func NewAutoScalingClient(cfg *aws.Config) *autoscaling.Client {
 func NewApplicationAutoScalingClient(cfg *aws.Config) *applicationautoscaling.Client {
 	return applicationautoscaling.NewFromConfig(*cfg)
 }
+
+func NewDynamoDBClient(cfg *aws.Config) *dynamodb.Client {
+	return dynamodb.NewFromConfig(*cfg)
+}
+
+func NewS3Client(cfg *aws.Config) *s3.Client {
+	return s3.NewFromConfig(*cfg)
+}
+
+func NewSNSClient(cfg *aws.Config) *sns.Client {
+	return sns.NewFromConfig(*cfg)
+}
+
+func NewCloudWatchClient(cfg *aws.Config) *cloudwatch.Client {
+	return cloudwatch.NewFromConfig(*cfg)
+}
+
+func NewServiceQuotasClient(cfg *aws.Config) *servicequotas.Client {
+	return servicequotas.NewFromConfig(*cfg)
+}
+
+func NewEC2Client(cfg *aws.Config) *ec2.Client {
+	return ec2.NewFromConfig(*cfg)
+}
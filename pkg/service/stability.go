@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
+	applicationautoscalingtypes "github.com/aws/aws-sdk-go-v2/service/applicationautoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	kinesistypes "github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+)
+
+// elasticCacheAvailable is the status of replication groups and cache clusters that aren't being modified
+const elasticCacheAvailable = "available"
+
+// stableFunc returns an error telling why the resource hasn't settled at its new capacity yet
+type stableFunc func(ctx context.Context) error
+
+func stableEC2(client *autoscaling.Client, asgName string) stableFunc {
+	return func(ctx context.Context) error {
+		output, err := client.DescribeAutoScalingGroups(ctx, &autoscaling.DescribeAutoScalingGroupsInput{
+			AutoScalingGroupNames: []string{asgName},
+		})
+		if err != nil {
+			return err
+		}
+		if len(output.AutoScalingGroups) == 0 {
+			return fmt.Errorf("auto scaling group not found")
+		}
+		return groupInService(output.AutoScalingGroups[0])
+	}
+}
+
+// groupInService checks that as many instances of the group are in service as it desires
+func groupInService(group autoscalingtypes.AutoScalingGroup) error {
+	inService := 0
+	for _, instance := range group.Instances {
+		if instance.LifecycleState == autoscalingtypes.LifecycleStateInService {
+			inService++
+		}
+	}
+
+	if desired := int(aws.ToInt32(group.DesiredCapacity)); inService != desired {
+		return fmt.Errorf("%d of %d desired instances in service", inService, desired)
+	}
+	return nil
+}
+
+func stableKinesis(client *kinesis.Client, streamArn string) stableFunc {
+	return func(ctx context.Context) error {
+		output, err := client.DescribeStreamSummary(ctx, &kinesis.DescribeStreamSummaryInput{
+			StreamARN: &streamArn,
+		})
+		if err != nil {
+			return err
+		}
+
+		if status := output.StreamDescriptionSummary.StreamStatus; status != kinesistypes.StreamStatusActive {
+			return fmt.Errorf("stream is %s", status)
+		}
+		return nil
+	}
+}
+
+func stableElasticCache(client *elasticache.Client, clusterId string, engine ElasticCacheEngine) stableFunc {
+	return func(ctx context.Context) error {
+		var status string
+		switch engine {
+		case Redis:
+			output, err := client.DescribeReplicationGroups(ctx, &elasticache.DescribeReplicationGroupsInput{
+				ReplicationGroupId: &clusterId,
+			})
+			if err != nil {
+				return err
+			}
+			if len(output.ReplicationGroups) == 0 {
+				return fmt.Errorf("replication group not found")
+			}
+			status = aws.ToString(output.ReplicationGroups[0].Status)
+		case Memcached:
+			output, err := client.DescribeCacheClusters(ctx, &elasticache.DescribeCacheClustersInput{
+				CacheClusterId: &clusterId,
+			})
+			if err != nil {
+				return err
+			}
+			if len(output.CacheClusters) == 0 {
+				return fmt.Errorf("cache cluster not found")
+			}
+			status = aws.ToString(output.CacheClusters[0].CacheClusterStatus)
+		default:
+			return fmt.Errorf("unsupported engine %s", engine)
+		}
+
+		if status != elasticCacheAvailable {
+			return fmt.Errorf("cluster is %s", status)
+		}
+		return nil
+	}
+}
+
+func stableDynamoDB(client *applicationautoscaling.Client, resourceId string) stableFunc {
+	return func(ctx context.Context) error {
+		output, err := client.DescribeScalingActivities(ctx, &applicationautoscaling.DescribeScalingActivitiesInput{
+			ServiceNamespace: DynamodbServiceNamespace,
+			ResourceId:       &resourceId,
+		})
+		if err != nil {
+			return err
+		}
+		return activitiesSettled(output.ScalingActivities)
+	}
+}
+
+// activitiesSettled checks that no scaling activity of a scalable target is still pending or in progress, registering
+// a scalable target starts an activity when the provisioned capacity is out of the new range
+func activitiesSettled(activities []applicationautoscalingtypes.ScalingActivity) error {
+	for _, activity := range activities {
+		switch activity.StatusCode {
+		case applicationautoscalingtypes.ScalingActivityStatusCodePending, applicationautoscalingtypes.ScalingActivityStatusCodeInProgress:
+			return fmt.Errorf("scaling activity %s is %s", aws.ToString(activity.Description), activity.StatusCode)
+		}
+	}
+	return nil
+}
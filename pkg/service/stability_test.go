@@ -0,0 +1,67 @@
+package service
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	applicationautoscalingtypes "github.com/aws/aws-sdk-go-v2/service/applicationautoscaling/types"
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"testing"
+)
+
+func TestGroupInService(t *testing.T) {
+	instances := func(states ...autoscalingtypes.LifecycleState) []autoscalingtypes.Instance {
+		var instances []autoscalingtypes.Instance
+		for _, state := range states {
+			instances = append(instances, autoscalingtypes.Instance{LifecycleState: state})
+		}
+		return instances
+	}
+
+	tests := []struct {
+		name      string
+		desired   int32
+		instances []autoscalingtypes.Instance
+		stable    bool
+	}{
+		{name: "all in service", desired: 2, instances: instances(autoscalingtypes.LifecycleStateInService, autoscalingtypes.LifecycleStateInService), stable: true},
+		{name: "launching", desired: 2, instances: instances(autoscalingtypes.LifecycleStateInService, autoscalingtypes.LifecycleStatePending), stable: false},
+		{name: "not launched yet", desired: 3, instances: instances(autoscalingtypes.LifecycleStateInService), stable: false},
+		{name: "terminating", desired: 1, instances: instances(autoscalingtypes.LifecycleStateInService, autoscalingtypes.LifecycleStateTerminating), stable: true},
+		{name: "not terminated yet", desired: 1, instances: instances(autoscalingtypes.LifecycleStateInService, autoscalingtypes.LifecycleStateInService), stable: false},
+		{name: "empty", desired: 0, stable: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := groupInService(autoscalingtypes.AutoScalingGroup{DesiredCapacity: aws.Int32(test.desired), Instances: test.instances})
+			if (err == nil) != test.stable {
+				t.Errorf("groupInService() = %v, want stable %v", err, test.stable)
+			}
+		})
+	}
+}
+
+func TestActivitiesSettled(t *testing.T) {
+	activity := func(statusCode applicationautoscalingtypes.ScalingActivityStatusCode) applicationautoscalingtypes.ScalingActivity {
+		return applicationautoscalingtypes.ScalingActivity{Description: aws.String("setting read capacity"), StatusCode: statusCode}
+	}
+
+	tests := []struct {
+		name       string
+		activities []applicationautoscalingtypes.ScalingActivity
+		settled    bool
+	}{
+		{name: "no activities", settled: true},
+		{name: "successful", activities: []applicationautoscalingtypes.ScalingActivity{activity(applicationautoscalingtypes.ScalingActivityStatusCodeSuccessful)}, settled: true},
+		{name: "failed", activities: []applicationautoscalingtypes.ScalingActivity{activity(applicationautoscalingtypes.ScalingActivityStatusCodeFailed)}, settled: true},
+		{name: "in progress", activities: []applicationautoscalingtypes.ScalingActivity{activity(applicationautoscalingtypes.ScalingActivityStatusCodeSuccessful), activity(applicationautoscalingtypes.ScalingActivityStatusCodeInProgress)}, settled: false},
+		{name: "pending", activities: []applicationautoscalingtypes.ScalingActivity{activity(applicationautoscalingtypes.ScalingActivityStatusCodePending)}, settled: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := activitiesSettled(test.activities); (err == nil) != test.settled {
+				t.Errorf("activitiesSettled() = %v, want settled %v", err, test.settled)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	elasticachetypes "github.com/aws/aws-sdk-go-v2/service/elasticache/types"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"strings"
+	"time"
+)
+
+const ExpiryTagKey = "aws-infra-scaler:expires-at"
+
+type tagFunc func(ctx context.Context, key string, value *string) error
+
+func expiryTagValue(expiresAt time.Time) *string {
+	return aws.String(expiresAt.UTC().Format(time.RFC3339))
+}
+
+func tagEC2(client *autoscaling.Client, asgName string) tagFunc {
+	return func(ctx context.Context, key string, value *string) error {
+		tag := autoscalingtypes.Tag{
+			Key:               &key,
+			ResourceId:        &asgName,
+			ResourceType:      aws.String("auto-scaling-group"),
+			PropagateAtLaunch: aws.Bool(false),
+		}
+
+		if value == nil {
+			_, err := client.DeleteTags(ctx, &autoscaling.DeleteTagsInput{Tags: []autoscalingtypes.Tag{tag}})
+			return err
+		}
+
+		tag.Value = value
+		_, err := client.CreateOrUpdateTags(ctx, &autoscaling.CreateOrUpdateTagsInput{Tags: []autoscalingtypes.Tag{tag}})
+		return err
+	}
+}
+
+func tagKinesis(client *kinesis.Client, streamArn string) tagFunc {
+	return func(ctx context.Context, key string, value *string) error {
+		if value == nil {
+			_, err := client.RemoveTagsFromStream(ctx, &kinesis.RemoveTagsFromStreamInput{
+				StreamARN: &streamArn,
+				TagKeys:   []string{key},
+			})
+			return err
+		}
+
+		_, err := client.AddTagsToStream(ctx, &kinesis.AddTagsToStreamInput{
+			StreamARN: &streamArn,
+			Tags:      map[string]string{key: *value},
+		})
+		return err
+	}
+}
+
+func tagDynamoDB(client *dynamodb.Client, resourceId string) tagFunc {
+	return func(ctx context.Context, key string, value *string) error {
+		if client == nil {
+			return fmt.Errorf("no dynamodb client to tag table")
+		}
+
+		tableName := strings.SplitN(strings.TrimPrefix(resourceId, "table/"), "/", 2)[0]
+		table, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+			TableName: &tableName,
+		})
+		if err != nil {
+			return err
+		}
+
+		if value == nil {
+			_, err = client.UntagResource(ctx, &dynamodb.UntagResourceInput{
+				ResourceArn: table.Table.TableArn,
+				TagKeys:     []string{key},
+			})
+			return err
+		}
+
+		_, err = client.TagResource(ctx, &dynamodb.TagResourceInput{
+			ResourceArn: table.Table.TableArn,
+			Tags:        []dynamodbtypes.Tag{{Key: &key, Value: value}},
+		})
+		return err
+	}
+}
+
+func tagElasticCache(client *elasticache.Client, clusterId string, engine ElasticCacheEngine) tagFunc {
+	return func(ctx context.Context, key string, value *string) error {
+		var arn *string
+		if engine == Redis {
+			output, err := client.DescribeReplicationGroups(ctx, &elasticache.DescribeReplicationGroupsInput{
+				ReplicationGroupId: &clusterId,
+			})
+			if err != nil {
+				return err
+			}
+			if len(output.ReplicationGroups) == 0 {
+				return fmt.Errorf("replication group not found")
+			}
+			arn = output.ReplicationGroups[0].ARN
+		} else {
+			output, err := client.DescribeCacheClusters(ctx, &elasticache.DescribeCacheClustersInput{
+				CacheClusterId: &clusterId,
+			})
+			if err != nil {
+				return err
+			}
+			if len(output.CacheClusters) == 0 {
+				return fmt.Errorf("cache cluster not found")
+			}
+			arn = output.CacheClusters[0].ARN
+		}
+
+		if value == nil {
+			_, err := client.RemoveTagsFromResource(ctx, &elasticache.RemoveTagsFromResourceInput{
+				ResourceName: arn,
+				TagKeys:      []string{key},
+			})
+			return err
+		}
+
+		_, err := client.AddTagsToResource(ctx, &elasticache.AddTagsToResourceInput{
+			ResourceName: arn,
+			Tags:         []elasticachetypes.Tag{{Key: &key, Value: value}},
+		})
+		return err
+	}
+}
@@ -0,0 +1,96 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStore keeps the state of every run in its own file, in a directory per app
+type FileStore struct {
+	Dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating state directory: %w", err)
+	}
+	return &FileStore{Dir: dir}, nil
+}
+
+func (f *FileStore) Get(_ context.Context, appName string, runId string) (*Run, error) {
+	data, err := os.ReadFile(f.path(appName, runId))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading run state: %w", err)
+	}
+
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("error decoding run state: %w", err)
+	}
+	return &run, nil
+}
+
+func (f *FileStore) Put(_ context.Context, run Run) error {
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding run state: %w", err)
+	}
+
+	path := f.path(run.AppName, run.RunId)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating state directory: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("error writing run state: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("error writing run state: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) Delete(_ context.Context, appName string, runId string) error {
+	if err := os.Remove(f.path(appName, runId)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error deleting run state: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) List(ctx context.Context, appName string) ([]*Run, error) {
+	entries, err := os.ReadDir(filepath.Join(f.Dir, filepath.Base(appName)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing run states: %w", err)
+	}
+
+	var runs []*Run
+	for _, entry := range entries {
+		runId, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		run, err := f.Get(ctx, appName, runId)
+		if err != nil {
+			return nil, err
+		}
+		if run != nil {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+func (f *FileStore) path(appName string, runId string) string {
+	return filepath.Join(f.Dir, filepath.Base(appName), filepath.Base(runId)+".json")
+}
@@ -0,0 +1,85 @@
+package state
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	StatusPending    = "pending"
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+	// StatusUnchanged is the status of resources already at their target, they weren't scaled
+	StatusUnchanged = "unchanged"
+)
+
+type Resource struct {
+	Region       string         `json:"region"`
+	ServiceName  string         `json:"serviceName"`
+	IdentifierId string         `json:"identifierId"`
+	Target       map[string]int `json:"target"`
+	Status       string         `json:"status"`
+	Error        string         `json:"error,omitempty"`
+	UpdatedAt    time.Time      `json:"updatedAt"`
+	// Step is the last step a resource scaled in steps completed, and Reached the capacity the step reached
+	Step    int            `json:"step,omitempty"`
+	Reached map[string]int `json:"reached,omitempty"`
+}
+
+func (r Resource) Key() string {
+	return r.Region + "/" + r.ServiceName + "/" + r.IdentifierId
+}
+
+// Run is the progress of a run, checkpointed as its resources are scaled so a run that died halfway can be resumed
+type Run struct {
+	RunId     string     `json:"runId"`
+	AppName   string     `json:"appName"`
+	Profile   string     `json:"profile"`
+	StartedAt time.Time  `json:"startedAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	Outcome   string     `json:"outcome,omitempty"`
+	Resources []Resource `json:"resources"`
+	// SuppressedAlarms are the alarms, by region, whose actions the run disabled and didn't enable again yet
+	SuppressedAlarms map[string][]string `json:"suppressedAlarms,omitempty"`
+}
+
+// Done tells whether the run completed the resource or found it already at its target
+func (r *Run) Done(key string) bool {
+	status := r.Status(key)
+	return status == StatusCompleted || status == StatusUnchanged
+}
+
+// Status returns the status of the resource, or an empty status when the run doesn't know it
+func (r *Run) Status(key string) string {
+	resource, _ := r.Resource(key)
+	return resource.Status
+}
+
+func (r *Run) Resource(key string) (Resource, bool) {
+	for _, resource := range r.Resources {
+		if resource.Key() == key {
+			return resource, true
+		}
+	}
+	return Resource{}, false
+}
+
+func (r *Run) Set(resource Resource) {
+	for i, existing := range r.Resources {
+		if existing.Key() == resource.Key() {
+			r.Resources[i] = resource
+			return
+		}
+	}
+	r.Resources = append(r.Resources, resource)
+}
+
+type Store interface {
+	// Get returns nil when the run has no state
+	Get(ctx context.Context, appName string, runId string) (*Run, error)
+	Put(ctx context.Context, run Run) error
+	Delete(ctx context.Context, appName string, runId string) error
+	// List returns the runs of the app that kept their state
+	List(ctx context.Context, appName string) ([]*Run, error)
+}
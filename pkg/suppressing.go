@@ -0,0 +1,138 @@
+package pkg
+
+import (
+	"context"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/alarms"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/logging"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/state"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// alarmActionsService names the failures of suppressing alarm actions, which aren't tied to a resource
+const alarmActionsService = "alarm-actions"
+
+// suppressAlarms disables the actions of the alarms of the app and the region while the region is scaled. The returned
+// func enables them again, even once the run is canceled, and may be called more than once. Alarms whose actions were
+// already disabled are left disabled.
+func (s *Scaler) suppressAlarms(ctx context.Context, region string, checkpoint *checkpoint, suppressConfigs ...*config.SuppressAlarmsConfig) (func() *service.ScalingError, error) {
+	var names, prefixes []string
+	for _, suppressConfig := range suppressConfigs {
+		if suppressConfig != nil {
+			names = append(names, suppressConfig.Names...)
+			prefixes = append(prefixes, suppressConfig.Prefixes...)
+		}
+	}
+	if len(names) == 0 && len(prefixes) == 0 {
+		return func() *service.ScalingError { return nil }, nil
+	}
+
+	awsCreds, err := s.awsConfig(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	client := s.newCloudWatchClient(awsCreds)
+
+	alarmNames, err := alarms.WithActionsEnabled(ctx, client, names, prefixes)
+	if err != nil {
+		return nil, err
+	}
+	if len(alarmNames) == 0 {
+		logging.FromContext(ctx).Warn("no alarms with actions enabled to suppress")
+		return func() *service.ScalingError { return nil }, nil
+	}
+
+	var once sync.Once
+	var restoreErr *service.ScalingError
+	restore := func() *service.ScalingError {
+		once.Do(func() {
+			ctx, cancel := detach(ctx)
+			defer cancel()
+
+			if err := alarms.EnableActions(ctx, client, alarmNames); err != nil {
+				logging.FromContext(ctx).Error("error enabling alarm actions, enable them manually", "alarms", alarmNames, logging.Err(err))
+				restoreErr = &service.ScalingError{
+					Region:       region,
+					ServiceName:  alarmActionsService,
+					IdentifierId: strings.Join(alarmNames, ","),
+					Err:          err,
+				}
+				return
+			}
+			checkpoint.unsuppress(ctx, region)
+			logging.FromContext(ctx).Info("enabled alarm actions", "alarms", alarmNames)
+		})
+		return restoreErr
+	}
+
+	checkpoint.suppress(ctx, region, alarmNames)
+	logging.FromContext(ctx).Info("disabling alarm actions", "alarms", alarmNames)
+	if err := alarms.DisableActions(ctx, client, alarmNames); err != nil {
+		// some batches may have been disabled already
+		restore()
+		return nil, err
+	}
+	return restore, nil
+}
+
+// restoreAlarms enables the actions of the alarms that earlier runs of the app disabled and never enabled again, as
+// they were killed or lost their lock while scaling a region. The app must be locked, so no run is scaling it.
+func (s *Scaler) restoreAlarms(ctx context.Context, current *state.Run) {
+	store, err := newStateStore(s.config)
+	if err != nil {
+		logging.FromContext(ctx).Error("error creating state store, alarms left disabled by earlier runs aren't enabled", logging.Err(err))
+		return
+	}
+	runs, err := store.List(ctx, s.config.Name)
+	if err != nil {
+		logging.FromContext(ctx).Error("error listing runs, alarms left disabled by earlier runs aren't enabled", logging.Err(err))
+		return
+	}
+
+	for _, run := range runs {
+		if current != nil && run.RunId == current.RunId {
+			run = current
+		}
+		if len(run.SuppressedAlarms) == 0 {
+			continue
+		}
+
+		regions := make([]string, 0, len(run.SuppressedAlarms))
+		for region := range run.SuppressedAlarms {
+			regions = append(regions, region)
+		}
+		sort.Strings(regions)
+		for _, region := range regions {
+			alarmNames := run.SuppressedAlarms[region]
+			logger := logging.FromContext(ctx).With("suppressedBy", run.RunId, logging.RegionKey, region, "alarms", alarmNames)
+			if err := s.enableAlarmActions(ctx, region, alarmNames); err != nil {
+				logger.Error("error enabling alarm actions left disabled by an earlier run, enable them manually", logging.Err(err))
+				continue
+			}
+			logger.Info("enabled alarm actions left disabled by an earlier run")
+			delete(run.SuppressedAlarms, region)
+		}
+		if err := store.Put(ctx, *run); err != nil {
+			logging.FromContext(ctx).Error("error saving run state", logging.Err(err))
+		}
+	}
+}
+
+func (s *Scaler) enableAlarmActions(ctx context.Context, region string, alarmNames []string) error {
+	awsCreds, err := s.awsConfig(ctx, region)
+	if err != nil {
+		return err
+	}
+	return alarms.EnableActions(ctx, s.newCloudWatchClient(awsCreds), alarmNames)
+}
+
+func (s *Scaler) newCloudWatchClient(cfg *aws.Config) alarms.Client {
+	if s.cloudWatchClient != nil {
+		return s.cloudWatchClient(cfg)
+	}
+	return service.NewCloudWatchClient(cfg)
+}
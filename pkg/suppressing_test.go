@@ -0,0 +1,191 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/alarms"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/config"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/state"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeCloudWatch keeps whether the actions of each alarm are enabled
+type fakeCloudWatch struct {
+	mu             sync.Mutex
+	actionsEnabled map[string]bool
+	// failDisableCall fails the DisableAlarmActions call with this number, counting from 1, 0 never fails
+	failDisableCall int
+	disableCalls    int
+}
+
+func newFakeCloudWatch(actionsEnabled map[string]bool) *fakeCloudWatch {
+	return &fakeCloudWatch{actionsEnabled: actionsEnabled}
+}
+
+func (f *fakeCloudWatch) DescribeAlarms(_ context.Context, input *cloudwatch.DescribeAlarmsInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.DescribeAlarmsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	output := &cloudwatch.DescribeAlarmsOutput{}
+	for name, enabled := range f.actionsEnabled {
+		matches := input.AlarmNamePrefix != nil && strings.HasPrefix(name, *input.AlarmNamePrefix)
+		for _, alarmName := range input.AlarmNames {
+			matches = matches || alarmName == name
+		}
+		if matches {
+			output.MetricAlarms = append(output.MetricAlarms, types.MetricAlarm{AlarmName: aws.String(name), ActionsEnabled: aws.Bool(enabled)})
+		}
+	}
+	return output, nil
+}
+
+func (f *fakeCloudWatch) DisableAlarmActions(_ context.Context, input *cloudwatch.DisableAlarmActionsInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.DisableAlarmActionsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.disableCalls++
+	if f.disableCalls == f.failDisableCall {
+		return nil, errors.New("throttled")
+	}
+	for _, name := range input.AlarmNames {
+		f.actionsEnabled[name] = false
+	}
+	return &cloudwatch.DisableAlarmActionsOutput{}, nil
+}
+
+func (f *fakeCloudWatch) EnableAlarmActions(_ context.Context, input *cloudwatch.EnableAlarmActionsInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.EnableAlarmActionsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, name := range input.AlarmNames {
+		f.actionsEnabled[name] = true
+	}
+	return &cloudwatch.EnableAlarmActionsOutput{}, nil
+}
+
+// disabled returns the alarms whose actions are disabled
+func (f *fakeCloudWatch) disabled() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var names []string
+	for name, enabled := range f.actionsEnabled {
+		if !enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newAlarmsScaler(t *testing.T, client *fakeCloudWatch) *Scaler {
+	return &Scaler{
+		config: &config.ScalingConfig{Name: "my-app", State: &config.StateConfig{Path: t.TempDir()}},
+		awsConfig: func(ctx context.Context, region string) (*aws.Config, error) {
+			return &aws.Config{Region: region}, nil
+		},
+		cloudWatchClient: func(cfg *aws.Config) alarms.Client { return client },
+	}
+}
+
+func suppressedAlarms(t *testing.T, scaler *Scaler) map[string][]string {
+	t.Helper()
+	store, err := newStateStore(scaler.config)
+	if err != nil {
+		t.Fatalf("newStateStore() failed: %v", err)
+	}
+	runs, err := store.List(context.Background(), scaler.config.Name)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("List() = %v, %v, want the run", runs, err)
+	}
+	return runs[0].SuppressedAlarms
+}
+
+func TestSuppressAlarms(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeCloudWatch(map[string]bool{"my-app-5xx": true, "my-app-latency": false, "other-app-5xx": true})
+	scaler := newAlarmsScaler(t, client)
+	checkpoint := newCheckpoint(ctx, &ScalingPlan{RunId: "run", ScalingConfig: scaler.config})
+
+	restore, err := scaler.suppressAlarms(ctx, "us-east-1", checkpoint, &config.SuppressAlarmsConfig{Prefixes: []string{"my-app-"}})
+	if err != nil {
+		t.Fatalf("suppressAlarms() error = %v", err)
+	}
+	if got, want := client.disabled(), []string{"my-app-5xx", "my-app-latency"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("disabled alarms while suppressed = %v, want %v", got, want)
+	}
+	if got, want := suppressedAlarms(t, scaler), map[string][]string{"us-east-1": {"my-app-5xx"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("suppressed alarms of the run = %v, want %v", got, want)
+	}
+
+	if err := restore(); err != nil {
+		t.Fatalf("restore() error = %v", err.Err)
+	}
+	if got, want := client.disabled(), []string{"my-app-latency"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("disabled alarms after restoring = %v, want %v, already disabled alarms stay disabled", got, want)
+	}
+	if got := suppressedAlarms(t, scaler); len(got) != 0 {
+		t.Errorf("suppressed alarms of the run after restoring = %v, want none", got)
+	}
+}
+
+func TestSuppressAlarmsPartialFailure(t *testing.T) {
+	ctx := context.Background()
+	actionsEnabled := make(map[string]bool)
+	for i := 0; i < 250; i++ {
+		actionsEnabled[fmt.Sprintf("my-app-%03d", i)] = true
+	}
+	client := newFakeCloudWatch(actionsEnabled)
+	client.failDisableCall = 2
+	scaler := newAlarmsScaler(t, client)
+	checkpoint := newCheckpoint(ctx, &ScalingPlan{RunId: "run", ScalingConfig: scaler.config})
+
+	restore, err := scaler.suppressAlarms(ctx, "us-east-1", checkpoint, &config.SuppressAlarmsConfig{Prefixes: []string{"my-app-"}})
+	if err == nil || restore != nil {
+		t.Fatalf("suppressAlarms() = %v, want the error of the failed batch", err)
+	}
+	if got := client.disabled(); len(got) != 0 {
+		t.Errorf("%d alarms left disabled after a failed batch, want the disabled batches enabled again", len(got))
+	}
+	if got := suppressedAlarms(t, scaler); len(got) != 0 {
+		t.Errorf("suppressed alarms of the run = %v, want none", got)
+	}
+}
+
+func TestRestoreAlarms(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeCloudWatch(map[string]bool{"my-app-5xx": false, "my-app-latency": false, "my-app-manual": false})
+	scaler := newAlarmsScaler(t, client)
+	store, err := newStateStore(scaler.config)
+	if err != nil {
+		t.Fatalf("newStateStore() failed: %v", err)
+	}
+	stale := state.Run{
+		RunId:   "killed-run",
+		AppName: "my-app",
+		SuppressedAlarms: map[string][]string{
+			"us-east-1": {"my-app-5xx"},
+			"eu-west-1": {"my-app-latency"},
+		},
+	}
+	if err := store.Put(ctx, stale); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	scaler.restoreAlarms(ctx, nil)
+
+	if got, want := client.disabled(), []string{"my-app-manual"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("disabled alarms after restoring = %v, want %v", got, want)
+	}
+	if got := suppressedAlarms(t, scaler); len(got) != 0 {
+		t.Errorf("suppressed alarms of the stale run after restoring = %v, want none", got)
+	}
+}
@@ -0,0 +1,62 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/metrics"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"time"
+)
+
+// ErrTimeout fails the resources whose scaling took longer than a timeout, or that a run past its timeout didn't start scaling
+var ErrTimeout = errors.New("timed out")
+
+// withTimeout derives a context that expires after the timeout, a timeout of 0 never expires
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// withDeadline derives a context that expires at the deadline, a zero deadline never expires
+func withDeadline(ctx context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	if deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+// contextErr tells why the work of a done context stopped, a detached context expires with the cause of its parent
+func contextErr(ctx context.Context) error {
+	if errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	return ErrCanceled
+}
+
+func scalingOutcome(errs []*service.ScalingError) string {
+	for _, err := range errs {
+		if errors.Is(err.Err, ErrTimeout) {
+			return metrics.OutcomeTimeout
+		}
+	}
+	if len(errs) > 0 {
+		return metrics.OutcomeFailure
+	}
+	return metrics.OutcomeSuccess
+}
+
+// timedOut marks the errors of work whose context expired, so timeouts are told apart from API failures
+func timedOut(ctx context.Context, errs []*service.ScalingError) []*service.ScalingError {
+	if !errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+		return errs
+	}
+	for _, err := range errs {
+		if !errors.Is(err.Err, ErrTimeout) {
+			err.Err = fmt.Errorf("%w: %w", ErrTimeout, err.Err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"github.com/Cool-fire/aws-infra-scaler/pkg/service"
+	"testing"
+	"time"
+)
+
+func TestTimedOut(t *testing.T) {
+	expired := func() context.Context {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		t.Cleanup(cancel)
+		return ctx
+	}
+	canceled := func(cause error) context.Context {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(cause)
+		// a context derived from the canceled one, like the contexts derived from a detached context
+		child, cancelChild := context.WithCancel(ctx)
+		t.Cleanup(cancelChild)
+		return child
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{name: "live context", ctx: context.Background(), err: errors.New("throttled"), want: false},
+		{name: "canceled", ctx: canceled(context.Canceled), err: context.Canceled, want: false},
+		{name: "deadline exceeded", ctx: expired(), err: context.DeadlineExceeded, want: true},
+		{name: "canceled once its parent timed out", ctx: canceled(context.DeadlineExceeded), err: context.Canceled, want: true},
+		{name: "already timed out", ctx: expired(), err: ErrTimeout, want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs := timedOut(test.ctx, []*service.ScalingError{{Err: test.err}})
+
+			if got := errors.Is(errs[0].Err, ErrTimeout); got != test.want {
+				t.Errorf("timedOut() marked %v as a timeout: %v, want %v", test.err, got, test.want)
+			}
+			if !errors.Is(errs[0].Err, test.err) {
+				t.Errorf("timedOut() = %v, dropped %v", errs[0].Err, test.err)
+			}
+			if test.want && contextErr(test.ctx) != ErrTimeout {
+				t.Errorf("contextErr() = %v, want %v", contextErr(test.ctx), ErrTimeout)
+			}
+		})
+	}
+}
+
+func TestDetach(t *testing.T) {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	detached, cancelDetached := detach(ctx)
+	if detached.Err() != nil {
+		t.Fatalf("detached context of an expired context is done before the grace: %v", detached.Err())
+	}
+	if _, ok := detached.Deadline(); ok {
+		t.Error("detached context kept the deadline of its parent")
+	}
+
+	cancelDetached()
+	if !errors.Is(context.Cause(detached), context.Canceled) {
+		t.Errorf("detached context canceled with cause %v, want %v", context.Cause(detached), context.Canceled)
+	}
+}
+
+func TestWithDeadline(t *testing.T) {
+	deadline := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		deadline time.Time
+		want     bool
+	}{
+		{name: "no deadline", deadline: time.Time{}, want: false},
+		{name: "deadline", deadline: deadline, want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := withDeadline(context.Background(), test.deadline)
+			defer cancel()
+
+			got, ok := ctx.Deadline()
+			if ok != test.want || !got.Equal(test.deadline) {
+				t.Errorf("withDeadline(%v).Deadline() = %v, %v, want %v, %v", test.deadline, got, ok, test.deadline, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/url"
+	"os"
+)
+
+const (
+	serviceName = "aws-infra-scaler"
+	tracerName  = "github.com/Cool-fire/aws-infra-scaler"
+)
+
+var (
+	AppKey        = attribute.Key("scaler.app")
+	ProfileKey    = attribute.Key("scaler.profile")
+	RunIdKey      = attribute.Key("scaler.run_id")
+	RegionKey     = attribute.Key("scaler.region")
+	ServiceKey    = attribute.Key("scaler.service")
+	IdentifierKey = attribute.Key("scaler.identifier")
+	OutcomeKey    = attribute.Key("scaler.outcome")
+)
+
+// Init exports spans over OTLP/HTTP to the endpoint, or to the endpoint of the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT variables when it is empty. Tracing stays disabled when neither is set.
+func Init(ctx context.Context, endpoint string) (func(ctx context.Context) error, error) {
+	if endpoint == "" && os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	var exporterOptions []otlptracehttp.Option
+	if endpoint != "" {
+		endpointURL, err := url.Parse(endpoint)
+		if err != nil || endpointURL.Host == "" {
+			return nil, fmt.Errorf("invalid otlp endpoint %s, expected a URL like http://localhost:4318", endpoint)
+		}
+
+		exporterOptions = append(exporterOptions, otlptracehttp.WithEndpoint(endpointURL.Host))
+		if endpointURL.Scheme == "http" {
+			exporterOptions = append(exporterOptions, otlptracehttp.WithInsecure())
+		}
+		if endpointURL.Path != "" && endpointURL.Path != "/" {
+			exporterOptions = append(exporterOptions, otlptracehttp.WithURLPath(endpointURL.Path))
+		}
+	}
+
+	exporter, err := otlptracehttp.New(ctx, exporterOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating otlp exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("error creating trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return provider.Shutdown, nil
+}
+
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End records the error on the span, if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func Fail(span trace.Span, description string) {
+	span.SetStatus(codes.Error, description)
+}
+
+// InstrumentAPI traces the AWS API calls made with the config
+func InstrumentAPI(cfg *aws.Config) {
+	otelaws.AppendMiddlewares(&cfg.APIOptions)
+}
@@ -0,0 +1,85 @@
+package ttl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	appNameAttribute  = "AppName"
+	snapshotAttribute = "Snapshot"
+)
+
+type DynamoDBStore struct {
+	Client    *dynamodb.Client
+	TableName string
+}
+
+func NewDynamoDBStore(client *dynamodb.Client, tableName string) *DynamoDBStore {
+	return &DynamoDBStore{
+		Client:    client,
+		TableName: tableName,
+	}
+}
+
+func (d *DynamoDBStore) Get(ctx context.Context, appName string) (*Snapshot, error) {
+	output, err := d.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &d.TableName,
+		Key:            d.key(appName),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading ttl snapshot: %w", err)
+	}
+
+	data, ok := output.Item[snapshotAttribute].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, nil
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal([]byte(data.Value), &snapshot); err != nil {
+		return nil, fmt.Errorf("error decoding ttl snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+func (d *DynamoDBStore) Put(ctx context.Context, snapshot Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("error encoding ttl snapshot: %w", err)
+	}
+
+	_, err = d.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &d.TableName,
+		Item: map[string]types.AttributeValue{
+			appNameAttribute:  &types.AttributeValueMemberS{Value: snapshot.AppName},
+			snapshotAttribute: &types.AttributeValueMemberS{Value: string(data)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error writing ttl snapshot: %w", err)
+	}
+	return nil
+}
+
+func (d *DynamoDBStore) Delete(ctx context.Context, appName string) error {
+	_, err := d.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: &d.TableName,
+		Key:       d.key(appName),
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting ttl snapshot: %w", err)
+	}
+	return nil
+}
+
+func (d *DynamoDBStore) key(appName string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		appNameAttribute: &types.AttributeValueMemberS{Value: appName},
+	}
+}
@@ -0,0 +1,64 @@
+package ttl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type FileStore struct {
+	Dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating ttl directory: %w", err)
+	}
+	return &FileStore{Dir: dir}, nil
+}
+
+func (f *FileStore) Get(_ context.Context, appName string) (*Snapshot, error) {
+	data, err := os.ReadFile(f.path(appName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading ttl snapshot: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("error decoding ttl snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+func (f *FileStore) Put(_ context.Context, snapshot Snapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding ttl snapshot: %w", err)
+	}
+
+	tmpPath := f.path(snapshot.AppName) + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("error writing ttl snapshot: %w", err)
+	}
+	if err := os.Rename(tmpPath, f.path(snapshot.AppName)); err != nil {
+		return fmt.Errorf("error writing ttl snapshot: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) Delete(_ context.Context, appName string) error {
+	if err := os.Remove(f.path(appName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error deleting ttl snapshot: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) path(appName string) string {
+	return filepath.Join(f.Dir, filepath.Base(appName)+".json")
+}
@@ -0,0 +1,76 @@
+package ttl
+
+import (
+	"context"
+	"time"
+)
+
+type ResourceSnapshot struct {
+	Region       string         `json:"region"`
+	ServiceName  string         `json:"serviceName"`
+	IdentifierId string         `json:"identifierId"`
+	Capacity     map[string]int `json:"capacity"`
+	RunId        string         `json:"runId"`
+	ExpiresAt    time.Time      `json:"expiresAt"`
+}
+
+func (r ResourceSnapshot) Key() string {
+	return r.Region + "/" + r.ServiceName + "/" + r.IdentifierId
+}
+
+func (r ResourceSnapshot) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// Snapshot holds the capacity of the resources of an app before a time boxed scale-up
+type Snapshot struct {
+	AppName   string             `json:"appName"`
+	Resources []ResourceSnapshot `json:"resources"`
+}
+
+func (s *Snapshot) Expired(now time.Time) []ResourceSnapshot {
+	var expired []ResourceSnapshot
+	for _, resource := range s.Resources {
+		if resource.Expired(now) {
+			expired = append(expired, resource)
+		}
+	}
+	return expired
+}
+
+// Put records the capacity of a resource, keeping the capacity of an earlier snapshot of it so
+// extending a time boxed scale-up still reverts to the original capacity
+func (s *Snapshot) Put(resource ResourceSnapshot) {
+	for i, existing := range s.Resources {
+		if existing.Key() == resource.Key() {
+			resource.Capacity = existing.Capacity
+			s.Resources[i] = resource
+			return
+		}
+	}
+	s.Resources = append(s.Resources, resource)
+}
+
+func (s *Snapshot) Remove(key string) bool {
+	for i, existing := range s.Resources {
+		if existing.Key() == key {
+			s.Resources = append(s.Resources[:i], s.Resources[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type Store interface {
+	// Get returns nil when the app has no snapshot
+	Get(ctx context.Context, appName string) (*Snapshot, error)
+	Put(ctx context.Context, snapshot Snapshot) error
+	Delete(ctx context.Context, appName string) error
+}
+
+func Save(ctx context.Context, store Store, snapshot Snapshot) error {
+	if len(snapshot.Resources) == 0 {
+		return store.Delete(ctx, snapshot.AppName)
+	}
+	return store.Put(ctx, snapshot)
+}
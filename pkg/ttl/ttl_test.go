@@ -0,0 +1,85 @@
+package ttl
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshotExpired(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	resource := func(identifierId string, expiresAt time.Time) ResourceSnapshot {
+		return ResourceSnapshot{Region: "us-east-1", ServiceName: "ec2", IdentifierId: identifierId, ExpiresAt: expiresAt}
+	}
+
+	tests := []struct {
+		name      string
+		resources []ResourceSnapshot
+		want      []string
+	}{
+		{name: "expired", resources: []ResourceSnapshot{resource("my-asg", now.Add(-time.Minute))}, want: []string{"my-asg"}},
+		{name: "expires now", resources: []ResourceSnapshot{resource("my-asg", now)}, want: []string{"my-asg"}},
+		{name: "not expired", resources: []ResourceSnapshot{resource("my-asg", now.Add(time.Minute))}, want: nil},
+		{
+			name:      "only the expired resources",
+			resources: []ResourceSnapshot{resource("expired-asg", now.Add(-time.Hour)), resource("extended-asg", now.Add(time.Hour))},
+			want:      []string{"expired-asg"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			snapshot := Snapshot{AppName: "my-app", Resources: test.resources}
+			var got []string
+			for _, resource := range snapshot.Expired(now) {
+				got = append(got, resource.IdentifierId)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Expired() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotPut(t *testing.T) {
+	now := time.Now()
+	snapshot := Snapshot{AppName: "my-app"}
+	snapshot.Put(ResourceSnapshot{Region: "us-east-1", ServiceName: "ec2", IdentifierId: "my-asg", Capacity: map[string]int{"desiredCount": 2}, RunId: "run-1", ExpiresAt: now})
+	snapshot.Put(ResourceSnapshot{Region: "us-east-1", ServiceName: "ec2", IdentifierId: "my-asg", Capacity: map[string]int{"desiredCount": 4}, RunId: "run-2", ExpiresAt: now.Add(time.Hour)})
+
+	if len(snapshot.Resources) != 1 {
+		t.Fatalf("snapshot has %d resources, want 1", len(snapshot.Resources))
+	}
+	resource := snapshot.Resources[0]
+	if want := map[string]int{"desiredCount": 2}; !reflect.DeepEqual(resource.Capacity, want) {
+		t.Errorf("extended snapshot capacity = %v, want the original capacity %v", resource.Capacity, want)
+	}
+	if resource.RunId != "run-2" || !resource.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("extended snapshot = %s expiring at %v, want run-2 expiring at %v", resource.RunId, resource.ExpiresAt, now.Add(time.Hour))
+	}
+}
+
+func TestSave(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+
+	snapshot := Snapshot{AppName: "my-app", Resources: []ResourceSnapshot{{Region: "us-east-1", ServiceName: "ec2", IdentifierId: "my-asg"}}}
+	if err := Save(ctx, store, snapshot); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if saved, err := store.Get(ctx, "my-app"); err != nil || saved == nil || len(saved.Resources) != 1 {
+		t.Fatalf("Get() = %v, %v, want the saved snapshot", saved, err)
+	}
+
+	snapshot.Remove(snapshot.Resources[0].Key())
+	if err := Save(ctx, store, snapshot); err != nil {
+		t.Fatalf("Save() of an empty snapshot error = %v", err)
+	}
+	if saved, err := store.Get(ctx, "my-app"); err != nil || saved != nil {
+		t.Errorf("Get() = %v, %v, want the empty snapshot deleted", saved, err)
+	}
+}